@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"secop-blockchain/internal/blockchain"
 	"secop-blockchain/internal/config"
 	"secop-blockchain/internal/handler"
+	"secop-blockchain/internal/scheduler"
 	"secop-blockchain/internal/service"
 )
 
@@ -38,16 +46,67 @@ func main() {
 	// Setup routes
 	router := handler.SetupRoutes(cfg, services)
 	
+	// Start daily digest of pending validations
+	services.Notifications.Start()
+	
+	if services.Registry != nil {
+		fmt.Printf("📒 Modo registro de peers activo (TTL %s)\n", services.Registry.TTL())
+	}
+	
+	// Join the network: discovery, peer exchange and peer sync
+	if err := services.P2P.Start(); err != nil {
+		log.Fatalf("Error iniciando red P2P: %v", err)
+	}
+	
 	// Start periodic tasks
-	go startPeriodicTasks(services)
+	startPeriodicTasks(services)
+	
+	// Start server; /api/health/ready answers 503 until the initial sync ends
+	server := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: router,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Error iniciando servidor:", err)
+		}
+	}()
 
 	fmt.Printf("✅ Servidor iniciado en puerto %s\n", cfg.Server.Port)
 	fmt.Printf("🔗 API disponible en http://%s:%s/api/\n", cfg.Server.Address, cfg.Server.Port)
 	
-	// Start server
-	if err := router.Run(":" + cfg.Server.Port); err != nil {
-		log.Fatal("Error iniciando servidor:", err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	
+	go func() {
+		services.P2P.InitialSync(cfg.P2P.InitialSyncTimeout)
+		fmt.Printf("🟢 Nodo listo\n")
+	}()
+	
+	<-ctx.Done()
+	stop()
+	shutdown(server, services, cfg.Server.ShutdownTimeout)
+}
+
+// shutdown stops accepting requests, lets in-flight ones finish and leaves
+// the network so peers and the registry stop routing to this node
+func shutdown(server *http.Server, services *service.Services, timeout time.Duration) {
+	fmt.Printf("🛑 Deteniendo nodo...\n")
+	if timeout <= 0 {
+		timeout = 15 * time.Second
 	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error cerrando servidor HTTP: %v", err)
+	}
+	
+	services.Scheduler.Stop()
+	services.P2P.Stop()
+	services.Notifications.Stop()
+	services.Webhooks.Stop()
+	fmt.Printf("👋 Nodo detenido\n")
 }
 
 func setupBootstrapPeers(services *service.Services, cfg *config.Config) {
@@ -57,7 +116,17 @@ func setupBootstrapPeers(services *service.Services, cfg *config.Config) {
 	}
 	
 	fmt.Printf("🔗 Configurando %d peers bootstrap\n", len(cfg.P2P.BootstrapPeers))
-	// TODO: Implement bootstrap peer setup logic
+	for _, entry := range cfg.P2P.BootstrapPeers {
+		peer, err := config.ParseBootstrapPeer(entry)
+		if err != nil {
+			log.Fatalf("BOOTSTRAP_PEERS: %v", err)
+		}
+		if peer.ID == cfg.P2P.NodeID {
+			fmt.Printf("⚠️ Ignorando peer bootstrap %s: es este mismo nodo\n", peer.ID)
+			continue
+		}
+		services.P2P.AddBootstrapPeer(peer.ID, peer.Host, peer.Port)
+	}
 }
 
 func createExampleContracts(services *service.Services) {
@@ -65,7 +134,90 @@ func createExampleContracts(services *service.Services) {
 	fmt.Printf("✅ Sistema iniciado sin datos de ejemplo\n")
 }
 
+// startPeriodicTasks registers the background jobs and starts the scheduler.
+// Their state is reported by /api/health.
 func startPeriodicTasks(services *service.Services) {
 	fmt.Printf("⏰ Iniciando tareas periódicas...\n")
-	// TODO: Implement periodic sync and health checks
-}
\ No newline at end of file
+	cfg := services.Config
+	p2p := services.P2P
+	
+	jobs := []scheduler.Job{
+		{
+			Name:     "peer-health",
+			Interval: orDefault(cfg.Scheduler.HealthCheckInterval, time.Minute),
+			Run:      p2p.HealthCheck,
+		},
+		{
+			Name:     "chain-sync",
+			Interval: orDefault(cfg.Scheduler.SyncInterval, 2*time.Minute),
+			Run:      p2p.SyncIncremental,
+		},
+		{
+			Name:     "peer-list",
+			Interval: orDefault(cfg.Scheduler.PeerListInterval, time.Minute),
+			Run: func(ctx context.Context) error {
+				p2p.RefreshPeers()
+				return nil
+			},
+		},
+		{
+			// Escalate workflow steps whose deadline has passed
+			Name:     "workflow-deadlines",
+			Interval: orDefault(cfg.Workflow.DeadlineCheckInterval, 15*time.Minute),
+			Run: func(ctx context.Context) error {
+				escalated, err := services.Workflow.CheckDeadlines()
+				if escalated > 0 {
+					fmt.Printf("⏰ %d pasos vencidos escalados\n", escalated)
+				}
+				return err
+			},
+		},
+	}
+	
+	// Keep the registration alive, backing off while the registry is unreachable
+	if p2p.PeerDiscovery.UsesRegistry() {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "registry-heartbeat",
+			Interval: p2p.PeerDiscovery.HeartbeatInterval(),
+			RetryMin: blockchain.DiscoveryRetryMin,
+			RetryMax: blockchain.DiscoveryRetryMax,
+			Run: func(ctx context.Context) error {
+				return p2p.PeerDiscovery.Refresh()
+			},
+		})
+	}
+	if interval := p2p.PeerDiscovery.GossipInterval(); interval > 0 {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "peer-exchange",
+			Interval: interval,
+			Run:      p2p.PeerDiscovery.GossipRound,
+		})
+	}
+	
+	// Prune nodes that stopped sending heartbeats when serving as registry
+	if services.Registry != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "registry-prune",
+			Interval: services.Registry.TTL(),
+			Run: func(ctx context.Context) error {
+				services.Registry.Prune()
+				return nil
+			},
+		})
+	}
+	
+	for _, job := range jobs {
+		if err := services.Scheduler.Add(job); err != nil {
+			log.Fatalf("Error programando tareas: %v", err)
+		}
+	}
+	services.Scheduler.Start()
+}
+
+// orDefault returns d, or def when d is not set
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
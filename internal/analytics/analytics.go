@@ -0,0 +1,314 @@
+// Package analytics computes anticorruption red flags over the contracts in
+// the chain and combines them into a risk score per contract.
+//
+// Flags are recomputed whenever the chain changes. Every node rebuilds its
+// contracts by replaying the chain's blocks, including blocks received from
+// other nodes, so every node with the same chain reports the same signals.
+package analytics
+
+import (
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/config"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Red flag identifiers
+const (
+	FlagSplitting             = "CONTRACT_SPLITTING"     // Fraccionamiento bajo umbrales
+	FlagSameValidator         = "SAME_VALIDATOR"         // Un validador aprueba varios pasos del mismo contrato
+	FlagConcentratedValidator = "CONCENTRATED_VALIDATOR" // Un validador concentra las aprobaciones de la entidad
+	FlagFastApproval          = "FAST_APPROVAL"          // Pasos aprobados en tiempos inusualmente cortos
+	FlagFastWorkflow          = "FAST_WORKFLOW"          // Flujo completo aprobado en tiempo inusualmente corto
+	FlagSingleBidder          = "SINGLE_BIDDER"          // Adjudicación con un solo proponente
+	FlagRepeatedSingleBidder  = "REPEATED_SINGLE_BIDDER" // Mismo proveedor gana varias veces como único proponente
+)
+
+// flagWeights are the points each flag adds to the risk score
+var flagWeights = map[string]int{
+	FlagSplitting:             35,
+	FlagSameValidator:         30,
+	FlagConcentratedValidator: 15,
+	FlagFastApproval:          15,
+	FlagFastWorkflow:          20,
+	FlagSingleBidder:          10,
+	FlagRepeatedSingleBidder:  30,
+}
+
+// Severity of a flag or risk level of a contract
+const (
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+// Config holds the detection thresholds
+type Config struct {
+	SplitWindow           time.Duration // Ventana en que contratos cercanos al umbral se consideran fraccionados
+	SplitMarginPercent    int           // Qué tan por debajo del umbral se considera "justo bajo el umbral"
+	FastStep              time.Duration // Aprobación de un paso más rápida que esto es inusual
+	FastWorkflow          time.Duration // Flujo completo aprobado más rápido que esto es inusual
+	ValidatorSharePercent int           // Porcentaje de aprobaciones de la entidad que concentra un validador
+	ValidatorMinContracts int           // Contratos mínimos de la entidad para evaluar concentración
+}
+
+// DefaultConfig returns the thresholds used when none are configured
+func DefaultConfig() Config {
+	return Config{
+		SplitWindow:           90 * 24 * time.Hour,
+		SplitMarginPercent:    10,
+		FastStep:              time.Hour,
+		FastWorkflow:          24 * time.Hour,
+		ValidatorSharePercent: 60,
+		ValidatorMinContracts: 5,
+	}
+}
+
+// Alert is a red flag raised on a contract
+type Alert struct {
+	ContractID       string    `json:"contract_id"`
+	EntityCode       string    `json:"entity_code"`
+	Flag             string    `json:"flag"`
+	Severity         string    `json:"severity"`
+	Weight           int       `json:"weight"`
+	Description      string    `json:"description"`
+	RelatedContracts []string  `json:"related_contracts,omitempty"`
+	DetectedAt       time.Time `json:"detected_at"`
+}
+
+// ContractRisk is the risk score of a contract and the flags behind it
+type ContractRisk struct {
+	ContractID string   `json:"contract_id"`
+	EntityCode string   `json:"entity_code"`
+	EntityName string   `json:"entity_name"`
+	Score      int      `json:"score"` // 0 a 100
+	Level      string   `json:"level"`
+	Alerts     []*Alert `json:"alerts"`
+}
+
+// AlertFilter selects alerts
+type AlertFilter struct {
+	EntityCode  string
+	Flag        string
+	MinSeverity string
+}
+
+// Service computes and caches red flags for the chain
+type Service struct {
+	blockchain *blockchain.Blockchain
+	config     Config
+	lastHash   string
+	risks      map[string]*ContractRisk
+	computedAt time.Time
+	mutex      sync.Mutex
+}
+
+// NewService creates the analytics service
+func NewService(bc *blockchain.Blockchain, cfg Config) *Service {
+	defaults := DefaultConfig()
+	if cfg.SplitWindow <= 0 {
+		cfg.SplitWindow = defaults.SplitWindow
+	}
+	if cfg.SplitMarginPercent <= 0 || cfg.SplitMarginPercent >= 100 {
+		cfg.SplitMarginPercent = defaults.SplitMarginPercent
+	}
+	if cfg.FastStep <= 0 {
+		cfg.FastStep = defaults.FastStep
+	}
+	if cfg.FastWorkflow <= 0 {
+		cfg.FastWorkflow = defaults.FastWorkflow
+	}
+	if cfg.ValidatorSharePercent <= 0 || cfg.ValidatorSharePercent > 100 {
+		cfg.ValidatorSharePercent = defaults.ValidatorSharePercent
+	}
+	if cfg.ValidatorMinContracts <= 0 {
+		cfg.ValidatorMinContracts = defaults.ValidatorMinContracts
+	}
+	return &Service{
+		blockchain: bc,
+		config:     cfg,
+	}
+}
+
+// Config returns the thresholds in effect
+func (s *Service) Config() Config {
+	return s.config
+}
+
+// ContractRisk returns the risk score of a contract
+func (s *Service) ContractRisk(contractID string) (*ContractRisk, error) {
+	contract, err := s.blockchain.GetContract(contractID)
+	if err != nil {
+		return nil, err
+	}
+	risks, _ := s.analyze()
+	if risk, ok := risks[contractID]; ok {
+		return risk, nil
+	}
+	return newContractRisk(contract), nil
+}
+
+// Ranking returns contracts with a score of at least minScore, riskiest first
+func (s *Service) Ranking(entityCode string, minScore int, limit int) []*ContractRisk {
+	risks, _ := s.analyze()
+
+	result := make([]*ContractRisk, 0)
+	for _, risk := range risks {
+		if entityCode != "" && risk.EntityCode != entityCode {
+			continue
+		}
+		if risk.Score < minScore || risk.Score == 0 {
+			continue
+		}
+		result = append(result, risk)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].ContractID < result[j].ContractID
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// Alerts returns the flags raised over the chain, most severe first
+func (s *Service) Alerts(filter AlertFilter) ([]*Alert, time.Time) {
+	risks, computedAt := s.analyze()
+	minRank := severityRank(filter.MinSeverity)
+
+	result := make([]*Alert, 0)
+	for _, risk := range risks {
+		for _, alert := range risk.Alerts {
+			if filter.EntityCode != "" && alert.EntityCode != filter.EntityCode {
+				continue
+			}
+			if filter.Flag != "" && alert.Flag != filter.Flag {
+				continue
+			}
+			if severityRank(alert.Severity) < minRank {
+				continue
+			}
+			result = append(result, alert)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Weight != result[j].Weight {
+			return result[i].Weight > result[j].Weight
+		}
+		if result[i].ContractID != result[j].ContractID {
+			return result[i].ContractID < result[j].ContractID
+		}
+		return result[i].Flag < result[j].Flag
+	})
+	return result, computedAt
+}
+
+// analyze recomputes the flags when the chain has changed since the last run
+func (s *Service) analyze() (map[string]*ContractRisk, time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	lastHash := s.blockchain.GetLastBlockHash()
+	if s.risks != nil && lastHash == s.lastHash {
+		return s.risks, s.computedAt
+	}
+
+	now := config.GetColombianTime()
+	contracts := make([]*blockchain.Contract, 0)
+	for _, contract := range s.blockchain.GetAllContracts() {
+		if contract.Status != blockchain.StatusRejected {
+			contracts = append(contracts, contract)
+		}
+	}
+
+	risks := make(map[string]*ContractRisk)
+	raise := func(contract *blockchain.Contract, flag string, description string, related []string) {
+		risk, ok := risks[contract.ID]
+		if !ok {
+			risk = newContractRisk(contract)
+			risks[contract.ID] = risk
+		}
+		weight := flagWeights[flag]
+		risk.Alerts = append(risk.Alerts, &Alert{
+			ContractID:       contract.ID,
+			EntityCode:       contract.EntityCode,
+			Flag:             flag,
+			Severity:         severityForWeight(weight),
+			Weight:           weight,
+			Description:      description,
+			RelatedContracts: related,
+			DetectedAt:       now,
+		})
+	}
+
+	detector := &detector{blockchain: s.blockchain, config: s.config, raise: raise}
+	detector.splitting(contracts)
+	detector.sameValidator(contracts)
+	detector.concentratedValidator(contracts)
+	detector.fastApprovals(contracts)
+	detector.singleBidder(contracts)
+
+	for _, risk := range risks {
+		for _, alert := range risk.Alerts {
+			risk.Score += alert.Weight
+		}
+		if risk.Score > 100 {
+			risk.Score = 100
+		}
+		risk.Level = levelForScore(risk.Score)
+	}
+
+	s.risks = risks
+	s.lastHash = lastHash
+	s.computedAt = now
+	return risks, now
+}
+
+func newContractRisk(contract *blockchain.Contract) *ContractRisk {
+	return &ContractRisk{
+		ContractID: contract.ID,
+		EntityCode: contract.EntityCode,
+		EntityName: contract.EntityName,
+		Level:      SeverityLow,
+		Alerts:     make([]*Alert, 0),
+	}
+}
+
+func severityForWeight(weight int) string {
+	switch {
+	case weight >= 30:
+		return SeverityHigh
+	case weight >= 15:
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+func levelForScore(score int) string {
+	switch {
+	case score >= 75:
+		return SeverityCritical
+	case score >= 50:
+		return SeverityHigh
+	case score >= 25:
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityMedium:
+		return 1
+	case SeverityHigh:
+		return 2
+	case SeverityCritical:
+		return 3
+	}
+	return 0
+}
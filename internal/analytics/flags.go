@@ -0,0 +1,263 @@
+package analytics
+
+import (
+	"fmt"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+// directModality is exempt from single-bidder checks: it has one offer by design
+const directModality = "CONTRATACION_DIRECTA"
+
+// detector runs each red-flag rule over the contracts
+type detector struct {
+	blockchain *blockchain.Blockchain
+	config     Config
+	raise      func(contract *blockchain.Contract, flag string, description string, related []string)
+}
+
+// threshold is a contract value ceiling that triggers a stricter process
+type threshold struct {
+	name  string
+	value money.Decimal
+}
+
+// thresholds returns the ceilings in pesos that apply to contracts of a year
+func (d *detector) thresholds(year int) []threshold {
+	converter := d.blockchain.GetConverter()
+	limits := d.blockchain.GetEntityLimits()
+
+	budget, err := d.blockchain.ValueInSMMLV(limits.AnnualBudget, money.COP)
+	if err != nil {
+		budget = money.Zero
+	}
+	var result []threshold
+	minima, menor, err := d.blockchain.GetModalityRules().Thresholds(budget)
+	if err == nil {
+		if value, err := converter.FromSMMLV(minima, year); err == nil {
+			result = append(result, threshold{name: "mínima cuantía", value: value})
+		}
+		if value, err := converter.FromSMMLV(menor, year); err == nil {
+			result = append(result, threshold{name: "menor cuantía", value: value})
+		}
+	}
+	if limits.HigherAuthorityThreshold.IsPositive() {
+		result = append(result, threshold{name: "comité de contratación", value: limits.HigherAuthorityThreshold})
+	}
+	return result
+}
+
+// splitting flags groups of contracts of the same entity and type that each
+// fall just under a threshold but together exceed it within the window
+func (d *detector) splitting(contracts []*blockchain.Contract) {
+	type candidate struct {
+		contract *blockchain.Contract
+		value    money.Decimal
+	}
+
+	lowerFactor := money.FromCents(int64(100 - d.config.SplitMarginPercent))
+	groups := make(map[string][]candidate)
+	limits := make(map[string]threshold)
+	for _, contract := range contracts {
+		value, err := d.blockchain.ValueInCOP(contract.Amount, contract.Currency)
+		if err != nil {
+			continue
+		}
+		for _, t := range d.thresholds(contract.CreatedAt.Year()) {
+			lower, err := t.value.Mul(lowerFactor)
+			if err != nil || !t.value.IsPositive() || value.GreaterThan(t.value) || value.LessThan(lower) {
+				continue
+			}
+			key := strings.Join([]string{contract.EntityCode, strings.ToUpper(contract.ContractType), t.name, t.value.String()}, "|")
+			groups[key] = append(groups[key], candidate{contract: contract, value: value})
+			limits[key] = t
+		}
+	}
+
+	for key, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		t := limits[key]
+		sort.Slice(group, func(i, j int) bool { return group[i].contract.CreatedAt.Before(group[j].contract.CreatedAt) })
+
+		for i, current := range group {
+			total := current.value
+			var related []string
+			for j, other := range group {
+				if i == j || absDuration(other.contract.CreatedAt.Sub(current.contract.CreatedAt)) > d.config.SplitWindow {
+					continue
+				}
+				total = total.Add(other.value)
+				related = append(related, other.contract.ID)
+			}
+			if len(related) == 0 || !total.GreaterThan(t.value) {
+				continue
+			}
+			d.raise(current.contract, FlagSplitting, fmt.Sprintf(
+				"%d contratos de tipo %s cercanos al umbral de %s (%s COP) en %d días suman %s COP",
+				len(related)+1, current.contract.ContractType, t.name, t.value, int(d.config.SplitWindow.Hours()/24), total,
+			), related)
+		}
+	}
+}
+
+// sameValidator flags contracts where one person approved several steps
+func (d *detector) sameValidator(contracts []*blockchain.Contract) {
+	for _, contract := range contracts {
+		steps := make(map[string][]string)
+		for _, step := range contract.ValidationSteps {
+			if step.Status != blockchain.ValidationApproved || step.ValidatorID == "" {
+				continue
+			}
+			steps[step.ValidatorID] = append(steps[step.ValidatorID], fmt.Sprintf("%d (%s)", step.StepNumber, step.Role))
+		}
+
+		validators := make([]string, 0, len(steps))
+		for validatorID := range steps {
+			validators = append(validators, validatorID)
+		}
+		sort.Strings(validators)
+		for _, validatorID := range validators {
+			if len(steps[validatorID]) < 2 {
+				continue
+			}
+			d.raise(contract, FlagSameValidator, fmt.Sprintf(
+				"el validador %s aprobó %d pasos del mismo contrato: %s",
+				validatorID, len(steps[validatorID]), strings.Join(steps[validatorID], ", "),
+			), nil)
+		}
+	}
+}
+
+// concentratedValidator flags contracts approved by a validator who signs a
+// disproportionate share of the entity's approvals
+func (d *detector) concentratedValidator(contracts []*blockchain.Contract) {
+	type entityApprovals struct {
+		contracts int
+		total     int
+		byUser    map[string]int
+		touched   map[string][]*blockchain.Contract
+	}
+
+	entities := make(map[string]*entityApprovals)
+	for _, contract := range contracts {
+		entity, ok := entities[contract.EntityCode]
+		if !ok {
+			entity = &entityApprovals{byUser: make(map[string]int), touched: make(map[string][]*blockchain.Contract)}
+			entities[contract.EntityCode] = entity
+		}
+		entity.contracts++
+
+		seen := make(map[string]bool)
+		for _, step := range contract.ValidationSteps {
+			// El formulador crea todos los proyectos; su aprobación no indica concentración
+			if step.Status != blockchain.ValidationApproved || step.ValidatorID == "" || step.Role == blockchain.RoleProjectDeveloper {
+				continue
+			}
+			entity.total++
+			entity.byUser[step.ValidatorID]++
+			if !seen[step.ValidatorID] {
+				seen[step.ValidatorID] = true
+				entity.touched[step.ValidatorID] = append(entity.touched[step.ValidatorID], contract)
+			}
+		}
+	}
+
+	for entityCode, entity := range entities {
+		if entity.contracts < d.config.ValidatorMinContracts || entity.total == 0 {
+			continue
+		}
+		for validatorID, approvals := range entity.byUser {
+			if approvals*100 < entity.total*d.config.ValidatorSharePercent {
+				continue
+			}
+			for _, contract := range entity.touched[validatorID] {
+				d.raise(contract, FlagConcentratedValidator, fmt.Sprintf(
+					"el validador %s firmó %d de %d aprobaciones de la entidad %s (%d%%)",
+					validatorID, approvals, entity.total, entityCode, approvals*100/entity.total,
+				), nil)
+			}
+		}
+	}
+}
+
+// fastApprovals flags steps and workflows approved unusually fast
+func (d *detector) fastApprovals(contracts []*blockchain.Contract) {
+	for _, contract := range contracts {
+		var fast []string
+		var lastApproval time.Time
+		for _, step := range contract.ValidationSteps {
+			if step.Status != blockchain.ValidationApproved {
+				continue
+			}
+			if step.Timestamp.After(lastApproval) {
+				lastApproval = step.Timestamp
+			}
+			if step.Role == blockchain.RoleProjectDeveloper || step.StartedAt.IsZero() {
+				continue
+			}
+			if elapsed := step.Timestamp.Sub(step.StartedAt); elapsed < d.config.FastStep {
+				fast = append(fast, fmt.Sprintf("%d (%s) en %s", step.StepNumber, step.Role, elapsed.Round(time.Second)))
+			}
+		}
+		if len(fast) > 0 {
+			d.raise(contract, FlagFastApproval, fmt.Sprintf(
+				"pasos aprobados en menos de %s: %s", d.config.FastStep, strings.Join(fast, ", "),
+			), nil)
+		}
+
+		completed := len(contract.ValidationSteps) > 0 && contract.CurrentStep > len(contract.ValidationSteps)
+		if completed && !lastApproval.IsZero() {
+			if elapsed := lastApproval.Sub(contract.CreatedAt); elapsed < d.config.FastWorkflow {
+				d.raise(contract, FlagFastWorkflow, fmt.Sprintf(
+					"flujo de %d pasos aprobado en %s (umbral %s)", len(contract.ValidationSteps), elapsed.Round(time.Second), d.config.FastWorkflow,
+				), nil)
+			}
+		}
+	}
+}
+
+// singleBidder flags competitive awards with a single proposal, and suppliers
+// that repeatedly win that way at the same entity
+func (d *detector) singleBidder(contracts []*blockchain.Contract) {
+	groups := make(map[string][]*blockchain.Contract)
+	for _, contract := range contracts {
+		if contract.SupplierNIT == "" || contract.ProposalsReceived != 1 || contract.Modality == directModality {
+			continue
+		}
+		key := contract.EntityCode + "|" + contract.SupplierNIT
+		groups[key] = append(groups[key], contract)
+	}
+
+	for _, group := range groups {
+		if len(group) == 1 {
+			contract := group[0]
+			d.raise(contract, FlagSingleBidder, fmt.Sprintf(
+				"adjudicado a %s con un solo proponente en modalidad %s", contract.SupplierNIT, contract.Modality,
+			), nil)
+			continue
+		}
+		for _, contract := range group {
+			var related []string
+			for _, other := range group {
+				if other.ID != contract.ID {
+					related = append(related, other.ID)
+				}
+			}
+			d.raise(contract, FlagRepeatedSingleBidder, fmt.Sprintf(
+				"%s ganó %d procesos de %s como único proponente", contract.SupplierNIT, len(group), contract.EntityCode,
+			), related)
+		}
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,597 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"secop-blockchain/internal/config"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de bloque de los casos de auditoría
+const (
+	BlockTypeAuditCaseOpened = "AUDIT_CASE_OPENED"
+	BlockTypeAuditFinding    = "AUDIT_FINDING_ADDED"
+	BlockTypeAuditResponse   = "AUDIT_RESPONSE_SUBMITTED"
+	BlockTypeAuditCaseClosed = "AUDIT_CASE_CLOSED"
+)
+
+// DefaultAuditResponseDays es el plazo en días hábiles para responder un hallazgo
+const DefaultAuditResponseDays = 10
+
+// AuditCaseStatus define el estado de un caso de auditoría
+type AuditCaseStatus string
+
+const (
+	AuditCaseOpen             AuditCaseStatus = "OPEN"              // Abierto sin hallazgos pendientes
+	AuditCaseAwaitingResponse AuditCaseStatus = "AWAITING_RESPONSE" // Hallazgos sin respuesta de la entidad
+	AuditCaseResponded        AuditCaseStatus = "RESPONDED"         // Todos los hallazgos respondidos
+	AuditCaseClosed           AuditCaseStatus = "CLOSED"
+)
+
+// FindingSeverity define la gravedad de un hallazgo
+type FindingSeverity string
+
+const (
+	FindingLow      FindingSeverity = "LOW"
+	FindingMedium   FindingSeverity = "MEDIUM"
+	FindingHigh     FindingSeverity = "HIGH"
+	FindingCritical FindingSeverity = "CRITICAL"
+)
+
+// Resultados del cierre de un caso
+const (
+	AuditOutcomeArchived  = "ARCHIVED"           // Sin mérito o respuestas satisfactorias
+	AuditOutcomeConfirmed = "FINDINGS_CONFIRMED" // Hallazgos en firme, plan de mejoramiento
+	AuditOutcomeReferred  = "REFERRED"           // Traslado a autoridad fiscal, disciplinaria o penal
+)
+
+// AuditResponse es la respuesta de la entidad contratante a un hallazgo
+type AuditResponse struct {
+	Text        string    `json:"text"`
+	RespondedBy string    `json:"responded_by"`
+	Role        AdminRole `json:"role"`
+	RespondedAt time.Time `json:"responded_at"`
+	Late        bool      `json:"late"`
+	BlockHash   string    `json:"block_hash"`
+}
+
+// AuditFinding es un hallazgo registrado dentro de un caso de auditoría
+type AuditFinding struct {
+	ID              string          `json:"id"`
+	Number          int             `json:"number"`
+	Description     string          `json:"description"`
+	Criteria        string          `json:"criteria"` // Norma o criterio incumplido
+	Severity        FindingSeverity `json:"severity"`
+	RecordedBy      string          `json:"recorded_by"`
+	RecordedAt      time.Time       `json:"recorded_at"`
+	ResponseDueDate time.Time       `json:"response_due_date"`
+	Response        *AuditResponse  `json:"response,omitempty"`
+	Overdue         bool            `json:"overdue"`
+	BlockHash       string          `json:"block_hash"`
+}
+
+// AuditCase es un caso de auditoría abierto por una entidad de control sobre un contrato
+type AuditCase struct {
+	ID         string          `json:"id"`
+	Number     string          `json:"number"`
+	ContractID string          `json:"contract_id"`
+	EntityCode string          `json:"entity_code"`
+	Subject    string          `json:"subject"`
+	OpenedBy   string          `json:"opened_by"`
+	OpenerRole AdminRole       `json:"opener_role"`
+	OpenedAt   time.Time       `json:"opened_at"`
+	Status     AuditCaseStatus `json:"status"`
+	Findings   []*AuditFinding `json:"findings"`
+	Outcome    string          `json:"outcome,omitempty"`
+	Conclusion string          `json:"conclusion,omitempty"`
+	ClosedBy   string          `json:"closed_by,omitempty"`
+	ClosedAt   time.Time       `json:"closed_at,omitempty"`
+	BlockHash  string          `json:"block_hash"`
+}
+
+// AuditCaseFilter selecciona casos de auditoría
+type AuditCaseFilter struct {
+	ContractID  string
+	EntityCode  string
+	Status      AuditCaseStatus
+	OverdueOnly bool
+}
+
+// auditRegistry mantiene los casos de auditoría derivados de los bloques
+type auditRegistry struct {
+	cases     map[string]*AuditCase
+	sequences map[string]int
+	mutex     sync.RWMutex
+	ops       sync.Mutex // Serializa validación y registro
+}
+
+func newAuditRegistry() *auditRegistry {
+	return &auditRegistry{
+		cases:     make(map[string]*AuditCase),
+		sequences: make(map[string]int),
+	}
+}
+
+// ConfigureAuditDeadlines establece el plazo en días hábiles para responder hallazgos
+func (wm *WorkflowManager) ConfigureAuditDeadlines(responseDays int) {
+	wm.auditResponseDays = responseDays
+}
+
+// isControlRole indica si el rol pertenece a un órgano de control con facultades de auditoría formal
+func isControlRole(role AdminRole) bool {
+	return role == RoleComptroller || role == RoleProsecutor
+}
+
+// isEntityRole indica si el rol pertenece a la entidad contratante
+func isEntityRole(role AdminRole) bool {
+	switch role {
+	case RoleProjectDeveloper, RoleTechnicalCommission, RoleLegalCommission, RoleContractsChief,
+		RoleAdminChief, RoleContractingCommittee, RoleBudgetAuthority:
+		return true
+	}
+	return false
+}
+
+// OpenAuditCase abre un caso de auditoría sobre un contrato. No bloquea el flujo de validación.
+func (wm *WorkflowManager) OpenAuditCase(contractID string, auditorID string, role AdminRole, subject string) (*AuditCase, error) {
+	wm.blockchain.audits.ops.Lock()
+	defer wm.blockchain.audits.ops.Unlock()
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+
+	contract, exists := wm.blockchain.Contracts[contractID]
+	if !exists {
+		return nil, errors.New("contrato no encontrado")
+	}
+	if !isControlRole(role) {
+		return nil, fmt.Errorf("el rol %s no puede abrir casos de auditoría", role)
+	}
+	if err := wm.checkAuditAuthority(contract, role); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(subject) == "" {
+		return nil, errors.New("objeto de la auditoría requerido")
+	}
+	for _, existing := range wm.blockchain.GetAuditCases(AuditCaseFilter{ContractID: contractID}) {
+		if existing.Status != AuditCaseClosed && existing.OpenerRole == role {
+			return nil, fmt.Errorf("ya existe el caso %s abierto por %s sobre este contrato", existing.Number, role)
+		}
+	}
+
+	caseID := uuid.New().String()
+	now := config.GetColombianTime()
+	wm.addAuditEntry(contract, "AUDIT_CASE_OPENED", auditorID, role, fmt.Sprintf("Caso de auditoría abierto: %s", subject))
+
+	block, err := wm.blockchain.addBlockAt(map[string]interface{}{
+		"type":        BlockTypeAuditCaseOpened,
+		"case_id":     caseID,
+		"contract_id": contractID,
+		"entity_code": contract.EntityCode,
+		"subject":     subject,
+		"opened_by":   auditorID,
+		"role":        string(role),
+		"timestamp":   now,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	wm.finishAuditEvent(contract, block)
+	return wm.blockchain.GetAuditCase(caseID), nil
+}
+
+// AddAuditFinding registra un hallazgo con su gravedad y el plazo de respuesta de la entidad
+func (wm *WorkflowManager) AddAuditFinding(caseID string, auditorID string, role AdminRole, finding *AuditFinding, responseDays int) (*AuditFinding, error) {
+	wm.blockchain.audits.ops.Lock()
+	defer wm.blockchain.audits.ops.Unlock()
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+
+	auditCase, contract, err := wm.openCase(caseID, role)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(finding.Description) == "" {
+		return nil, errors.New("descripción del hallazgo requerida")
+	}
+	finding.Severity = FindingSeverity(strings.ToUpper(string(finding.Severity)))
+	switch finding.Severity {
+	case FindingLow, FindingMedium, FindingHigh, FindingCritical:
+	case "":
+		return nil, errors.New("gravedad del hallazgo requerida")
+	default:
+		return nil, fmt.Errorf("gravedad inválida: %s", finding.Severity)
+	}
+	if responseDays <= 0 {
+		responseDays = wm.auditResponseDays
+	}
+	if responseDays <= 0 {
+		responseDays = DefaultAuditResponseDays
+	}
+
+	now := config.GetColombianTime()
+	due := wm.businessCalendar().AddBusinessDays(now, responseDays)
+	due = time.Date(due.Year(), due.Month(), due.Day(), 23, 59, 59, 0, config.ColombianTimezone)
+	findingID := uuid.New().String()
+
+	wm.addAuditEntry(contract, "AUDIT_FINDING_ADDED", auditorID, role,
+		fmt.Sprintf("Hallazgo %s en el caso %s: %s (respuesta hasta %s)", finding.Severity, auditCase.Number, finding.Description, due.Format("2006-01-02")))
+
+	block, err := wm.blockchain.addBlockAt(map[string]interface{}{
+		"type":              BlockTypeAuditFinding,
+		"case_id":           caseID,
+		"finding_id":        findingID,
+		"contract_id":       contract.ID,
+		"description":       finding.Description,
+		"criteria":          finding.Criteria,
+		"severity":          string(finding.Severity),
+		"recorded_by":       auditorID,
+		"role":              string(role),
+		"response_due_date": due,
+		"timestamp":         now,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	wm.finishAuditEvent(contract, block)
+	for _, recorded := range wm.blockchain.GetAuditCase(caseID).Findings {
+		if recorded.ID == findingID {
+			return recorded, nil
+		}
+	}
+	return nil, errors.New("hallazgo no registrado")
+}
+
+// RespondAuditFinding registra la respuesta de la entidad contratante a un hallazgo
+func (wm *WorkflowManager) RespondAuditFinding(caseID string, findingID string, userID string, role AdminRole, response string) error {
+	wm.blockchain.audits.ops.Lock()
+	defer wm.blockchain.audits.ops.Unlock()
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+
+	auditCase := wm.blockchain.GetAuditCase(caseID)
+	if auditCase == nil {
+		return errors.New("caso de auditoría no encontrado")
+	}
+	if auditCase.Status == AuditCaseClosed {
+		return fmt.Errorf("el caso %s está cerrado", auditCase.Number)
+	}
+	if !isEntityRole(role) {
+		return fmt.Errorf("el rol %s no puede responder hallazgos en nombre de la entidad", role)
+	}
+	if strings.TrimSpace(response) == "" {
+		return errors.New("respuesta requerida")
+	}
+	contract, exists := wm.blockchain.Contracts[auditCase.ContractID]
+	if !exists {
+		return errors.New("contrato no encontrado")
+	}
+
+	var finding *AuditFinding
+	for _, candidate := range auditCase.Findings {
+		if candidate.ID == findingID {
+			finding = candidate
+		}
+	}
+	if finding == nil {
+		return errors.New("hallazgo no encontrado")
+	}
+	if finding.Response != nil {
+		return fmt.Errorf("el hallazgo %d ya fue respondido", finding.Number)
+	}
+
+	now := config.GetColombianTime()
+	late := now.After(finding.ResponseDueDate)
+	description := fmt.Sprintf("Respuesta al hallazgo %d del caso %s", finding.Number, auditCase.Number)
+	if late {
+		description += " (extemporánea)"
+	}
+	wm.addAuditEntry(contract, "AUDIT_RESPONSE_SUBMITTED", userID, role, description)
+
+	block, err := wm.blockchain.addBlockAt(map[string]interface{}{
+		"type":         BlockTypeAuditResponse,
+		"case_id":      caseID,
+		"finding_id":   findingID,
+		"contract_id":  contract.ID,
+		"response":     response,
+		"responded_by": userID,
+		"role":         string(role),
+		"late":         late,
+		"timestamp":    now,
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	wm.finishAuditEvent(contract, block)
+	return nil
+}
+
+// CloseAuditCase cierra un caso cuando todos sus hallazgos fueron respondidos o su plazo venció
+func (wm *WorkflowManager) CloseAuditCase(caseID string, auditorID string, role AdminRole, outcome string, conclusion string) error {
+	wm.blockchain.audits.ops.Lock()
+	defer wm.blockchain.audits.ops.Unlock()
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+
+	auditCase, contract, err := wm.openCase(caseID, role)
+	if err != nil {
+		return err
+	}
+	outcome = strings.ToUpper(strings.TrimSpace(outcome))
+	switch outcome {
+	case AuditOutcomeArchived, AuditOutcomeConfirmed, AuditOutcomeReferred:
+	default:
+		return fmt.Errorf("resultado inválido: %q (use %s, %s o %s)", outcome, AuditOutcomeArchived, AuditOutcomeConfirmed, AuditOutcomeReferred)
+	}
+	if outcome != AuditOutcomeArchived && len(auditCase.Findings) == 0 {
+		return fmt.Errorf("el resultado %s requiere al menos un hallazgo", outcome)
+	}
+	if strings.TrimSpace(conclusion) == "" {
+		return errors.New("conclusión requerida")
+	}
+	for _, finding := range auditCase.Findings {
+		if finding.Response == nil && !finding.Overdue {
+			return fmt.Errorf("el hallazgo %d está en término de respuesta hasta %s", finding.Number, finding.ResponseDueDate.Format("2006-01-02"))
+		}
+	}
+
+	now := config.GetColombianTime()
+	wm.addAuditEntry(contract, "AUDIT_CASE_CLOSED", auditorID, role, fmt.Sprintf("Caso %s cerrado (%s): %s", auditCase.Number, outcome, conclusion))
+
+	block, err := wm.blockchain.addBlockAt(map[string]interface{}{
+		"type":        BlockTypeAuditCaseClosed,
+		"case_id":     caseID,
+		"contract_id": contract.ID,
+		"outcome":     outcome,
+		"conclusion":  conclusion,
+		"closed_by":   auditorID,
+		"role":        string(role),
+		"timestamp":   now,
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	wm.finishAuditEvent(contract, block)
+	return nil
+}
+
+// openCase obtiene un caso abierto y verifica que el rol sea el que lo abrió
+func (wm *WorkflowManager) openCase(caseID string, role AdminRole) (*AuditCase, *Contract, error) {
+	auditCase := wm.blockchain.GetAuditCase(caseID)
+	if auditCase == nil {
+		return nil, nil, errors.New("caso de auditoría no encontrado")
+	}
+	if auditCase.Status == AuditCaseClosed {
+		return nil, nil, fmt.Errorf("el caso %s está cerrado", auditCase.Number)
+	}
+	if role != auditCase.OpenerRole {
+		return nil, nil, fmt.Errorf("el caso %s es de competencia de %s, no de %s", auditCase.Number, auditCase.OpenerRole, role)
+	}
+	contract, exists := wm.blockchain.Contracts[auditCase.ContractID]
+	if !exists {
+		return nil, nil, errors.New("contrato no encontrado")
+	}
+	if err := wm.checkAuditAuthority(contract, role); err != nil {
+		return nil, nil, err
+	}
+	return auditCase, contract, nil
+}
+
+// finishAuditEvent enlaza la entrada de auditoría con su bloque, actualiza el
+// estado de control del contrato y notifica a los listeners. La entrada toma la
+// fecha del bloque, que es la que usan los nodos que reproducen la cadena.
+func (wm *WorkflowManager) finishAuditEvent(contract *Contract, block *Block) {
+	entry := &contract.AuditTrail[len(contract.AuditTrail)-1]
+	entry.BlockHash = block.Hash
+	entry.Timestamp = block.Timestamp
+	contract.AuditStatus = wm.blockchain.auditStatusFor(contract.ID)
+	contract.UpdatedAt = block.Timestamp
+	wm.blockchain.reindexContract(contract)
+	wm.notifyListeners(contract)
+}
+
+// auditStatusFor deriva el estado de control de un contrato de sus casos abiertos.
+// Es independiente del estado del flujo y no lo bloquea.
+func (bc *Blockchain) auditStatusFor(contractID string) ContractStatus {
+	status := ContractStatus("")
+	for _, auditCase := range bc.GetAuditCases(AuditCaseFilter{ContractID: contractID}) {
+		switch {
+		case auditCase.Status == AuditCaseClosed:
+		case len(auditCase.Findings) > 0:
+			return StatusAuditObservations
+		default:
+			status = StatusUnderAudit
+		}
+	}
+	return status
+}
+
+// GetAuditCase obtiene un caso de auditoría por ID
+func (bc *Blockchain) GetAuditCase(id string) *AuditCase {
+	bc.audits.mutex.RLock()
+	defer bc.audits.mutex.RUnlock()
+
+	auditCase, exists := bc.audits.cases[id]
+	if !exists {
+		return nil
+	}
+	return auditCaseView(auditCase, config.GetColombianTime())
+}
+
+// GetAuditCases lista los casos de auditoría según el filtro, los más recientes primero
+func (bc *Blockchain) GetAuditCases(filter AuditCaseFilter) []*AuditCase {
+	bc.audits.mutex.RLock()
+	defer bc.audits.mutex.RUnlock()
+
+	now := config.GetColombianTime()
+	result := make([]*AuditCase, 0)
+	for _, auditCase := range bc.audits.cases {
+		if filter.ContractID != "" && auditCase.ContractID != filter.ContractID {
+			continue
+		}
+		if filter.EntityCode != "" && auditCase.EntityCode != filter.EntityCode {
+			continue
+		}
+		view := auditCaseView(auditCase, now)
+		if filter.Status != "" && view.Status != filter.Status {
+			continue
+		}
+		if filter.OverdueOnly && !view.hasOverdueFindings() {
+			continue
+		}
+		result = append(result, view)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].OpenedAt.Equal(result[j].OpenedAt) {
+			return result[i].OpenedAt.After(result[j].OpenedAt)
+		}
+		return result[i].Number < result[j].Number
+	})
+	return result
+}
+
+func (c *AuditCase) hasOverdueFindings() bool {
+	for _, finding := range c.Findings {
+		if finding.Overdue {
+			return true
+		}
+	}
+	return false
+}
+
+// auditCaseView retorna una copia del caso con su estado y vencimientos calculados
+func auditCaseView(auditCase *AuditCase, now time.Time) *AuditCase {
+	view := *auditCase
+	view.Findings = make([]*AuditFinding, len(auditCase.Findings))
+
+	pending := 0
+	for i, finding := range auditCase.Findings {
+		findingView := *finding
+		if finding.Response != nil {
+			response := *finding.Response
+			findingView.Response = &response
+		} else {
+			pending++
+			findingView.Overdue = view.Status != AuditCaseClosed && now.After(finding.ResponseDueDate)
+		}
+		view.Findings[i] = &findingView
+	}
+
+	if view.Status != AuditCaseClosed {
+		switch {
+		case pending > 0:
+			view.Status = AuditCaseAwaitingResponse
+		case len(view.Findings) > 0:
+			view.Status = AuditCaseResponded
+		default:
+			view.Status = AuditCaseOpen
+		}
+	}
+	return &view
+}
+
+// apply actualiza el registro con un bloque de auditoría. Se usa tanto para
+// bloques nuevos como al reconstruir desde la cadena.
+func (r *auditRegistry) apply(block *Block) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	data := block.Data
+	timestamp := dataTime(data, "timestamp", block.Timestamp)
+	switch block.Type {
+	case BlockTypeAuditCaseOpened:
+		role := AdminRole(dataString(data, "role"))
+		entityCode := dataString(data, "entity_code")
+		key := fmt.Sprintf("AUD-%s-%s-%d", role, entityCode, timestamp.Year())
+		r.sequences[key]++
+		auditCase := &AuditCase{
+			ID:         dataString(data, "case_id"),
+			Number:     fmt.Sprintf("%s-%04d", key, r.sequences[key]),
+			ContractID: dataString(data, "contract_id"),
+			EntityCode: entityCode,
+			Subject:    dataString(data, "subject"),
+			OpenedBy:   dataString(data, "opened_by"),
+			OpenerRole: role,
+			OpenedAt:   timestamp,
+			Status:     AuditCaseOpen,
+			Findings:   make([]*AuditFinding, 0),
+			BlockHash:  block.Hash,
+		}
+		r.cases[auditCase.ID] = auditCase
+
+	case BlockTypeAuditFinding:
+		auditCase, exists := r.cases[dataString(data, "case_id")]
+		if !exists || auditCase.Status == AuditCaseClosed {
+			return
+		}
+		auditCase.Findings = append(auditCase.Findings, &AuditFinding{
+			ID:              dataString(data, "finding_id"),
+			Number:          len(auditCase.Findings) + 1,
+			Description:     dataString(data, "description"),
+			Criteria:        dataString(data, "criteria"),
+			Severity:        FindingSeverity(dataString(data, "severity")),
+			RecordedBy:      dataString(data, "recorded_by"),
+			RecordedAt:      timestamp,
+			ResponseDueDate: dataTime(data, "response_due_date", timestamp),
+			BlockHash:       block.Hash,
+		})
+
+	case BlockTypeAuditResponse:
+		auditCase, exists := r.cases[dataString(data, "case_id")]
+		if !exists || auditCase.Status == AuditCaseClosed {
+			return
+		}
+		findingID := dataString(data, "finding_id")
+		for _, finding := range auditCase.Findings {
+			if finding.ID == findingID && finding.Response == nil {
+				late, _ := data["late"].(bool)
+				finding.Response = &AuditResponse{
+					Text:        dataString(data, "response"),
+					RespondedBy: dataString(data, "responded_by"),
+					Role:        AdminRole(dataString(data, "role")),
+					RespondedAt: timestamp,
+					Late:        late,
+					BlockHash:   block.Hash,
+				}
+			}
+		}
+
+	case BlockTypeAuditCaseClosed:
+		auditCase, exists := r.cases[dataString(data, "case_id")]
+		if !exists {
+			return
+		}
+		auditCase.Status = AuditCaseClosed
+		auditCase.Outcome = dataString(data, "outcome")
+		auditCase.Conclusion = dataString(data, "conclusion")
+		auditCase.ClosedBy = dataString(data, "closed_by")
+		auditCase.ClosedAt = timestamp
+	}
+}
+
+func (r *auditRegistry) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.cases = make(map[string]*AuditCase)
+	r.sequences = make(map[string]int)
+}
+
+// rebuildAuditRegistry reconstruye los casos de auditoría desde la cadena y
+// el estado de control de los contratos
+func (bc *Blockchain) rebuildAuditRegistry() {
+	bc.audits.reset()
+	for _, block := range bc.Chain {
+		bc.audits.apply(block)
+	}
+	for _, contract := range bc.Contracts {
+		contract.AuditStatus = bc.auditStatusFor(contract.ID)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"time"
 	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/money"
 )
 
 // Block representa un bloque en la blockchain SECOP
@@ -24,10 +25,22 @@ type Contract struct {
 	ID              string             `json:"id"`
 	EntityCode      string             `json:"entity_code"`
 	EntityName      string             `json:"entity_name"`
+	EntityRegion    string             `json:"entity_region,omitempty"` // Municipio y/o departamento de la entidad
+	EntityLevel     string             `json:"entity_level,omitempty"`  // Nacional, Departamental o Municipal
 	ContractType    string             `json:"contract_type"`
 	Description     string             `json:"description"`
-	Amount          float64            `json:"amount"`
+	Amount          money.Decimal      `json:"amount"`
+	Currency        money.Currency     `json:"currency"`
+	Modality        string             `json:"modality"` // Modalidad de selección exigida por las reglas
+	WorkflowTemplate string            `json:"workflow_template"`
+	RequiredDocuments []string         `json:"required_documents"`
+	Documents       []string           `json:"documents"` // Tipos de documento aportados
+	SupplierNIT     string             `json:"supplier_nit,omitempty"` // Adjudicatario
+	SupplierName    string             `json:"supplier_name,omitempty"`
+	ProposalsReceived int              `json:"proposals_received,omitempty"`
+	AwardedAt       time.Time          `json:"awarded_at,omitempty"`
 	Status          ContractStatus     `json:"status"`
+	AuditStatus     ContractStatus     `json:"audit_status,omitempty"` // UNDER_AUDIT o AUDIT_OBSERVATIONS mientras haya casos abiertos
 	CreatedBy       string             `json:"created_by"`
 	CreatedAt       time.Time          `json:"created_at"`
 	UpdatedAt       time.Time          `json:"updated_at"`
@@ -50,6 +63,7 @@ const (
 	StatusContractsApproved       ContractStatus = "CONTRACTS_APPROVED"
 	StatusAdminReview             ContractStatus = "ADMIN_REVIEW"
 	StatusAdminApproved           ContractStatus = "ADMIN_APPROVED"
+	StatusCommitteeReview         ContractStatus = "COMMITTEE_REVIEW"
 	StatusBudgetReview            ContractStatus = "BUDGET_REVIEW"
 	StatusAuthorizedForPublication ContractStatus = "AUTHORIZED_FOR_PUBLICATION"
 	StatusPublished               ContractStatus = "PUBLISHED"
@@ -76,6 +90,10 @@ type ValidationStep struct {
 	Required      bool                   `json:"required"`
 	DigitalSign   string                 `json:"digital_sign"`
 	Documents     []string               `json:"documents"`
+	StartedAt     time.Time              `json:"started_at,omitempty"`
+	DueDate       time.Time              `json:"due_date,omitempty"`
+	Escalated     bool                   `json:"escalated"`
+	EscalatedAt   time.Time              `json:"escalated_at,omitempty"`
 }
 
 // AdminRole define los roles administrativos internos
@@ -87,6 +105,7 @@ const (
 	RoleLegalCommission   AdminRole = "LEGAL_COMMISSION"
 	RoleContractsChief    AdminRole = "CONTRACTS_CHIEF"
 	RoleAdminChief        AdminRole = "ADMIN_CHIEF"
+	RoleContractingCommittee AdminRole = "CONTRACTING_COMMITTEE"
 	RoleBudgetAuthority   AdminRole = "BUDGET_AUTHORITY"
 	// Roles de control externo (solo auditoría)
 	RoleComptroller       AdminRole = "COMPTROLLER"
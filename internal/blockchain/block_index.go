@@ -0,0 +1,247 @@
+package blockchain
+
+import (
+	"errors"
+	"secop-blockchain/internal/config"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GenesisBlockType identifica el bloque génesis, que no tiene tipo propio
+const GenesisBlockType = "GENESIS"
+
+// DailyBlockCount representa la cantidad de bloques de un día
+type DailyBlockCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// ChainStats resume la actividad de la cadena para el explorador
+type ChainStats struct {
+	Height         int               `json:"height"`
+	TotalContracts int               `json:"total_contracts"`
+	FirstBlockAt   time.Time         `json:"first_block_at"`
+	LastBlockAt    time.Time         `json:"last_block_at"`
+	BlocksPerDay   []DailyBlockCount `json:"blocks_per_day"`
+	BlocksByType   map[string]int    `json:"blocks_by_type"`
+	BlocksByEntity map[string]int    `json:"blocks_by_entity"`
+}
+
+// blockIndex mantiene índices secundarios sobre los bloques de la cadena
+type blockIndex struct {
+	byHash           map[string]*Block
+	byType           map[string][]int
+	byContract       map[string][]int
+	byEntity         map[string][]int
+	byDay            map[string]int
+	contractEntities map[string]string
+	mutex            sync.RWMutex
+}
+
+// newBlockIndex crea un índice de bloques vacío
+func newBlockIndex() *blockIndex {
+	return &blockIndex{
+		byHash:           make(map[string]*Block),
+		byType:           make(map[string][]int),
+		byContract:       make(map[string][]int),
+		byEntity:         make(map[string][]int),
+		byDay:            make(map[string]int),
+		contractEntities: make(map[string]string),
+	}
+}
+
+// add indexa un bloque recién agregado a la cadena
+func (idx *blockIndex) add(block *Block) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.byHash[block.Hash] = block
+	blockType := blockTypeKey(block)
+	idx.byType[blockType] = append(idx.byType[blockType], block.Index)
+	idx.byDay[config.ToColombianTime(block.Timestamp).Format("2006-01-02")]++
+
+	contractID, _ := block.Data["contract_id"].(string)
+	entityCode, _ := block.Data["entity_code"].(string)
+	if contractID != "" {
+		idx.byContract[contractID] = append(idx.byContract[contractID], block.Index)
+		if entityCode != "" {
+			idx.contractEntities[contractID] = entityCode
+		} else {
+			entityCode = idx.contractEntities[contractID]
+		}
+	}
+	if entityCode != "" {
+		idx.byEntity[entityCode] = append(idx.byEntity[entityCode], block.Index)
+	}
+}
+
+// reset reconstruye el índice a partir de una cadena completa
+func (idx *blockIndex) reset(chain []*Block) {
+	idx.mutex.Lock()
+	idx.byHash = make(map[string]*Block)
+	idx.byType = make(map[string][]int)
+	idx.byContract = make(map[string][]int)
+	idx.byEntity = make(map[string][]int)
+	idx.byDay = make(map[string]int)
+	idx.contractEntities = make(map[string]string)
+	idx.mutex.Unlock()
+
+	for _, block := range chain {
+		idx.add(block)
+	}
+}
+
+// blockTypeKey retorna la clave de tipo con la que se indexa un bloque
+func blockTypeKey(block *Block) string {
+	if block.Type == "" && block.Index == 0 {
+		return GenesisBlockType
+	}
+	return block.Type
+}
+
+// rebuildBlockIndex reconstruye los índices de bloques tras reemplazar la cadena
+func (bc *Blockchain) rebuildBlockIndex() {
+	bc.blocks.reset(bc.Chain)
+}
+
+// GetBlockByIndex obtiene un bloque por su índice
+func (bc *Blockchain) GetBlockByIndex(index int) (*Block, error) {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
+	if index < 0 || index >= len(bc.Chain) {
+		return nil, errors.New("bloque no encontrado")
+	}
+	return bc.Chain[index], nil
+}
+
+// GetBlockByHash busca un bloque por su hash
+func (bc *Blockchain) GetBlockByHash(hash string) *Block {
+	bc.blocks.mutex.RLock()
+	defer bc.blocks.mutex.RUnlock()
+	return bc.blocks.byHash[hash]
+}
+
+// GetLatestBlocks retorna los últimos n bloques, del más reciente al más antiguo
+func (bc *Blockchain) GetLatestBlocks(n int) []*Block {
+	if n <= 0 {
+		n = 10
+	}
+	if n > MaxPageSize {
+		n = MaxPageSize
+	}
+
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
+	blocks := make([]*Block, 0, n)
+	for i := len(bc.Chain) - 1; i >= 0 && len(blocks) < n; i-- {
+		blocks = append(blocks, bc.Chain[i])
+	}
+	return blocks
+}
+
+// GetBlocksByType retorna una página de bloques de un tipo
+func (bc *Blockchain) GetBlocksByType(blockType string, cursor string, limit int, descending bool) (*BlockPage, error) {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
+	bc.blocks.mutex.RLock()
+	indexes := bc.blocks.byType[blockType]
+	bc.blocks.mutex.RUnlock()
+	return bc.pageBlocks(indexes, cursor, limit, descending)
+}
+
+// GetBlocksByContract retorna una página de bloques que involucran un contrato
+func (bc *Blockchain) GetBlocksByContract(contractID string, cursor string, limit int, descending bool) (*BlockPage, error) {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
+	bc.blocks.mutex.RLock()
+	indexes := bc.blocks.byContract[contractID]
+	bc.blocks.mutex.RUnlock()
+	return bc.pageBlocks(indexes, cursor, limit, descending)
+}
+
+// GetChainStats calcula estadísticas de la cadena por día, tipo y entidad
+func (bc *Blockchain) GetChainStats() *ChainStats {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+	bc.blocks.mutex.RLock()
+	defer bc.blocks.mutex.RUnlock()
+
+	stats := &ChainStats{
+		Height:         len(bc.Chain),
+		TotalContracts: len(bc.Contracts),
+		BlocksPerDay:   make([]DailyBlockCount, 0, len(bc.blocks.byDay)),
+		BlocksByType:   make(map[string]int, len(bc.blocks.byType)),
+		BlocksByEntity: make(map[string]int, len(bc.blocks.byEntity)),
+	}
+
+	if len(bc.Chain) > 0 {
+		stats.FirstBlockAt = bc.Chain[0].Timestamp
+		stats.LastBlockAt = bc.Chain[len(bc.Chain)-1].Timestamp
+	}
+	for day, count := range bc.blocks.byDay {
+		stats.BlocksPerDay = append(stats.BlocksPerDay, DailyBlockCount{Date: day, Count: count})
+	}
+	sort.Slice(stats.BlocksPerDay, func(i, j int) bool {
+		return stats.BlocksPerDay[i].Date < stats.BlocksPerDay[j].Date
+	})
+	for blockType, indexes := range bc.blocks.byType {
+		stats.BlocksByType[blockType] = len(indexes)
+	}
+	for entity, indexes := range bc.blocks.byEntity {
+		stats.BlocksByEntity[entity] = len(indexes)
+	}
+
+	return stats
+}
+
+// pageBlocks pagina una lista ascendente de índices de bloque.
+// El cursor es el índice del último bloque de la página anterior. Requiere el
+// mutex de la cadena tomado.
+func (bc *Blockchain) pageBlocks(indexes []int, cursor string, limit int, descending bool) (*BlockPage, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	pos := 0
+	if descending {
+		pos = len(indexes) - 1
+	}
+	if cursor != "" {
+		last, err := strconv.Atoi(cursor)
+		if err != nil || last < 0 {
+			return nil, errors.New("cursor inválido")
+		}
+		// Primera posición con índice mayor al cursor
+		pos = sort.SearchInts(indexes, last+1)
+		if descending {
+			pos = sort.SearchInts(indexes, last) - 1
+		}
+	}
+
+	blocks := make([]*Block, 0, limit)
+	for pos >= 0 && pos < len(indexes) && len(blocks) < limit {
+		if indexes[pos] < len(bc.Chain) {
+			blocks = append(blocks, bc.Chain[indexes[pos]])
+		}
+		if descending {
+			pos--
+		} else {
+			pos++
+		}
+	}
+
+	page := &BlockPage{Blocks: blocks, Height: len(bc.Chain)}
+	if len(blocks) > 0 && pos >= 0 && pos < len(indexes) {
+		page.NextCursor = strconv.Itoa(blocks[len(blocks)-1].Index)
+	}
+	return page, nil
+}
@@ -4,22 +4,66 @@ import (
 	"errors"
 	"fmt"
 	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/modality"
+	"secop-blockchain/internal/money"
+	"secop-blockchain/internal/sanctions"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// Blockchain representa la cadena de bloques SECOP
+// Blockchain representa la cadena de bloques SECOP. Los handlers HTTP y las
+// tareas programadas la usan en paralelo: Chain, Contracts y los contratos solo
+// se leen o modifican con mutex tomado, por lo que desde fuera del paquete se
+// accede a ellos por los métodos, que retornan copias de los contratos.
 type Blockchain struct {
 	Chain           []*Block             `json:"chain"`
 	Contracts       map[string]*Contract `json:"contracts"`
 	WorkflowManager *WorkflowManager     `json:"-"`
+	mutex           sync.RWMutex
+	index           *contractIndex
+	blocks          *blockIndex
+	listeners       []BlockListener
+	localListeners  []BlockListener
+	pending         []pendingBlock // Bloques agregados que aún no se informan a los listeners
+	pendingMutex    sync.Mutex
+	dispatch        sync.Mutex // Serializa la notificación para respetar el orden de la cadena
+	limits          EntityLimits
+	budget          *budgetLedger
+	converter       *money.Converter
+	modality        *modality.Engine
+	suppliers       *supplierRegistry
+	audits          *auditRegistry
+	complaints      *complaintRegistry
+	sanctions       sanctions.Checker
 }
 
+// ErrInvalidBlockData indica que un bloque recibido tiene un hash correcto pero
+// un contenido que viola las reglas del libro, como comprometer más saldo del
+// disponible. Quien lo envió violó el protocolo.
+var ErrInvalidBlockData = errors.New("contenido de bloque inválido")
+
+// BlockListener es notificado cada vez que se agrega un bloque a la cadena. Se
+// invoca sin el mutex de la cadena tomado, así que puede consultarla, pero no
+// debe agregar bloques.
+type BlockListener func(block *Block)
+
+// pendingBlock es un bloque agregado que falta informar a los listeners
+type pendingBlock struct {
+	block *Block
+	local bool
+}
+
+// genesisTimestamp es la fecha fija del bloque génesis: todos los nodos deben
+// generar el mismo génesis para reconocer como válidas las cadenas de los demás
+var genesisTimestamp = time.Date(2025, time.January, 1, 0, 0, 0, 0, config.ColombianTimezone)
+
 // NewBlockchain crea una nueva blockchain con bloque génesis
 func NewBlockchain() *Blockchain {
 	genesisBlock := &Block{
 		Index:        0,
-		Timestamp:    config.GetColombianTime(),
+		Timestamp:    genesisTimestamp,
 		Data:         map[string]interface{}{"message": "SECOP Blockchain Genesis Block"},
 		PreviousHash: "",
 		Nonce:        0,
@@ -27,9 +71,19 @@ func NewBlockchain() *Blockchain {
 	genesisBlock.Hash = genesisBlock.calculateHash()
 
 	bc := &Blockchain{
-		Chain:     []*Block{genesisBlock},
-		Contracts: make(map[string]*Contract),
-	}
+		Chain:      []*Block{genesisBlock},
+		Contracts:  make(map[string]*Contract),
+		blocks:     newBlockIndex(),
+		budget:     newBudgetLedger(),
+		converter:  money.NewConverter(nil, nil),
+		modality:   modality.Default(),
+		suppliers:  newSupplierRegistry(),
+		audits:     newAuditRegistry(),
+		complaints: newComplaintRegistry(),
+		sanctions:  sanctions.None{},
+	}
+	bc.index = newContractIndex(bc.contractValue)
+	bc.blocks.add(genesisBlock)
 	
 	// Inicializar el gestor de flujo de trabajo
 	bc.WorkflowManager = NewWorkflowManager(bc)
@@ -39,11 +93,19 @@ func NewBlockchain() *Blockchain {
 
 // AddContract agrega un nuevo contrato a la blockchain con flujo de trabajo
 func (bc *Blockchain) AddContract(contract *Contract) error {
+	bc.lock()
+	defer bc.unlock()
+
 	// Validar contrato
 	if err := bc.validateContract(contract); err != nil {
 		return err
 	}
 
+	// Determinar modalidad de selección, documentos requeridos y flujo
+	if err := bc.applyModality(contract); err != nil {
+		return err
+	}
+
 	// Generar ID único si no existe
 	if contract.ID == "" {
 		contract.ID = uuid.New().String()
@@ -64,17 +126,29 @@ func (bc *Blockchain) AddContract(contract *Contract) error {
 
 	// Crear bloque para el contrato
 	blockData := map[string]interface{}{
-		"type":        "CONTRACT_CREATION",
-		"contract_id": contract.ID,
-		"entity_code": contract.EntityCode,
-		"entity_name": contract.EntityName,
-		"amount":      contract.Amount,
-		"created_by":  contract.CreatedBy,
-		"timestamp":   contract.CreatedAt,
+		"type":               "CONTRACT_CREATION",
+		"contract_id":        contract.ID,
+		"entity_code":        contract.EntityCode,
+		"entity_name":        contract.EntityName,
+		"entity_region":      contract.EntityRegion,
+		"entity_level":       contract.EntityLevel,
+		"contract_type":      contract.ContractType,
+		"description":        contract.Description,
+		"amount":             contract.Amount.String(),
+		"currency":           string(contract.Currency),
+		"modality":           contract.Modality,
+		"workflow_template":  contract.WorkflowTemplate,
+		"required_documents": contract.RequiredDocuments,
+		"documents":          contract.Documents,
+		"steps":              stepRoles(contract),
+		"created_by":         contract.CreatedBy,
+		"status":             string(contract.Status),
+		"next_role":          string(bc.WorkflowManager.getNextRole(contract)),
+		"timestamp":          contract.CreatedAt,
 	}
 
 	// Agregar bloque y obtener hash
-	_, err := bc.AddBlock(blockData)
+	_, err := bc.addBlock(blockData)
 	if err != nil {
 		return err
 	}
@@ -86,7 +160,9 @@ func (bc *Blockchain) AddContract(contract *Contract) error {
 
 	// Agregar a la blockchain
 	bc.Contracts[contract.ID] = contract
+	bc.reindexContract(contract)
 
+	bc.WorkflowManager.notifyListeners(contract)
 	return nil
 }
 
@@ -105,25 +181,73 @@ func (bc *Blockchain) GetContractWorkflowStatus(contractID string) (*WorkflowSta
 	return bc.WorkflowManager.GetContractWorkflowStatus(contractID)
 }
 
-// GetContractsByStatus obtiene contratos por estado
+// GetContractsByStatus obtiene contratos por estado, ordenados por fecha de creación
 func (bc *Blockchain) GetContractsByStatus(status ContractStatus) []*Contract {
-	var contracts []*Contract
-	for _, contract := range bc.Contracts {
-		if contract.Status == status {
-			contracts = append(contracts, contract)
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+	return bc.contractsByIDs(bc.index.statusIDs(status))
+}
+
+// QueryContracts consulta contratos usando los índices secundarios
+func (bc *Blockchain) QueryContracts(query ContractQuery) (*ContractPage, error) {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
+	ids, total, nextCursor, err := bc.index.query(query)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractPage{
+		Contracts:  bc.contractsByIDs(ids),
+		Total:      total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// reindexContract actualiza los índices secundarios de un contrato
+func (bc *Blockchain) reindexContract(contract *Contract) {
+	bc.index.put(contract)
+}
+
+// rebuildContractIndex reconstruye los índices desde el mapa de contratos
+func (bc *Blockchain) rebuildContractIndex() {
+	bc.index.reset(bc.Contracts)
+}
+
+// contractsByIDs resuelve una lista ordenada de IDs a copias de los contratos
+// (requiere el mutex tomado)
+func (bc *Blockchain) contractsByIDs(ids []string) []*Contract {
+	contracts := make([]*Contract, 0, len(ids))
+	for _, id := range ids {
+		if contract, exists := bc.Contracts[id]; exists {
+			contracts = append(contracts, contract.clone())
 		}
 	}
 	return contracts
 }
 
+// clone retorna una copia del contrato que no comparte sus listas con el original
+func (c *Contract) clone() *Contract {
+	copied := *c
+	copied.RequiredDocuments = append([]string(nil), c.RequiredDocuments...)
+	copied.Documents = append([]string(nil), c.Documents...)
+	copied.ValidationSteps = append([]ValidationStep(nil), c.ValidationSteps...)
+	copied.RequiredRoles = append([]string(nil), c.RequiredRoles...)
+	copied.AuditTrail = append([]AuditEntry(nil), c.AuditTrail...)
+	return &copied
+}
+
 // GetContractsByRole obtiene contratos que requieren validación de un rol específico
 func (bc *Blockchain) GetContractsByRole(role AdminRole) []*Contract {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
 	var contracts []*Contract
 	for _, contract := range bc.Contracts {
 		if contract.CurrentStep <= len(contract.ValidationSteps) {
 			currentStepRole := contract.ValidationSteps[contract.CurrentStep-1].Role
 			if currentStepRole == role && contract.ValidationSteps[contract.CurrentStep-1].Status == ValidationPending {
-				contracts = append(contracts, contract)
+				contracts = append(contracts, contract.clone())
 			}
 		}
 	}
@@ -132,6 +256,9 @@ func (bc *Blockchain) GetContractsByRole(role AdminRole) []*Contract {
 
 // ValidateContract valida un contrato por parte de un nodo
 func (bc *Blockchain) ValidateContract(contractID string, nodeID string, approved bool, reason string) error {
+	bc.lock()
+	defer bc.unlock()
+
 	contract, exists := bc.Contracts[contractID]
 	if !exists {
 		return errors.New("contrato no encontrado")
@@ -153,33 +280,44 @@ func (bc *Blockchain) ValidateContract(contractID string, nodeID string, approve
 		fmt.Printf("✅ Validación aprobada para contrato %s por nodo %s\n", contractID, nodeID)
 	} else {
 		contract.Status = StatusRejected
+		bc.reindexContract(contract)
 		fmt.Printf("❌ Validación rechazada para contrato %s por nodo %s: %s\n", contractID, nodeID, reason)
 	}
 
-	_, err := bc.AddBlock(validationData)
+	validationData["status"] = string(contract.Status)
+
+	_, err := bc.addBlock(validationData)
 	return err
 }
 
 // GetContract obtiene un contrato por ID
 func (bc *Blockchain) GetContract(contractID string) (*Contract, error) {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
 	contract, exists := bc.Contracts[contractID]
 	if !exists {
 		return nil, errors.New("contrato no encontrado")
 	}
-	return contract, nil
+	return contract.clone(), nil
 }
 
-// GetAllContracts obtiene todos los contratos
+// GetAllContracts obtiene todos los contratos ordenados por fecha de creación
 func (bc *Blockchain) GetAllContracts() []*Contract {
-	contracts := make([]*Contract, 0, len(bc.Contracts))
-	for _, contract := range bc.Contracts {
-		contracts = append(contracts, contract)
-	}
-	return contracts
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+	return bc.contractsByIDs(bc.index.orderedIDs())
 }
 
 // IsChainValid verifica la integridad de la blockchain
 func (bc *Blockchain) IsChainValid() bool {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+	return bc.isChainValid()
+}
+
+// isChainValid verifica la integridad de la blockchain (requiere el mutex tomado)
+func (bc *Blockchain) isChainValid() bool {
 	for i := 1; i < len(bc.Chain); i++ {
 		currentBlock := bc.Chain[i]
 		previousBlock := bc.Chain[i-1]
@@ -213,17 +351,32 @@ func (bc *Blockchain) validateContract(contract *Contract) error {
 	if contract.Description == "" {
 		return errors.New("descripción requerida")
 	}
-	if contract.Amount <= 0 {
+	if !contract.Amount.IsPositive() {
 		return errors.New("monto debe ser mayor a cero")
 	}
+	currency, err := money.ParseCurrency(string(contract.Currency))
+	if err != nil {
+		return err
+	}
+	contract.Currency = currency
 	if contract.CreatedBy == "" {
 		return errors.New("creador requerido")
 	}
+	if err := bc.checkContractValue(contract); err != nil {
+		return err
+	}
 	return nil
 }
 
 // IsValidBlock valida si un bloque es válido
 func (bc *Blockchain) IsValidBlock(block Block) bool {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+	return bc.isValidBlock(block)
+}
+
+// isValidBlock valida si un bloque es válido (requiere el mutex tomado)
+func (bc *Blockchain) isValidBlock(block Block) bool {
 	// Verificar que el hash no esté vacío
 	if block.Hash == "" {
 		return false
@@ -247,19 +400,28 @@ func (bc *Blockchain) IsValidBlock(block Block) bool {
 
 // HasBlock verifica si un bloque ya existe en la cadena
 func (bc *Blockchain) HasBlock(hash string) bool {
-	for _, block := range bc.Chain {
-		if block.Hash == hash {
-			return true
-		}
-	}
-	return false
+	return bc.GetBlockByHash(hash) != nil
 }
 
 // AddBlock agrega un nuevo bloque a la cadena con datos
 func (bc *Blockchain) AddBlock(blockData map[string]interface{}) (*Block, error) {
+	bc.lock()
+	defer bc.unlock()
+	return bc.addBlock(blockData)
+}
+
+// addBlock agrega un bloque creado en este nodo (requiere el mutex tomado)
+func (bc *Blockchain) addBlock(blockData map[string]interface{}) (*Block, error) {
+	return bc.addBlockAt(blockData, config.GetColombianTime())
+}
+
+// addBlockAt agrega un bloque con la fecha dada, para que la operación que lo
+// registra use esa misma fecha en el contrato (requiere el mutex tomado)
+func (bc *Blockchain) addBlockAt(blockData map[string]interface{}, at time.Time) (*Block, error) {
 	// Crear el bloque con los datos proporcionados
 	block := NewBlock(blockData, bc.getLatestBlock().Hash)
 	block.Index = len(bc.Chain)
+	block.Timestamp = at
 	
 	// Establecer tipo de bloque si está especificado
 	if blockType, ok := blockData["type"].(string); ok {
@@ -270,47 +432,166 @@ func (bc *Blockchain) AddBlock(blockData map[string]interface{}) (*Block, error)
 	block.Hash = block.calculateHash()
 
 	// Verificar que el bloque sea válido
-	if !bc.IsValidBlock(*block) {
+	if !bc.isValidBlock(*block) {
 		return nil, errors.New("bloque inválido")
 	}
 
-	// Agregar a la cadena
+	bc.appendBlock(block)
+	bc.queueBlock(block, true)
+	return block, nil
+}
+
+// appendReceivedBlock agrega un bloque recibido de otro nodo conservando su hash
+func (bc *Blockchain) appendReceivedBlock(block *Block) error {
+	if isBudgetBlock(block.Type) {
+		bc.budget.ops.Lock()
+		defer bc.budget.ops.Unlock()
+	}
+	bc.lock()
+	defer bc.unlock()
+
+	if block.Index != len(bc.Chain) || !bc.isValidBlock(*block) {
+		return errors.New("bloque inválido")
+	}
+	if err := bc.budget.check(block); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBlockData, err)
+	}
+	bc.appendBlock(block)
+	bc.applyContractBlock(block)
+	bc.queueBlock(block, false)
+	return nil
+}
+
+// appendBlock agrega un bloque ya validado y actualiza los registros derivados
+func (bc *Blockchain) appendBlock(block *Block) {
 	bc.Chain = append(bc.Chain, block)
+	bc.blocks.add(block)
+	bc.budget.apply(block)
+	bc.suppliers.apply(block)
+	bc.audits.apply(block)
+	bc.complaints.apply(block)
 	fmt.Printf("✅ Bloque %d agregado a la cadena\n", block.Index)
-	return block, nil
+}
+
+// lock toma el mutex de la cadena para modificarla
+func (bc *Blockchain) lock() {
+	bc.mutex.Lock()
+}
+
+// unlock libera el mutex de la cadena e informa a los listeners los bloques
+// agregados mientras estaba tomado
+func (bc *Blockchain) unlock() {
+	bc.mutex.Unlock()
+	bc.notifyPending()
+}
+
+// queueBlock deja un bloque agregado pendiente de informar (requiere el mutex tomado)
+func (bc *Blockchain) queueBlock(block *Block, local bool) {
+	bc.pendingMutex.Lock()
+	bc.pending = append(bc.pending, pendingBlock{block: block, local: local})
+	bc.pendingMutex.Unlock()
+}
+
+// notifyPending informa los bloques pendientes a los listeners. Los bloques se
+// encolan en el orden de la cadena y dispatch impide que dos notificaciones se
+// intercalen, así los listeners los reciben en ese mismo orden.
+func (bc *Blockchain) notifyPending() {
+	bc.dispatch.Lock()
+	defer bc.dispatch.Unlock()
+
+	bc.pendingMutex.Lock()
+	pending := bc.pending
+	bc.pending = nil
+	bc.pendingMutex.Unlock()
+
+	for _, p := range pending {
+		for _, listener := range bc.listeners {
+			listener(p.block)
+		}
+		if p.local {
+			for _, listener := range bc.localListeners {
+				listener(p.block)
+			}
+		}
+	}
+}
+
+// AddBlockListener registra una función que se invoca al agregar cada bloque
+func (bc *Blockchain) AddBlockListener(listener BlockListener) {
+	bc.dispatch.Lock()
+	defer bc.dispatch.Unlock()
+	bc.listeners = append(bc.listeners, listener)
+}
+
+// AddLocalBlockListener registra una función que se invoca solo por los bloques
+// creados en este nodo, no por los recibidos de otros nodos
+func (bc *Blockchain) AddLocalBlockListener(listener BlockListener) {
+	bc.dispatch.Lock()
+	defer bc.dispatch.Unlock()
+	bc.localListeners = append(bc.localListeners, listener)
 }
 
 // IsValidChain valida si una cadena completa es válida
 func (bc *Blockchain) IsValidChain(chain []Block) bool {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+	return bc.isValidChain(chain)
+}
+
+// isValidChain valida una cadena completa (requiere el mutex tomado)
+func (bc *Blockchain) isValidChain(chain []Block) bool {
 	if len(chain) == 0 {
 		return false
 	}
 	
+	// La cadena debe partir de nuestro mismo bloque génesis
+	if chain[0].Hash != bc.Chain[0].Hash {
+		fmt.Printf("❌ Cadena con génesis distinto: %s\n", chain[0].Hash)
+		return false
+	}
+	
 	// Verificar cada bloque en la cadena
 	for i, block := range chain {
-		// Verificar hash del bloque
-		if block.Hash == "" {
+		// Verificar hash del bloque contra su contenido
+		if block.Hash == "" || !block.IsValid() {
+			fmt.Printf("❌ Cadena con hash inválido en el bloque %d\n", i)
 			return false
 		}
 		
-		// Verificar enlace con bloque anterior (excepto el primero)
+		// Verificar índice y enlace con bloque anterior (excepto el primero)
+		if block.Index != i {
+			fmt.Printf("❌ Cadena con índice %d en la posición %d\n", block.Index, i)
+			return false
+		}
 		if i > 0 {
 			if block.PreviousHash != chain[i-1].Hash {
+				fmt.Printf("❌ Cadena con enlace roto en el bloque %d\n", i)
 				return false
 			}
 		}
 	}
 	
+	// Los saldos presupuestales deben respetarse en toda la cadena
+	if err := checkBudgetChain(chain); err != nil {
+		fmt.Printf("❌ Cadena con bloque presupuestal inválido: %v\n", err)
+		return false
+	}
+	
 	return true
 }
 
 // GetBlockchainHeight returns the current height of the blockchain
 func (bc *Blockchain) GetBlockchainHeight() int {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
 	return len(bc.Chain)
 }
 
 // GetLastBlockHash returns the hash of the last block in the chain
 func (bc *Blockchain) GetLastBlockHash() string {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
 	if len(bc.Chain) == 0 {
 		return ""
 	}
@@ -323,16 +604,22 @@ func (bc *Blockchain) GetLastBlockHash() string {
 func (bc *Blockchain) IsSynced() bool {
 	// For now, consider synced if we have at least the genesis block
 	// and the chain is valid
-	return len(bc.Chain) > 0 && bc.IsChainValid()
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+	return len(bc.Chain) > 0 && bc.isChainValid()
 }
 
 // GetNetworkHealth returns the health status of the blockchain network
 func (bc *Blockchain) GetNetworkHealth() map[string]interface{} {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
+	valid := bc.isChainValid()
 	health := map[string]interface{}{
-		"blockchain_height":    bc.GetBlockchainHeight(),
-		"last_block_hash":     bc.GetLastBlockHash(),
-		"is_synced":           bc.IsSynced(),
-		"chain_valid":         bc.IsChainValid(),
+		"blockchain_height":    len(bc.Chain),
+		"last_block_hash":     bc.getLatestBlock().Hash,
+		"is_synced":           len(bc.Chain) > 0 && valid,
+		"chain_valid":         valid,
 		"total_contracts":     len(bc.Contracts),
 		"genesis_block_hash":  "",
 	}
@@ -352,8 +639,31 @@ func (bc *Blockchain) GetNetworkHealth() map[string]interface{} {
 	return health
 }
 
+// BlockPage represents a page of blocks
+type BlockPage struct {
+	Blocks     []*Block `json:"blocks"`
+	Height     int      `json:"height"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// GetBlocksPage returns blocks in index order starting after the cursor.
+// The cursor is the index of the last block of the previous page.
+func (bc *Blockchain) GetBlocksPage(cursor string, limit int, descending bool) (*BlockPage, error) {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
+	indexes := make([]int, len(bc.Chain))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return bc.pageBlocks(indexes, cursor, limit, descending)
+}
+
 // GetChain returns a copy of the blockchain for synchronization
 func (bc *Blockchain) GetChain() []*Block {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
 	chain := make([]*Block, len(bc.Chain))
 	copy(chain, bc.Chain)
 	return chain
@@ -361,6 +671,9 @@ func (bc *Blockchain) GetChain() []*Block {
 
 // ReplaceChain replaces the current chain with a new one if it's valid and longer
 func (bc *Blockchain) ReplaceChain(newChain []*Block) error {
+	bc.lock()
+	defer bc.unlock()
+
 	if len(newChain) <= len(bc.Chain) {
 		return errors.New("nueva cadena debe ser más larga que la actual")
 	}
@@ -371,11 +684,28 @@ func (bc *Blockchain) ReplaceChain(newChain []*Block) error {
 		chainBlocks[i] = *block
 	}
 	
-	if !bc.IsValidChain(chainBlocks) {
+	if !bc.isValidChain(chainBlocks) {
 		return errors.New("nueva cadena no es válida")
 	}
 	
+	// Bloques que la cadena actual ya tenía
+	common := 0
+	for common < len(bc.Chain) && bc.Chain[common].Hash == newChain[common].Hash {
+		common++
+	}
+	
 	bc.Chain = newChain
+	bc.rebuildBlockIndex()
+	bc.rebuildBudgetLedger()
+	bc.rebuildSupplierRegistry()
+	bc.rebuildAuditRegistry()
+	bc.rebuildComplaintRegistry()
+	bc.rebuildContracts()
 	fmt.Printf("🔄 Cadena reemplazada con nueva cadena de longitud %d\n", len(newChain))
+	
+	// Los listeners (búsqueda, eventos en vivo) reciben los bloques nuevos
+	for _, block := range newChain[common:] {
+		bc.queueBlock(block, false)
+	}
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,142 @@
+package blockchain
+
+import (
+	"fmt"
+	"secop-blockchain/internal/money"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// expiredCalendar vence cada paso el día en que empieza, para que CheckDeadlines
+// escale todos los contratos
+type expiredCalendar struct{}
+
+func (expiredCalendar) IsBusinessDay(t time.Time) bool { return true }
+
+func (expiredCalendar) AddBusinessDays(from time.Time, days int) time.Time {
+	return from.AddDate(0, 0, -days-1)
+}
+
+func (expiredCalendar) BusinessDaysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// TestConcurrentSchedulerAndHandlers runs the scheduled jobs against the
+// operations the HTTP handlers perform. Run it with -race.
+func TestConcurrentSchedulerAndHandlers(t *testing.T) {
+	bc := NewBlockchain()
+	bc.WorkflowManager.ConfigureDeadlines(expiredCalendar{}, nil, 1)
+
+	// Like the search index, a listener may read the chain it is notified about
+	var notified int64
+	bc.AddBlockListener(func(block *Block) {
+		if bc.GetBlockByHash(block.Hash) == nil {
+			t.Errorf("block %d notified before it was indexed", block.Index)
+		}
+		bc.GetAllContracts()
+		atomic.AddInt64(&notified, 1)
+	})
+
+	const rounds = 30
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	// Scheduler: contracts arrive while deadlines are checked
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			err := bc.AddContract(&Contract{
+				ID:           fmt.Sprintf("C%d", i),
+				EntityCode:   "E1",
+				EntityName:   "Alcaldía",
+				Description:  "Obra",
+				Amount:       money.New(1000000),
+				Currency:     money.COP,
+				CreatedBy:    "u1",
+				ContractType: "OBRA",
+			})
+			if err != nil {
+				t.Errorf("AddContract: %v", err)
+				return
+			}
+			if _, err := bc.WorkflowManager.CheckDeadlines(); err != nil {
+				t.Errorf("CheckDeadlines: %v", err)
+				return
+			}
+		}
+	}()
+
+	// Handlers: validate steps and read contracts and blocks
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			for _, contract := range bc.GetAllContracts() {
+				step := contract.ValidationSteps[contract.CurrentStep-1]
+				bc.WorkflowManager.ValidateStep(contract.ID, step.StepNumber, "v1", "Ana", step.Role, true, "ok")
+				bc.WorkflowManager.GetWorkflowStatus(contract.ID)
+			}
+			bc.GetChain()
+			bc.GetChainStats()
+			bc.GetLatestBlocks(5)
+			bc.WorkflowManager.GetOverdueReport("", "")
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			bc.AddBlock(map[string]interface{}{"type": "TEST", "n": i})
+			bc.GetNetworkHealth()
+		}
+	}()
+
+	wg.Wait()
+
+	if !bc.IsChainValid() {
+		t.Fatal("chain is not valid after concurrent use")
+	}
+	if got, want := atomic.LoadInt64(&notified), int64(bc.GetBlockchainHeight()-1); got != want {
+		t.Errorf("listener notified of %d blocks, want %d", got, want)
+	}
+}
+
+func TestIsValidChain(t *testing.T) {
+	bc := NewBlockchain()
+	for i := 0; i < 3; i++ {
+		if _, err := bc.AddBlock(map[string]interface{}{"type": "TEST", "n": i}); err != nil {
+			t.Fatalf("AddBlock: %v", err)
+		}
+	}
+	copyChain := func() []Block {
+		chain := make([]Block, 0, bc.GetBlockchainHeight())
+		for _, block := range bc.GetChain() {
+			chain = append(chain, *block)
+		}
+		return chain
+	}
+
+	if !NewBlockchain().IsValidChain(copyChain()) {
+		t.Error("chain from a node with the same genesis should be valid")
+	}
+
+	// Content changed after hashing, links left intact
+	tampered := copyChain()
+	tampered[2].Data = map[string]interface{}{"type": "TEST", "n": 99}
+	if bc.IsValidChain(tampered) {
+		t.Error("chain with a tampered block should be invalid")
+	}
+
+	// Same blocks on top of a different genesis
+	foreign := copyChain()
+	foreign[0].Timestamp = foreign[0].Timestamp.Add(time.Hour)
+	foreign[0].Hash = foreign[0].calculateHash()
+	for i := 1; i < len(foreign); i++ {
+		foreign[i].PreviousHash = foreign[i-1].Hash
+		foreign[i].Hash = foreign[i].calculateHash()
+	}
+	if bc.IsValidChain(foreign) {
+		t.Error("chain with a different genesis should be invalid")
+	}
+}
@@ -0,0 +1,442 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"secop-blockchain/internal/config"
+	"sync"
+	"time"
+)
+
+// Reintentos de la cola de envío
+const (
+	broadcastRetryMin = time.Second // Espera tras el primer error, se duplica en cada uno
+	broadcastRetryMax = time.Minute // Tope de la espera entre reintentos
+)
+
+// ErrBlockAhead indica que un bloque recibido es posterior a la altura local:
+// faltan bloques intermedios que el emisor debe enviar primero
+var ErrBlockAhead = errors.New("bloque posterior a la altura local")
+
+// errBlockRejected marca los bloques que el peer rechazó y no vale la pena reintentar
+var errBlockRejected = errors.New("el peer rechazó el bloque")
+
+// peerBehindError indica la altura de un peer al que le faltan bloques
+type peerBehindError struct {
+	height int
+}
+
+func (e *peerBehindError) Error() string {
+	return fmt.Sprintf("al peer le faltan bloques desde la altura %d", e.height)
+}
+
+// OutboxStatus describe la cola de bloques pendientes de envío a un peer
+type OutboxStatus struct {
+	Pending   int        `json:"pending"`
+	Delivered int        `json:"delivered"`
+	Dropped   int        `json:"dropped"`  // Descartados por cola llena o rechazados
+	Attempts  int        `json:"attempts"` // Intentos fallidos seguidos del primer bloque
+	Paused    bool       `json:"paused"`   // Esperando a que el peer vuelva a responder
+	LastError string     `json:"last_error,omitempty"`
+	NextRetry *time.Time `json:"next_retry,omitempty"`
+}
+
+// outbox es la cola de bloques de un peer, entregada en orden por un worker
+type outbox struct {
+	peerID string
+	queue  []Block
+	wake   chan struct{}
+	status OutboxStatus
+}
+
+// broadcaster lleva una cola por peer, así un peer caído o lento no retrasa
+// a los demás y recibe los bloques pendientes cuando vuelve
+type broadcaster struct {
+	timeout    time.Duration
+	maxRetries int
+	queueSize  int
+	outboxes   map[string]*outbox
+	mutex      sync.Mutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func newBroadcaster() *broadcaster {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &broadcaster{
+		timeout:    5 * time.Second,
+		maxRetries: 5,
+		queueSize:  256,
+		outboxes:   make(map[string]*outbox),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// signal despierta al worker de una cola sin bloquear
+func (box *outbox) signal() {
+	select {
+	case box.wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue agrega un bloque a la cola del peer. Retorna la cola si es nueva y
+// necesita un worker.
+func (b *broadcaster) enqueue(peerID string, block Block) (*outbox, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	box, exists := b.outboxes[peerID]
+	if !exists {
+		box = &outbox{peerID: peerID, wake: make(chan struct{}, 1)}
+		b.outboxes[peerID] = box
+	}
+	for _, queued := range box.queue {
+		if queued.Hash == block.Hash {
+			return box, false
+		}
+	}
+	// Con la cola llena se descarta el bloque más antiguo; el peer lo
+	// obtendrá con su sincronización periódica
+	if len(box.queue) >= b.queueSize {
+		box.queue = box.queue[1:]
+		box.status.Dropped++
+	}
+	box.queue = append(box.queue, block)
+	box.signal()
+	return box, !exists
+}
+
+// head retorna el primer bloque pendiente de una cola
+func (b *broadcaster) head(box *outbox) (Block, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if len(box.queue) == 0 {
+		return Block{}, false
+	}
+	return box.queue[0], true
+}
+
+// pop retira un bloque de la cabeza de la cola tras entregarlo o descartarlo.
+// Si mientras tanto salió de la cola por desbordamiento, la cola no se toca.
+func (b *broadcaster) pop(box *outbox, block Block, delivered bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if len(box.queue) > 0 && box.queue[0].Hash == block.Hash {
+		box.queue = box.queue[1:]
+	}
+	if delivered {
+		box.status.Delivered++
+	} else {
+		box.status.Dropped++
+	}
+	box.status.Attempts = 0
+	box.status.Paused = false
+	box.status.LastError = ""
+	box.status.NextRetry = nil
+}
+
+// prepend pone bloques faltantes delante de la cola para entregarlos primero
+func (b *broadcaster) prepend(box *outbox, blocks []Block) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	box.queue = append(blocks, box.queue...)
+}
+
+// failed registra un intento fallido y retorna la espera hasta el siguiente,
+// o cero si se agotaron los reintentos y la cola debe pausarse
+func (b *broadcaster) failed(box *outbox, err error) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	box.status.Attempts++
+	box.status.LastError = err.Error()
+	if box.status.Attempts >= b.maxRetries {
+		box.status.Paused = true
+		box.status.NextRetry = nil
+		return 0
+	}
+	delay := broadcastRetryMin << uint(min(box.status.Attempts-1, 16))
+	if delay > broadcastRetryMax {
+		delay = broadcastRetryMax
+	}
+	next := config.GetColombianTime().Add(delay)
+	box.status.NextRetry = &next
+	return delay
+}
+
+// pause detiene la cola hasta que el peer vuelva a responder
+func (b *broadcaster) pause(box *outbox) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	box.status.Paused = true
+	box.status.NextRetry = nil
+}
+
+// resume reanuda la cola pausada de un peer que volvió a responder
+func (b *broadcaster) resume(peerID string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	box, exists := b.outboxes[peerID]
+	if !exists || !box.status.Paused {
+		return
+	}
+	box.status.Paused = false
+	box.status.Attempts = 0
+	if len(box.queue) > 0 {
+		fmt.Printf("📬 Reanudando envío de %d bloques pendientes a %s\n", len(box.queue), peerID)
+	}
+	box.signal()
+}
+
+// remove descarta la cola de un peer que salió de la red
+func (b *broadcaster) remove(peerID string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if box, exists := b.outboxes[peerID]; exists {
+		delete(b.outboxes, peerID)
+		box.signal()
+	}
+}
+
+// active indica si la cola sigue registrada
+func (b *broadcaster) active(box *outbox) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.outboxes[box.peerID] == box
+}
+
+// status retorna una copia del estado de la cola de un peer
+func (b *broadcaster) status(peerID string) (OutboxStatus, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	box, exists := b.outboxes[peerID]
+	if !exists {
+		return OutboxStatus{}, false
+	}
+	status := box.status
+	status.Pending = len(box.queue)
+	return status, true
+}
+
+// wait espera a que la cola sea despertada, pase delay (si es positivo) o se
+// detenga la red. Retorna false al detenerse.
+func (b *broadcaster) wait(box *outbox, delay time.Duration) bool {
+	var timeout <-chan time.Time
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-b.ctx.Done():
+		return false
+	case <-box.wake:
+	case <-timeout:
+	}
+	return true
+}
+
+// ConfigureBroadcast sets the per-peer outbound queue: how long each delivery
+// may take, how many times a block is retried before the queue pauses until
+// the peer answers again, and how many blocks each queue holds
+func (p2p *P2PNetwork) ConfigureBroadcast(timeout time.Duration, maxRetries, queueSize int) {
+	if timeout > 0 {
+		p2p.broadcast.timeout = timeout
+	}
+	if maxRetries > 0 {
+		p2p.broadcast.maxRetries = maxRetries
+	}
+	if queueSize > 0 {
+		p2p.broadcast.queueSize = queueSize
+	}
+}
+
+// BroadcastBlock encola un bloque para cada peer conocido no bloqueado. Los
+// peers inactivos lo reciben cuando vuelvan a responder.
+func (p2p *P2PNetwork) BroadcastBlock(block Block) {
+	p2p.mutex.RLock()
+	peerIDs := make([]string, 0, len(p2p.Peers))
+	for id, peer := range p2p.Peers {
+		if peer.Port != "" {
+			peerIDs = append(peerIDs, id)
+		}
+	}
+	p2p.mutex.RUnlock()
+
+	queued := 0
+	for _, peerID := range peerIDs {
+		if p2p.reputation.banned(peerID) {
+			continue
+		}
+		box, created := p2p.broadcast.enqueue(peerID, block)
+		if created {
+			p2p.broadcast.wg.Add(1)
+			go p2p.deliver(box)
+		}
+		queued++
+	}
+	fmt.Printf("📡 Broadcasting bloque %s a %d peers\n", block.Hash, queued)
+}
+
+// deliver entrega en orden los bloques de la cola de un peer hasta que la red
+// se detenga o el peer salga de la lista
+func (p2p *P2PNetwork) deliver(box *outbox) {
+	b := p2p.broadcast
+	defer b.wg.Done()
+
+	for {
+		if !b.active(box) {
+			return
+		}
+		block, pending := b.head(box)
+		if !pending {
+			if !b.wait(box, 0) {
+				return
+			}
+			continue
+		}
+
+		p2p.mutex.RLock()
+		peer, exists := p2p.Peers[box.peerID]
+		var target Peer
+		if exists {
+			target = *peer
+		}
+		p2p.mutex.RUnlock()
+		if !exists {
+			b.remove(box.peerID)
+			return
+		}
+
+		// Un peer caído o bloqueado conserva sus bloques hasta que vuelva
+		if !target.Active || p2p.reputation.banned(target.ID) {
+			b.pause(box)
+			if !b.wait(box, 0) {
+				return
+			}
+			continue
+		}
+
+		started := time.Now()
+		err := p2p.sendBlockToPeer(b.ctx, &target, block)
+		var behind *peerBehindError
+		switch {
+		case err == nil:
+			b.pop(box, block, true)
+			p2p.recordSuccess(target.ID, time.Since(started))
+		case errors.As(err, &behind):
+			p2p.recordSuccess(target.ID, time.Since(started))
+			p2p.catchUp(box, target.ID, behind.height, block)
+		case errors.Is(err, errBlockRejected):
+			fmt.Printf("⚠️ %s rechazó el bloque %d: %v\n", target.ID, block.Index, err)
+			b.pop(box, block, false)
+		default:
+			if b.ctx.Err() != nil {
+				return
+			}
+			fmt.Printf("❌ Error enviando bloque %d a %s: %v\n", block.Index, target.ID, err)
+			p2p.recordFailure(target.ID)
+			delay := b.failed(box, err)
+			if delay == 0 {
+				fmt.Printf("⏸️ Envíos a %s pausados hasta que vuelva a responder\n", target.ID)
+			}
+			if !b.wait(box, delay) {
+				return
+			}
+		}
+	}
+}
+
+// catchUp pone delante de la cola los bloques que le faltan a un peer atrasado.
+// Si le faltan más de los que caben en la cola, el bloque se descarta y el
+// peer se pone al día con su sincronización periódica.
+func (p2p *P2PNetwork) catchUp(box *outbox, peerID string, height int, block Block) {
+	missing := block.Index - height
+	if height < 0 || missing <= 0 || missing > p2p.broadcast.queueSize {
+		fmt.Printf("⚠️ %s está %d bloques atrás; se pondrá al día con su sincronización\n", peerID, missing)
+		p2p.broadcast.pop(box, block, false)
+		return
+	}
+
+	blocks := make([]Block, 0, missing)
+	for i := height; i < block.Index; i++ {
+		local, err := p2p.Blockchain.GetBlockByIndex(i)
+		if err != nil {
+			p2p.broadcast.pop(box, block, false)
+			return
+		}
+		blocks = append(blocks, *local)
+	}
+	fmt.Printf("📬 Enviando %d bloques faltantes a %s\n", len(blocks), peerID)
+	p2p.broadcast.prepend(box, blocks)
+}
+
+// sendBlockToPeer envía un bloque a un peer específico
+func (p2p *P2PNetwork) sendBlockToPeer(ctx context.Context, peer *Peer, block Block) error {
+	ctx, cancel := context.WithTimeout(ctx, p2p.broadcast.timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("http://%s:%s/api/p2p/receive-block", peer.Address, peer.Port)
+
+	blockData, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(blockData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(NodeIDHeader, p2p.NodeID)
+	resp, err := p2pClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusConflict:
+		var body struct {
+			Height int `json:"height"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body); err != nil {
+			return fmt.Errorf("%w: respuesta inválida a un bloque adelantado", errBlockRejected)
+		}
+		return &peerBehindError{height: body.Height}
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return fmt.Errorf("%w: status %d", errBlockRejected, resp.StatusCode)
+	default:
+		return fmt.Errorf("peer respondió con status %d", resp.StatusCode)
+	}
+}
+
+// ReceiveBlock agrega un bloque recibido de otro peer conservando su hash.
+// Recibir de nuevo un bloque ya agregado no es un error, así los reintentos
+// del emisor son seguros.
+func (p2p *P2PNetwork) ReceiveBlock(block Block) error {
+	if p2p.Blockchain.HasBlock(block.Hash) {
+		return nil
+	}
+	if block.Index > p2p.Blockchain.GetBlockchainHeight() {
+		return ErrBlockAhead
+	}
+	if err := p2p.Blockchain.appendReceivedBlock(&block); err != nil {
+		return err
+	}
+	fmt.Printf("📥 Bloque %d recibido de la red\n", block.Index)
+	return nil
+}
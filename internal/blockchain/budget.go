@@ -0,0 +1,725 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/money"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de bloque del libro presupuestal
+const (
+	BlockTypeAppropriation = "BUDGET_APPROPRIATION"
+	BlockTypeCDPIssued     = "CDP_ISSUED"
+	BlockTypeCDPReleased   = "CDP_RELEASED"
+	BlockTypeCommitment    = "RP_REGISTERED"
+)
+
+// CDPStatus define el estado de un certificado de disponibilidad presupuestal
+type CDPStatus string
+
+const (
+	CDPActive   CDPStatus = "ACTIVE"
+	CDPReleased CDPStatus = "RELEASED"
+	CDPExpired  CDPStatus = "EXPIRED"
+)
+
+// Appropriation es un rubro presupuestal apropiado a una entidad para una vigencia
+type Appropriation struct {
+	ID          string        `json:"id"`
+	EntityCode  string        `json:"entity_code"`
+	Code        string        `json:"code"` // Código del rubro, ej: 2.1.2.02.02
+	Description string        `json:"description"`
+	FiscalYear  int           `json:"fiscal_year"`
+	Amount      money.Decimal `json:"amount"`
+	Certified   money.Decimal `json:"certified"` // Reservado por CDP (incluye lo comprometido)
+	Committed   money.Decimal `json:"committed"` // Comprometido por RP
+	Available   money.Decimal `json:"available"` // Saldo libre para nuevos CDP
+	CreatedBy   string        `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+	BlockHash   string        `json:"block_hash"`
+}
+
+// CDP es un certificado de disponibilidad presupuestal expedido contra un rubro
+type CDP struct {
+	ID              string        `json:"id"`
+	Number          string        `json:"number"`
+	AppropriationID string        `json:"appropriation_id"`
+	EntityCode      string        `json:"entity_code"`
+	ContractID      string        `json:"contract_id,omitempty"`
+	Purpose         string        `json:"purpose"`
+	Amount          money.Decimal `json:"amount"`
+	Committed       money.Decimal `json:"committed"`
+	Balance         money.Decimal `json:"balance"` // Saldo sin comprometer
+	Status          CDPStatus     `json:"status"`
+	IssuedBy        string        `json:"issued_by"`
+	IssuedAt        time.Time     `json:"issued_at"`
+	ExpiresAt       time.Time     `json:"expires_at"`
+	BlockHash       string        `json:"block_hash"`
+}
+
+// Commitment es un registro presupuestal (RP) que compromete el saldo de un CDP
+type Commitment struct {
+	ID           string        `json:"id"`
+	Number       string        `json:"number"`
+	CDPID        string        `json:"cdp_id"`
+	ContractID   string        `json:"contract_id"`
+	Beneficiary  string        `json:"beneficiary"`
+	Amount       money.Decimal `json:"amount"` // Valor del contrato en pesos
+	RegisteredBy string        `json:"registered_by"`
+	RegisteredAt time.Time     `json:"registered_at"`
+	BlockHash    string        `json:"block_hash"`
+}
+
+// ContractBudget resume la cobertura presupuestal de un contrato
+type ContractBudget struct {
+	ContractID   string        `json:"contract_id"`
+	Amount       money.Decimal `json:"amount"`  // Valor del contrato en pesos
+	Covered      money.Decimal `json:"covered"` // Saldo de CDP vigentes
+	Committed    money.Decimal `json:"committed"`
+	FullyCovered bool          `json:"fully_covered"`
+	CDPs         []*CDP        `json:"cdps"`
+	Commitments  []*Commitment `json:"commitments"`
+}
+
+// budgetLedger mantiene el estado presupuestal derivado de los bloques
+type budgetLedger struct {
+	appropriations map[string]*Appropriation
+	cdps           map[string]*CDP
+	commitments    map[string]*Commitment
+	sequences      map[string]int // Consecutivos por tipo, entidad y vigencia
+	mutex          sync.RWMutex
+	ops            sync.Mutex // Serializa validación y registro de operaciones
+}
+
+func newBudgetLedger() *budgetLedger {
+	return &budgetLedger{
+		appropriations: make(map[string]*Appropriation),
+		cdps:           make(map[string]*CDP),
+		commitments:    make(map[string]*Commitment),
+		sequences:      make(map[string]int),
+	}
+}
+
+// RegisterAppropriation registra un rubro presupuestal en la cadena
+func (bc *Blockchain) RegisterAppropriation(appropriation *Appropriation) error {
+	bc.budget.ops.Lock()
+	defer bc.budget.ops.Unlock()
+
+	if appropriation.EntityCode == "" {
+		return errors.New("código de entidad requerido")
+	}
+	if appropriation.Code == "" {
+		return errors.New("código de rubro requerido")
+	}
+	if !appropriation.Amount.IsPositive() {
+		return errors.New("monto debe ser mayor a cero")
+	}
+	if appropriation.FiscalYear == 0 {
+		appropriation.FiscalYear = config.GetColombianTime().Year()
+	}
+
+	bc.budget.mutex.RLock()
+	for _, existing := range bc.budget.appropriations {
+		if existing.EntityCode == appropriation.EntityCode && existing.Code == appropriation.Code && existing.FiscalYear == appropriation.FiscalYear {
+			bc.budget.mutex.RUnlock()
+			return fmt.Errorf("el rubro %s ya está apropiado para la vigencia %d", appropriation.Code, appropriation.FiscalYear)
+		}
+	}
+	bc.budget.mutex.RUnlock()
+
+	appropriation.ID = uuid.New().String()
+	appropriation.CreatedAt = config.GetColombianTime()
+
+	_, err := bc.AddBlock(map[string]interface{}{
+		"type":             BlockTypeAppropriation,
+		"appropriation_id": appropriation.ID,
+		"entity_code":      appropriation.EntityCode,
+		"code":             appropriation.Code,
+		"description":      appropriation.Description,
+		"fiscal_year":      appropriation.FiscalYear,
+		"amount":           appropriation.Amount.String(),
+		"created_by":       appropriation.CreatedBy,
+		"timestamp":        appropriation.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	*appropriation = *bc.GetAppropriation(appropriation.ID)
+	return nil
+}
+
+// IssueCDP expide un CDP contra el saldo disponible de un rubro
+func (bc *Blockchain) IssueCDP(cdp *CDP) error {
+	bc.budget.ops.Lock()
+	defer bc.budget.ops.Unlock()
+	bc.lock()
+	defer bc.unlock()
+
+	if !cdp.Amount.IsPositive() {
+		return errors.New("monto debe ser mayor a cero")
+	}
+
+	appropriation := bc.GetAppropriation(cdp.AppropriationID)
+	if appropriation == nil {
+		return errors.New("rubro presupuestal no encontrado")
+	}
+
+	now := config.GetColombianTime()
+	expiresAt := endOfFiscalYear(appropriation.FiscalYear)
+	if now.After(expiresAt) {
+		return fmt.Errorf("la vigencia %d del rubro %s ya terminó", appropriation.FiscalYear, appropriation.Code)
+	}
+	if cdp.Amount.GreaterThan(appropriation.Available) {
+		return fmt.Errorf("saldo insuficiente en el rubro %s: disponible %s, solicitado %s", appropriation.Code, appropriation.Available, cdp.Amount)
+	}
+	if cdp.ContractID != "" {
+		contract, exists := bc.Contracts[cdp.ContractID]
+		if !exists {
+			return errors.New("contrato no encontrado")
+		}
+		if contract.EntityCode != appropriation.EntityCode {
+			return fmt.Errorf("el contrato pertenece a %s y el rubro a %s", contract.EntityCode, appropriation.EntityCode)
+		}
+	}
+
+	cdp.ID = uuid.New().String()
+
+	_, err := bc.addBlock(map[string]interface{}{
+		"type":             BlockTypeCDPIssued,
+		"cdp_id":           cdp.ID,
+		"appropriation_id": appropriation.ID,
+		"entity_code":      appropriation.EntityCode,
+		"contract_id":      cdp.ContractID,
+		"purpose":          cdp.Purpose,
+		"amount":           cdp.Amount.String(),
+		"issued_by":        cdp.IssuedBy,
+		"expires_at":       expiresAt,
+		"timestamp":        now,
+	})
+	if err != nil {
+		return err
+	}
+
+	*cdp = *bc.GetCDP(cdp.ID)
+	return nil
+}
+
+// ReleaseCDP libera el saldo sin comprometer de un CDP y lo devuelve al rubro
+func (bc *Blockchain) ReleaseCDP(cdpID string, releasedBy string, reason string) error {
+	bc.budget.ops.Lock()
+	defer bc.budget.ops.Unlock()
+
+	cdp := bc.GetCDP(cdpID)
+	if cdp == nil {
+		return errors.New("CDP no encontrado")
+	}
+	if cdp.Status != CDPActive {
+		return fmt.Errorf("el CDP %s no está vigente (%s)", cdp.Number, cdp.Status)
+	}
+
+	_, err := bc.AddBlock(map[string]interface{}{
+		"type":        BlockTypeCDPReleased,
+		"cdp_id":      cdp.ID,
+		"contract_id": cdp.ContractID,
+		"released":    cdp.Balance.String(),
+		"released_by": releasedBy,
+		"reason":      reason,
+		"timestamp":   config.GetColombianTime(),
+	})
+	return err
+}
+
+// RegisterCommitment registra un RP que compromete el saldo de un CDP vigente
+func (bc *Blockchain) RegisterCommitment(commitment *Commitment) error {
+	bc.budget.ops.Lock()
+	defer bc.budget.ops.Unlock()
+	bc.lock()
+	defer bc.unlock()
+
+	if !commitment.Amount.IsPositive() {
+		return errors.New("monto debe ser mayor a cero")
+	}
+	if commitment.ContractID == "" {
+		return errors.New("contrato requerido")
+	}
+
+	cdp := bc.GetCDP(commitment.CDPID)
+	if cdp == nil {
+		return errors.New("CDP no encontrado")
+	}
+	if cdp.Status != CDPActive {
+		return fmt.Errorf("el CDP %s no está vigente (%s)", cdp.Number, cdp.Status)
+	}
+	if cdp.ContractID != "" && cdp.ContractID != commitment.ContractID {
+		return fmt.Errorf("el CDP %s está expedido para otro contrato", cdp.Number)
+	}
+	contract, exists := bc.Contracts[commitment.ContractID]
+	if !exists {
+		return errors.New("contrato no encontrado")
+	}
+	if contract.Status == StatusRejected {
+		return errors.New("no se puede comprometer presupuesto para un contrato rechazado")
+	}
+	if contract.CurrentStep <= len(contract.ValidationSteps) {
+		return errors.New("el contrato debe estar autorizado por el ordenador del gasto antes del registro presupuestal")
+	}
+	if commitment.Beneficiary == "" {
+		commitment.Beneficiary = contract.SupplierNIT
+	}
+	if commitment.Beneficiary == "" {
+		return errors.New("beneficiario requerido")
+	}
+	if commitment.Amount.GreaterThan(cdp.Balance) {
+		return fmt.Errorf("saldo insuficiente en el CDP %s: disponible %s, solicitado %s", cdp.Number, cdp.Balance, commitment.Amount)
+	}
+
+	commitment.ID = uuid.New().String()
+
+	_, err := bc.addBlock(map[string]interface{}{
+		"type":          BlockTypeCommitment,
+		"commitment_id": commitment.ID,
+		"cdp_id":        cdp.ID,
+		"contract_id":   commitment.ContractID,
+		"beneficiary":   commitment.Beneficiary,
+		"amount":        commitment.Amount.String(),
+		"registered_by": commitment.RegisteredBy,
+		"timestamp":     config.GetColombianTime(),
+	})
+	if err != nil {
+		return err
+	}
+
+	*commitment = *bc.GetCommitment(commitment.ID)
+	return nil
+}
+
+// GetAppropriations lista los rubros, opcionalmente filtrados por entidad y vigencia
+func (bc *Blockchain) GetAppropriations(entityCode string, fiscalYear int) []*Appropriation {
+	bc.budget.mutex.RLock()
+	defer bc.budget.mutex.RUnlock()
+
+	result := make([]*Appropriation, 0)
+	for _, appropriation := range bc.budget.appropriations {
+		if entityCode != "" && appropriation.EntityCode != entityCode {
+			continue
+		}
+		if fiscalYear != 0 && appropriation.FiscalYear != fiscalYear {
+			continue
+		}
+		view := *appropriation
+		result = append(result, &view)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FiscalYear != result[j].FiscalYear {
+			return result[i].FiscalYear > result[j].FiscalYear
+		}
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+// GetAppropriation obtiene un rubro por ID
+func (bc *Blockchain) GetAppropriation(id string) *Appropriation {
+	bc.budget.mutex.RLock()
+	defer bc.budget.mutex.RUnlock()
+
+	appropriation, exists := bc.budget.appropriations[id]
+	if !exists {
+		return nil
+	}
+	view := *appropriation
+	return &view
+}
+
+// GetCDPs lista los CDP filtrados por rubro y contrato
+func (bc *Blockchain) GetCDPs(appropriationID string, contractID string) []*CDP {
+	bc.budget.mutex.RLock()
+	defer bc.budget.mutex.RUnlock()
+
+	now := config.GetColombianTime()
+	result := make([]*CDP, 0)
+	for _, cdp := range bc.budget.cdps {
+		if appropriationID != "" && cdp.AppropriationID != appropriationID {
+			continue
+		}
+		if contractID != "" && cdp.ContractID != contractID {
+			continue
+		}
+		result = append(result, cdpView(cdp, now))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Number < result[j].Number })
+	return result
+}
+
+// GetCDP obtiene un CDP por ID
+func (bc *Blockchain) GetCDP(id string) *CDP {
+	bc.budget.mutex.RLock()
+	defer bc.budget.mutex.RUnlock()
+
+	cdp, exists := bc.budget.cdps[id]
+	if !exists {
+		return nil
+	}
+	return cdpView(cdp, config.GetColombianTime())
+}
+
+// GetCommitments lista los RP filtrados por CDP y contrato
+func (bc *Blockchain) GetCommitments(cdpID string, contractID string) []*Commitment {
+	bc.budget.mutex.RLock()
+	defer bc.budget.mutex.RUnlock()
+
+	result := make([]*Commitment, 0)
+	for _, commitment := range bc.budget.commitments {
+		if cdpID != "" && commitment.CDPID != cdpID {
+			continue
+		}
+		if contractID != "" && commitment.ContractID != contractID {
+			continue
+		}
+		view := *commitment
+		result = append(result, &view)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Number < result[j].Number })
+	return result
+}
+
+// GetCommitment obtiene un RP por ID
+func (bc *Blockchain) GetCommitment(id string) *Commitment {
+	bc.budget.mutex.RLock()
+	defer bc.budget.mutex.RUnlock()
+
+	commitment, exists := bc.budget.commitments[id]
+	if !exists {
+		return nil
+	}
+	view := *commitment
+	return &view
+}
+
+// GetContractBudget resume los CDP y RP asociados a un contrato
+func (bc *Blockchain) GetContractBudget(contractID string) (*ContractBudget, error) {
+	bc.mutex.RLock()
+	defer bc.mutex.RUnlock()
+
+	contract, exists := bc.Contracts[contractID]
+	if !exists {
+		return nil, errors.New("contrato no encontrado")
+	}
+	return bc.contractBudget(contract), nil
+}
+
+// contractBudget resume el presupuesto de un contrato (requiere el mutex tomado)
+func (bc *Blockchain) contractBudget(contract *Contract) *ContractBudget {
+	budget := &ContractBudget{
+		ContractID:  contract.ID,
+		Amount:      bc.contractValue(contract),
+		CDPs:        bc.GetCDPs("", contract.ID),
+		Commitments: bc.GetCommitments("", contract.ID),
+	}
+	for _, cdp := range budget.CDPs {
+		if cdp.Status == CDPActive {
+			budget.Covered = budget.Covered.Add(cdp.Balance)
+		}
+	}
+	for _, commitment := range budget.Commitments {
+		budget.Committed = budget.Committed.Add(commitment.Amount)
+	}
+	budget.FullyCovered = !budget.Covered.Add(budget.Committed).LessThan(budget.Amount)
+	return budget
+}
+
+// checkBudgetCoverage verifica que el contrato tenga CDP vigentes que cubran su monto
+func (bc *Blockchain) checkBudgetCoverage(contract *Contract) error {
+	budget := bc.contractBudget(contract)
+	if !budget.FullyCovered {
+		return fmt.Errorf("el contrato requiere CDP vigentes que cubran su valor: cubierto %s de %s COP", budget.Covered.Add(budget.Committed), budget.Amount)
+	}
+	return nil
+}
+
+// cdpView retorna una copia del CDP con el estado de vencimiento actualizado
+func cdpView(cdp *CDP, now time.Time) *CDP {
+	view := *cdp
+	if view.Status == CDPActive && now.After(view.ExpiresAt) {
+		view.Status = CDPExpired
+	}
+	return &view
+}
+
+// endOfFiscalYear retorna el último instante de una vigencia fiscal
+func endOfFiscalYear(year int) time.Time {
+	return time.Date(year, time.December, 31, 23, 59, 59, 0, config.ColombianTimezone)
+}
+
+// apply actualiza el libro con un bloque presupuestal. Se usa tanto para bloques
+// nuevos como al reconstruir desde la cadena, por lo que no repite validaciones.
+func (l *budgetLedger) apply(block *Block) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	data := block.Data
+	switch block.Type {
+	case BlockTypeAppropriation:
+		appropriation := &Appropriation{
+			ID:          dataString(data, "appropriation_id"),
+			EntityCode:  dataString(data, "entity_code"),
+			Code:        dataString(data, "code"),
+			Description: dataString(data, "description"),
+			FiscalYear:  int(dataFloat(data, "fiscal_year")),
+			Amount:      dataDecimal(data, "amount"),
+			CreatedBy:   dataString(data, "created_by"),
+			CreatedAt:   dataTime(data, "timestamp", block.Timestamp),
+			BlockHash:   block.Hash,
+		}
+		appropriation.Available = appropriation.Amount
+		l.appropriations[appropriation.ID] = appropriation
+
+	case BlockTypeCDPIssued:
+		appropriation, exists := l.appropriations[dataString(data, "appropriation_id")]
+		if !exists {
+			return
+		}
+		issuedAt := dataTime(data, "timestamp", block.Timestamp)
+		cdp := &CDP{
+			ID:              dataString(data, "cdp_id"),
+			Number:          l.nextNumber("CDP", appropriation.EntityCode, appropriation.FiscalYear),
+			AppropriationID: appropriation.ID,
+			EntityCode:      appropriation.EntityCode,
+			ContractID:      dataString(data, "contract_id"),
+			Purpose:         dataString(data, "purpose"),
+			Amount:          dataDecimal(data, "amount"),
+			Status:          CDPActive,
+			IssuedBy:        dataString(data, "issued_by"),
+			IssuedAt:        issuedAt,
+			ExpiresAt:       dataTime(data, "expires_at", endOfFiscalYear(appropriation.FiscalYear)),
+			BlockHash:       block.Hash,
+		}
+		cdp.Balance = cdp.Amount
+		l.cdps[cdp.ID] = cdp
+		appropriation.Certified = appropriation.Certified.Add(cdp.Amount)
+		appropriation.Available = appropriation.Amount.Sub(appropriation.Certified)
+
+	case BlockTypeCDPReleased:
+		cdp, exists := l.cdps[dataString(data, "cdp_id")]
+		if !exists || cdp.Status != CDPActive {
+			return
+		}
+		if appropriation, exists := l.appropriations[cdp.AppropriationID]; exists {
+			appropriation.Certified = appropriation.Certified.Sub(cdp.Balance)
+			appropriation.Available = appropriation.Amount.Sub(appropriation.Certified)
+		}
+		cdp.Balance = money.Zero
+		cdp.Status = CDPReleased
+
+	case BlockTypeCommitment:
+		cdp, exists := l.cdps[dataString(data, "cdp_id")]
+		if !exists {
+			return
+		}
+		appropriation := l.appropriations[cdp.AppropriationID]
+		fiscalYear := cdp.IssuedAt.Year()
+		if appropriation != nil {
+			fiscalYear = appropriation.FiscalYear
+		}
+		commitment := &Commitment{
+			ID:           dataString(data, "commitment_id"),
+			Number:       l.nextNumber("RP", cdp.EntityCode, fiscalYear),
+			CDPID:        cdp.ID,
+			ContractID:   dataString(data, "contract_id"),
+			Beneficiary:  dataString(data, "beneficiary"),
+			Amount:       dataDecimal(data, "amount"),
+			RegisteredBy: dataString(data, "registered_by"),
+			RegisteredAt: dataTime(data, "timestamp", block.Timestamp),
+			BlockHash:    block.Hash,
+		}
+		l.commitments[commitment.ID] = commitment
+		cdp.Committed = cdp.Committed.Add(commitment.Amount)
+		cdp.Balance = cdp.Balance.Sub(commitment.Amount)
+		if appropriation != nil {
+			appropriation.Committed = appropriation.Committed.Add(commitment.Amount)
+		}
+	}
+}
+
+// isBudgetBlock indica si un tipo de bloque pertenece al libro presupuestal
+func isBudgetBlock(blockType string) bool {
+	switch blockType {
+	case BlockTypeAppropriation, BlockTypeCDPIssued, BlockTypeCDPReleased, BlockTypeCommitment:
+		return true
+	}
+	return false
+}
+
+// check verifica que un bloque presupuestal recibido de otro nodo respete los
+// saldos del libro, con las mismas reglas que se aplican al registrar localmente.
+// Los bloques de otros tipos no se verifican.
+func (l *budgetLedger) check(block *Block) error {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	data := block.Data
+	amount := dataDecimal(data, "amount")
+	timestamp := dataTime(data, "timestamp", block.Timestamp)
+
+	switch block.Type {
+	case BlockTypeAppropriation:
+		id := dataString(data, "appropriation_id")
+		if id == "" || l.appropriations[id] != nil {
+			return errors.New("rubro presupuestal sin identificador o repetido")
+		}
+		if !amount.IsPositive() {
+			return errors.New("monto debe ser mayor a cero")
+		}
+		entityCode, code, fiscalYear := dataString(data, "entity_code"), dataString(data, "code"), int(dataFloat(data, "fiscal_year"))
+		for _, existing := range l.appropriations {
+			if existing.EntityCode == entityCode && existing.Code == code && existing.FiscalYear == fiscalYear {
+				return fmt.Errorf("el rubro %s ya está apropiado para la vigencia %d", code, fiscalYear)
+			}
+		}
+
+	case BlockTypeCDPIssued:
+		id := dataString(data, "cdp_id")
+		if id == "" || l.cdps[id] != nil {
+			return errors.New("CDP sin identificador o repetido")
+		}
+		appropriation, exists := l.appropriations[dataString(data, "appropriation_id")]
+		if !exists {
+			return errors.New("rubro presupuestal no encontrado")
+		}
+		if !amount.IsPositive() {
+			return errors.New("monto debe ser mayor a cero")
+		}
+		if timestamp.After(endOfFiscalYear(appropriation.FiscalYear)) {
+			return fmt.Errorf("la vigencia %d del rubro %s ya terminó", appropriation.FiscalYear, appropriation.Code)
+		}
+		if amount.GreaterThan(appropriation.Available) {
+			return fmt.Errorf("saldo insuficiente en el rubro %s: disponible %s, solicitado %s", appropriation.Code, appropriation.Available, amount)
+		}
+
+	case BlockTypeCDPReleased:
+		cdp, exists := l.cdps[dataString(data, "cdp_id")]
+		if !exists {
+			return errors.New("CDP no encontrado")
+		}
+		if cdp.Status != CDPActive {
+			return fmt.Errorf("el CDP %s no está vigente (%s)", cdp.Number, cdp.Status)
+		}
+
+	case BlockTypeCommitment:
+		id := dataString(data, "commitment_id")
+		if id == "" || l.commitments[id] != nil {
+			return errors.New("RP sin identificador o repetido")
+		}
+		cdp, exists := l.cdps[dataString(data, "cdp_id")]
+		if !exists {
+			return errors.New("CDP no encontrado")
+		}
+		if view := cdpView(cdp, timestamp); view.Status != CDPActive {
+			return fmt.Errorf("el CDP %s no está vigente (%s)", cdp.Number, view.Status)
+		}
+		contractID := dataString(data, "contract_id")
+		if contractID == "" || (cdp.ContractID != "" && cdp.ContractID != contractID) {
+			return fmt.Errorf("el CDP %s está expedido para otro contrato", cdp.Number)
+		}
+		if !amount.IsPositive() {
+			return errors.New("monto debe ser mayor a cero")
+		}
+		if amount.GreaterThan(cdp.Balance) {
+			return fmt.Errorf("saldo insuficiente en el CDP %s: disponible %s, solicitado %s", cdp.Number, cdp.Balance, amount)
+		}
+	}
+	return nil
+}
+
+// checkBudgetChain reproduce los bloques presupuestales de una cadena en un
+// libro nuevo y retorna el primer bloque que no respete los saldos
+func checkBudgetChain(chain []Block) error {
+	ledger := newBudgetLedger()
+	for i := range chain {
+		if err := ledger.check(&chain[i]); err != nil {
+			return fmt.Errorf("bloque %d: %v", chain[i].Index, err)
+		}
+		ledger.apply(&chain[i])
+	}
+	return nil
+}
+
+// nextNumber genera el consecutivo de un documento, ej: CDP-MHCP-2025-0001
+func (l *budgetLedger) nextNumber(prefix string, entityCode string, fiscalYear int) string {
+	key := fmt.Sprintf("%s-%s-%d", prefix, entityCode, fiscalYear)
+	l.sequences[key]++
+	return fmt.Sprintf("%s-%04d", key, l.sequences[key])
+}
+
+// reset vacía el libro presupuestal
+func (l *budgetLedger) reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.appropriations = make(map[string]*Appropriation)
+	l.cdps = make(map[string]*CDP)
+	l.commitments = make(map[string]*Commitment)
+	l.sequences = make(map[string]int)
+}
+
+// rebuildBudgetLedger reconstruye el libro presupuestal desde la cadena
+func (bc *Blockchain) rebuildBudgetLedger() {
+	bc.budget.reset()
+	for _, block := range bc.Chain {
+		bc.budget.apply(block)
+	}
+}
+
+// dataString lee un texto de los datos de un bloque
+func dataString(data map[string]interface{}, key string) string {
+	value, _ := data[key].(string)
+	return value
+}
+
+// dataFloat lee un número de los datos de un bloque (float64 tras JSON, int en memoria)
+func dataFloat(data map[string]interface{}, key string) float64 {
+	switch value := data[key].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	}
+	return 0
+}
+
+// dataDecimal lee un monto de los datos de un bloque (texto exacto, número en bloques antiguos)
+func dataDecimal(data map[string]interface{}, key string) money.Decimal {
+	switch value := data[key].(type) {
+	case string:
+		amount, _ := money.Parse(value)
+		return amount
+	case money.Decimal:
+		return value
+	case float64:
+		amount, _ := money.Parse(strconv.FormatFloat(value, 'f', 2, 64))
+		return amount
+	}
+	return money.Zero
+}
+
+// dataTime lee una fecha de los datos de un bloque (time.Time en memoria, RFC3339 tras JSON)
+func dataTime(data map[string]interface{}, key string, fallback time.Time) time.Time {
+	switch value := data[key].(type) {
+	case time.Time:
+		return value
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return config.ToColombianTime(t)
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,563 @@
+package blockchain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"secop-blockchain/internal/config"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de bloque de las denuncias ciudadanas
+const (
+	BlockTypeComplaintFiled   = "COMPLAINT_FILED"
+	BlockTypeComplaintUpdated = "COMPLAINT_STATUS_CHANGED"
+)
+
+// AnonymousComplainant identifica en el audit trail a quien denuncia sin identificarse
+const AnonymousComplainant = "ANONIMO"
+
+// ComplaintCategory determina el órgano de control competente
+type ComplaintCategory string
+
+const (
+	ComplaintFiscal       ComplaintCategory = "FISCAL"       // Detrimento patrimonial: contraloría
+	ComplaintDisciplinary ComplaintCategory = "DISCIPLINARY" // Conducta de servidores públicos: procuraduría
+)
+
+// ComplaintStatus define el estado de trámite de una denuncia
+type ComplaintStatus string
+
+const (
+	ComplaintReceived    ComplaintStatus = "RECEIVED"
+	ComplaintUnderReview ComplaintStatus = "UNDER_REVIEW"
+	ComplaintAuditOpened ComplaintStatus = "AUDIT_OPENED" // Dio origen a un caso de auditoría
+	ComplaintResolved    ComplaintStatus = "RESOLVED"
+	ComplaintDismissed   ComplaintStatus = "DISMISSED"
+)
+
+// complaintTransitions son los cambios de estado permitidos
+var complaintTransitions = map[ComplaintStatus][]ComplaintStatus{
+	ComplaintReceived:    {ComplaintUnderReview, ComplaintDismissed},
+	ComplaintUnderReview: {ComplaintAuditOpened, ComplaintResolved, ComplaintDismissed},
+	ComplaintAuditOpened: {ComplaintResolved},
+}
+
+// ComplaintEvidence es un archivo aportado como prueba. En la cadena solo
+// queda su huella SHA-256; el archivo se conserva fuera de la cadena.
+type ComplaintEvidence struct {
+	Name      string `json:"name"`
+	MediaType string `json:"media_type,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+	SHA256    string `json:"sha256"`
+	URL       string `json:"url,omitempty"` // Ubicación externa cuando el archivo no se cargó al nodo
+}
+
+// ComplaintRoute es el órgano de control al que se remite la denuncia
+type ComplaintRoute struct {
+	Role      AdminRole `json:"role"`
+	Level     string    `json:"level"`
+	Region    string    `json:"region,omitempty"`
+	Authority string    `json:"authority"`
+}
+
+// ComplaintEvent es un cambio de estado de la denuncia
+type ComplaintEvent struct {
+	Status    ComplaintStatus `json:"status"`
+	Note      string          `json:"note,omitempty"`
+	By        string          `json:"by"`
+	Role      AdminRole       `json:"role"`
+	At        time.Time       `json:"at"`
+	BlockHash string          `json:"block_hash"`
+}
+
+// Complaint es una denuncia ciudadana o de veeduría sobre un contrato
+type Complaint struct {
+	ID          string              `json:"id"`
+	Number      string              `json:"number"`
+	ContractID  string              `json:"contract_id"`
+	EntityCode  string              `json:"entity_code"`
+	Category    ComplaintCategory   `json:"category"`
+	Subject     string              `json:"subject"`
+	Description string              `json:"description"`
+	Committee   string              `json:"oversight_committee,omitempty"` // Veeduría ciudadana que presenta la denuncia
+	Anonymous   bool                `json:"anonymous"`
+	Complainant string              `json:"complainant,omitempty"` // Seudónimo verificado; vacío si es anónima
+	Evidence    []ComplaintEvidence `json:"evidence"`
+	Route       ComplaintRoute      `json:"routed_to"`
+	Status      ComplaintStatus     `json:"status"`
+	AuditCaseID string              `json:"audit_case_id,omitempty"`
+	History     []ComplaintEvent    `json:"history"`
+	FiledAt     time.Time           `json:"filed_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	BlockHash   string              `json:"block_hash"`
+	receiptHash string
+}
+
+// ComplaintFilter selecciona denuncias
+type ComplaintFilter struct {
+	ContractID string
+	EntityCode string
+	Status     ComplaintStatus
+	Role       AdminRole
+}
+
+// complaintRegistry mantiene las denuncias derivadas de los bloques
+type complaintRegistry struct {
+	complaints map[string]*Complaint
+	receipts   map[string]string // Huella del radicado -> ID de la denuncia
+	sequences  map[string]int
+	mutex      sync.RWMutex
+	ops        sync.Mutex // Serializa validación y registro
+}
+
+func newComplaintRegistry() *complaintRegistry {
+	return &complaintRegistry{
+		complaints: make(map[string]*Complaint),
+		receipts:   make(map[string]string),
+		sequences:  make(map[string]int),
+	}
+}
+
+// FileComplaint radica una denuncia sobre un contrato y la remite al órgano de
+// control competente. Retorna el código de radicado con el que el denunciante
+// consulta el trámite; en la cadena solo queda su huella.
+func (wm *WorkflowManager) FileComplaint(contractID string, complaint *Complaint) (*Complaint, string, error) {
+	wm.blockchain.complaints.ops.Lock()
+	defer wm.blockchain.complaints.ops.Unlock()
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+
+	contract, exists := wm.blockchain.Contracts[contractID]
+	if !exists {
+		return nil, "", errors.New("contrato no encontrado")
+	}
+	if strings.TrimSpace(complaint.Subject) == "" {
+		return nil, "", errors.New("asunto de la denuncia requerido")
+	}
+	if strings.TrimSpace(complaint.Description) == "" {
+		return nil, "", errors.New("descripción de los hechos requerida")
+	}
+	complaint.Category = ComplaintCategory(strings.ToUpper(string(complaint.Category)))
+	switch complaint.Category {
+	case ComplaintFiscal, ComplaintDisciplinary:
+	case "":
+		complaint.Category = ComplaintFiscal
+	default:
+		return nil, "", fmt.Errorf("categoría inválida: %s (use %s o %s)", complaint.Category, ComplaintFiscal, ComplaintDisciplinary)
+	}
+	if complaint.Anonymous {
+		complaint.Complainant = ""
+	} else if complaint.Complainant == "" {
+		return nil, "", errors.New("las denuncias no anónimas requieren identidad ciudadana verificada")
+	}
+	for _, item := range complaint.Evidence {
+		if item.Name == "" || len(item.SHA256) != sha256.Size*2 {
+			return nil, "", fmt.Errorf("evidencia inválida: %q requiere nombre y huella SHA-256", item.Name)
+		}
+	}
+
+	receipt, err := newComplaintReceipt()
+	if err != nil {
+		return nil, "", err
+	}
+	complaintID := uuid.New().String()
+	route := complaintRoute(contract, complaint.Category)
+	now := config.GetColombianTime()
+
+	evidence := make([]interface{}, 0, len(complaint.Evidence))
+	for _, item := range complaint.Evidence {
+		evidence = append(evidence, map[string]interface{}{
+			"name":       item.Name,
+			"media_type": item.MediaType,
+			"size":       item.Size,
+			"sha256":     item.SHA256,
+			"url":        item.URL,
+		})
+	}
+
+	filedBy := complaint.Complainant
+	if complaint.Anonymous {
+		filedBy = AnonymousComplainant
+	}
+	wm.addAuditEntry(contract, BlockTypeComplaintFiled, filedBy, RoleCitizen,
+		fmt.Sprintf("Denuncia %s radicada y remitida a %s: %s", complaint.Category, route.Authority, complaint.Subject))
+
+	block, err := wm.blockchain.addBlockAt(map[string]interface{}{
+		"type":                BlockTypeComplaintFiled,
+		"complaint_id":        complaintID,
+		"contract_id":         contractID,
+		"entity_code":         contract.EntityCode,
+		"category":            string(complaint.Category),
+		"subject":             complaint.Subject,
+		"description":         complaint.Description,
+		"oversight_committee": complaint.Committee,
+		"anonymous":           complaint.Anonymous,
+		"complainant":         complaint.Complainant,
+		"evidence":            evidence,
+		"routed_role":         string(route.Role),
+		"routed_level":        route.Level,
+		"routed_region":       route.Region,
+		"routed_authority":    route.Authority,
+		"receipt_hash":        complaintReceiptHash(receipt),
+		"timestamp":           now,
+	}, now)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// La entrada toma la fecha del bloque, como en los nodos que reproducen la cadena
+	entry := &contract.AuditTrail[len(contract.AuditTrail)-1]
+	entry.BlockHash = block.Hash
+	entry.Timestamp = block.Timestamp
+	wm.blockchain.reindexContract(contract)
+	wm.notifyListeners(contract)
+	return wm.blockchain.GetComplaint(complaintID), receipt, nil
+}
+
+// UpdateComplaintStatus registra el trámite que da a la denuncia el órgano de
+// control al que fue remitida
+func (wm *WorkflowManager) UpdateComplaintStatus(complaintID string, auditorID string, role AdminRole, status ComplaintStatus, note string, auditCaseID string) error {
+	wm.blockchain.complaints.ops.Lock()
+	defer wm.blockchain.complaints.ops.Unlock()
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+
+	complaint := wm.blockchain.GetComplaint(complaintID)
+	if complaint == nil {
+		return errors.New("denuncia no encontrada")
+	}
+	contract, exists := wm.blockchain.Contracts[complaint.ContractID]
+	if !exists {
+		return errors.New("contrato no encontrado")
+	}
+	if role != complaint.Route.Role {
+		return fmt.Errorf("la denuncia %s fue remitida a %s, no es de competencia de %s", complaint.Number, complaint.Route.Authority, role)
+	}
+	if err := wm.checkAuditAuthority(contract, role); err != nil {
+		return err
+	}
+
+	status = ComplaintStatus(strings.ToUpper(string(status)))
+	allowed := false
+	for _, next := range complaintTransitions[complaint.Status] {
+		if next == status {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("la denuncia %s no puede pasar de %s a %s", complaint.Number, complaint.Status, status)
+	}
+	if (status == ComplaintResolved || status == ComplaintDismissed) && strings.TrimSpace(note) == "" {
+		return fmt.Errorf("el estado %s requiere una decisión motivada", status)
+	}
+	if status == ComplaintAuditOpened {
+		auditCase := wm.blockchain.GetAuditCase(auditCaseID)
+		if auditCase == nil || auditCase.ContractID != complaint.ContractID {
+			return errors.New("el estado AUDIT_OPENED requiere un caso de auditoría del mismo contrato")
+		}
+	} else {
+		auditCaseID = ""
+	}
+
+	now := config.GetColombianTime()
+	wm.addAuditEntry(contract, BlockTypeComplaintUpdated, auditorID, role,
+		fmt.Sprintf("Denuncia %s: %s", complaint.Number, status))
+
+	block, err := wm.blockchain.addBlockAt(map[string]interface{}{
+		"type":          BlockTypeComplaintUpdated,
+		"complaint_id":  complaintID,
+		"contract_id":   complaint.ContractID,
+		"status":        string(status),
+		"note":          note,
+		"audit_case_id": auditCaseID,
+		"updated_by":    auditorID,
+		"role":          string(role),
+		"timestamp":     now,
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	entry := &contract.AuditTrail[len(contract.AuditTrail)-1]
+	entry.BlockHash = block.Hash
+	entry.Timestamp = block.Timestamp
+	wm.blockchain.reindexContract(contract)
+	wm.notifyListeners(contract)
+	return nil
+}
+
+// ComplaintInbox lista las denuncias en trámite remitidas al rol que este nodo
+// de control puede atender según su jurisdicción
+func (wm *WorkflowManager) ComplaintInbox(role AdminRole) ([]*Complaint, error) {
+	if !isControlRole(role) {
+		return nil, fmt.Errorf("el rol %s no atiende denuncias", role)
+	}
+	if EntityType(strings.ToUpper(wm.auditAuthority.EntityType)) != EntityControl {
+		return nil, fmt.Errorf("el rol %s solo puede ejercerse desde un nodo de entidad de control (ENTITY_TYPE=%s)", role, EntityControl)
+	}
+
+	wm.blockchain.mutex.RLock()
+	defer wm.blockchain.mutex.RUnlock()
+
+	result := make([]*Complaint, 0)
+	for _, complaint := range wm.blockchain.GetComplaints(ComplaintFilter{Role: role}) {
+		if len(complaintTransitions[complaint.Status]) == 0 {
+			continue
+		}
+		contract, exists := wm.blockchain.Contracts[complaint.ContractID]
+		if !exists || checkJurisdiction(wm.auditAuthority, contract) != nil {
+			continue
+		}
+		result = append(result, complaint)
+	}
+	return result, nil
+}
+
+// complaintRoute determina el órgano de control competente según la
+// categoría de la denuncia y el nivel territorial de la entidad
+func complaintRoute(contract *Contract, category ComplaintCategory) ComplaintRoute {
+	role := RoleComptroller
+	if category == ComplaintDisciplinary {
+		role = RoleProsecutor
+	}
+
+	var parts []string
+	for _, part := range strings.Split(contract.EntityRegion, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	level := normalizeLevel(contract.EntityLevel)
+	if len(parts) == 0 || (level != LevelDepartamental && level != LevelMunicipal) {
+		level = LevelNacional
+	}
+
+	route := ComplaintRoute{Role: role, Level: level}
+	switch level {
+	case LevelDepartamental:
+		route.Region = parts[len(parts)-1]
+		route.Authority = map[AdminRole]string{
+			RoleComptroller: "Contraloría Departamental de ",
+			RoleProsecutor:  "Procuraduría Regional de ",
+		}[role] + route.Region
+	case LevelMunicipal:
+		route.Region = parts[0]
+		route.Authority = map[AdminRole]string{
+			RoleComptroller: "Contraloría Municipal de ",
+			RoleProsecutor:  "Procuraduría Provincial de ",
+		}[role] + route.Region
+	default:
+		route.Authority = map[AdminRole]string{
+			RoleComptroller: "Contraloría General de la República",
+			RoleProsecutor:  "Procuraduría General de la Nación",
+		}[role]
+	}
+	return route
+}
+
+// newComplaintReceipt genera un código de radicado aleatorio para el denunciante
+func newComplaintReceipt() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "RAD-" + base32.StdEncoding.EncodeToString(buf), nil
+}
+
+// complaintReceiptHash es la huella del radicado que se publica en la cadena
+func complaintReceiptHash(receipt string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(receipt))))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetComplaint obtiene una denuncia por ID
+func (bc *Blockchain) GetComplaint(id string) *Complaint {
+	bc.complaints.mutex.RLock()
+	defer bc.complaints.mutex.RUnlock()
+
+	complaint, exists := bc.complaints.complaints[id]
+	if !exists {
+		return nil
+	}
+	return complaintView(complaint)
+}
+
+// GetComplaintByReceipt obtiene la denuncia asociada a un código de radicado
+func (bc *Blockchain) GetComplaintByReceipt(receipt string) *Complaint {
+	bc.complaints.mutex.RLock()
+	defer bc.complaints.mutex.RUnlock()
+
+	complaint, exists := bc.complaints.complaints[bc.complaints.receipts[complaintReceiptHash(receipt)]]
+	if !exists {
+		return nil
+	}
+	return complaintView(complaint)
+}
+
+// GetComplaints lista las denuncias según el filtro, las más recientes primero
+func (bc *Blockchain) GetComplaints(filter ComplaintFilter) []*Complaint {
+	bc.complaints.mutex.RLock()
+	defer bc.complaints.mutex.RUnlock()
+
+	result := make([]*Complaint, 0)
+	for _, complaint := range bc.complaints.complaints {
+		if filter.ContractID != "" && complaint.ContractID != filter.ContractID {
+			continue
+		}
+		if filter.EntityCode != "" && complaint.EntityCode != filter.EntityCode {
+			continue
+		}
+		if filter.Status != "" && complaint.Status != filter.Status {
+			continue
+		}
+		if filter.Role != "" && complaint.Route.Role != filter.Role {
+			continue
+		}
+		result = append(result, complaintView(complaint))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].FiledAt.Equal(result[j].FiledAt) {
+			return result[i].FiledAt.After(result[j].FiledAt)
+		}
+		return result[i].Number < result[j].Number
+	})
+	return result
+}
+
+// complaintView retorna una copia de la denuncia para exponerla fuera del registro
+func complaintView(complaint *Complaint) *Complaint {
+	view := *complaint
+	view.Evidence = append([]ComplaintEvidence{}, complaint.Evidence...)
+	view.History = append([]ComplaintEvent{}, complaint.History...)
+	return &view
+}
+
+// apply actualiza el registro con un bloque de denuncia. Se usa tanto para
+// bloques nuevos como al reconstruir desde la cadena.
+func (r *complaintRegistry) apply(block *Block) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	data := block.Data
+	timestamp := dataTime(data, "timestamp", block.Timestamp)
+	switch block.Type {
+	case BlockTypeComplaintFiled:
+		entityCode := dataString(data, "entity_code")
+		key := fmt.Sprintf("DEN-%s-%d", entityCode, timestamp.Year())
+		r.sequences[key]++
+		anonymous, _ := data["anonymous"].(bool)
+		complainant := dataString(data, "complainant")
+		filedBy := complainant
+		if anonymous {
+			filedBy = AnonymousComplainant
+		}
+		complaint := &Complaint{
+			ID:          dataString(data, "complaint_id"),
+			Number:      fmt.Sprintf("%s-%04d", key, r.sequences[key]),
+			ContractID:  dataString(data, "contract_id"),
+			EntityCode:  entityCode,
+			Category:    ComplaintCategory(dataString(data, "category")),
+			Subject:     dataString(data, "subject"),
+			Description: dataString(data, "description"),
+			Committee:   dataString(data, "oversight_committee"),
+			Anonymous:   anonymous,
+			Complainant: complainant,
+			Evidence:    dataEvidence(data, "evidence"),
+			Route: ComplaintRoute{
+				Role:      AdminRole(dataString(data, "routed_role")),
+				Level:     dataString(data, "routed_level"),
+				Region:    dataString(data, "routed_region"),
+				Authority: dataString(data, "routed_authority"),
+			},
+			Status: ComplaintReceived,
+			History: []ComplaintEvent{{
+				Status:    ComplaintReceived,
+				By:        filedBy,
+				Role:      RoleCitizen,
+				At:        timestamp,
+				BlockHash: block.Hash,
+			}},
+			FiledAt:     timestamp,
+			UpdatedAt:   timestamp,
+			BlockHash:   block.Hash,
+			receiptHash: dataString(data, "receipt_hash"),
+		}
+		r.complaints[complaint.ID] = complaint
+		if complaint.receiptHash != "" {
+			r.receipts[complaint.receiptHash] = complaint.ID
+		}
+
+	case BlockTypeComplaintUpdated:
+		complaint, exists := r.complaints[dataString(data, "complaint_id")]
+		if !exists {
+			return
+		}
+		status := ComplaintStatus(dataString(data, "status"))
+		complaint.Status = status
+		complaint.UpdatedAt = timestamp
+		if caseID := dataString(data, "audit_case_id"); caseID != "" {
+			complaint.AuditCaseID = caseID
+		}
+		complaint.History = append(complaint.History, ComplaintEvent{
+			Status:    status,
+			Note:      dataString(data, "note"),
+			By:        dataString(data, "updated_by"),
+			Role:      AdminRole(dataString(data, "role")),
+			At:        timestamp,
+			BlockHash: block.Hash,
+		})
+	}
+}
+
+func (r *complaintRegistry) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.complaints = make(map[string]*Complaint)
+	r.receipts = make(map[string]string)
+	r.sequences = make(map[string]int)
+}
+
+// rebuildComplaintRegistry reconstruye las denuncias desde la cadena
+func (bc *Blockchain) rebuildComplaintRegistry() {
+	bc.complaints.reset()
+	for _, block := range bc.Chain {
+		bc.complaints.apply(block)
+	}
+}
+
+// dataEvidence lee la lista de evidencias de un bloque, ya sea recién creado o
+// decodificado desde JSON
+func dataEvidence(data map[string]interface{}, key string) []ComplaintEvidence {
+	items, _ := data[key].([]interface{})
+	result := make([]ComplaintEvidence, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var size int64
+		switch value := fields["size"].(type) {
+		case int64:
+			size = value
+		case float64:
+			size = int64(value)
+		}
+		result = append(result, ComplaintEvidence{
+			Name:      dataString(fields, "name"),
+			MediaType: dataString(fields, "media_type"),
+			Size:      size,
+			SHA256:    dataString(fields, "sha256"),
+			URL:       dataString(fields, "url"),
+		})
+	}
+	return result
+}
@@ -0,0 +1,528 @@
+package blockchain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"secop-blockchain/internal/money"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Parámetros de paginación por defecto
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// Campos de ordenamiento soportados para contratos
+const (
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+	SortByAmount    = "amount"
+)
+
+// ContractQuery define los filtros, el orden y la paginación de una consulta de contratos
+type ContractQuery struct {
+	EntityCode   string
+	ContractType string
+	Status       ContractStatus
+	CreatedBy    string
+	MinAmount    *money.Decimal // En pesos
+	MaxAmount    *money.Decimal // En pesos
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+	SortBy       string
+	Descending   bool
+	Cursor       string
+	Limit        int
+}
+
+// ContractPage representa una página de resultados de contratos
+type ContractPage struct {
+	Contracts  []*Contract `json:"contracts"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// contractCursor es el contenido codificado de un cursor de paginación
+type contractCursor struct {
+	SortBy string `json:"s"`
+	Key    string `json:"k"`
+	ID     string `json:"id"`
+}
+
+// indexEntry guarda los valores indexados de un contrato
+type indexEntry struct {
+	id           string
+	entityCode   string
+	contractType string
+	status       ContractStatus
+	createdBy    string
+	createdAt    time.Time
+	updatedAt    time.Time
+	amount       money.Decimal // Valor en pesos
+}
+
+// contractIndex mantiene índices secundarios sobre los contratos
+type contractIndex struct {
+	entries   map[string]*indexEntry
+	byEntity  map[string]map[string]struct{}
+	byType    map[string]map[string]struct{}
+	byStatus  map[ContractStatus]map[string]struct{}
+	byCreator map[string]map[string]struct{}
+	byCreated []*indexEntry // ordenado por (createdAt, id)
+	byAmount  []*indexEntry // ordenado por (amount, id)
+	valueOf   func(contract *Contract) money.Decimal
+	mutex     sync.RWMutex
+}
+
+// newContractIndex crea un índice vacío. valueOf convierte el monto del
+// contrato a pesos para que los filtros y el orden por monto sean comparables.
+func newContractIndex(valueOf func(contract *Contract) money.Decimal) *contractIndex {
+	return &contractIndex{
+		valueOf:   valueOf,
+		entries:   make(map[string]*indexEntry),
+		byEntity:  make(map[string]map[string]struct{}),
+		byType:    make(map[string]map[string]struct{}),
+		byStatus:  make(map[ContractStatus]map[string]struct{}),
+		byCreator: make(map[string]map[string]struct{}),
+	}
+}
+
+// put indexa (o reindexa) un contrato
+func (idx *contractIndex) put(contract *Contract) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if old, exists := idx.entries[contract.ID]; exists {
+		idx.remove(old)
+	}
+
+	entry := &indexEntry{
+		id:           contract.ID,
+		entityCode:   contract.EntityCode,
+		contractType: contract.ContractType,
+		status:       contract.Status,
+		createdBy:    contract.CreatedBy,
+		createdAt:    contract.CreatedAt,
+		updatedAt:    contract.UpdatedAt,
+		amount:       idx.valueOf(contract),
+	}
+	idx.entries[entry.id] = entry
+
+	addToSet(idx.byEntity, entry.entityCode, entry.id)
+	addToSet(idx.byType, entry.contractType, entry.id)
+	addToSet(idx.byCreator, entry.createdBy, entry.id)
+	if idx.byStatus[entry.status] == nil {
+		idx.byStatus[entry.status] = make(map[string]struct{})
+	}
+	idx.byStatus[entry.status][entry.id] = struct{}{}
+
+	idx.byCreated = insertSorted(idx.byCreated, entry, lessByCreated)
+	idx.byAmount = insertSorted(idx.byAmount, entry, lessByAmount)
+}
+
+// remove elimina una entrada de todos los índices (requiere el lock tomado)
+func (idx *contractIndex) remove(entry *indexEntry) {
+	delete(idx.entries, entry.id)
+	removeFromSet(idx.byEntity, entry.entityCode, entry.id)
+	removeFromSet(idx.byType, entry.contractType, entry.id)
+	removeFromSet(idx.byCreator, entry.createdBy, entry.id)
+	if set, ok := idx.byStatus[entry.status]; ok {
+		delete(set, entry.id)
+		if len(set) == 0 {
+			delete(idx.byStatus, entry.status)
+		}
+	}
+	idx.byCreated = removeSorted(idx.byCreated, entry, lessByCreated)
+	idx.byAmount = removeSorted(idx.byAmount, entry, lessByAmount)
+}
+
+// reset reconstruye el índice a partir de un mapa de contratos
+func (idx *contractIndex) reset(contracts map[string]*Contract) {
+	idx.mutex.Lock()
+	idx.entries = make(map[string]*indexEntry)
+	idx.byEntity = make(map[string]map[string]struct{})
+	idx.byType = make(map[string]map[string]struct{})
+	idx.byStatus = make(map[ContractStatus]map[string]struct{})
+	idx.byCreator = make(map[string]map[string]struct{})
+	idx.byCreated = nil
+	idx.byAmount = nil
+	idx.mutex.Unlock()
+
+	for _, contract := range contracts {
+		idx.put(contract)
+	}
+}
+
+// query retorna los IDs que cumplen la consulta, ordenados y paginados
+func (idx *contractIndex) query(q ContractQuery) ([]string, int, string, error) {
+	sortBy := q.SortBy
+	if sortBy == "" {
+		sortBy = SortByCreatedAt
+	}
+	if sortBy != SortByCreatedAt && sortBy != SortByUpdatedAt && sortBy != SortByAmount {
+		return nil, 0, "", errors.New("campo de ordenamiento inválido")
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	var cursor *contractCursor
+	if q.Cursor != "" {
+		decoded, err := decodeContractCursor(q.Cursor)
+		if err != nil || decoded.SortBy != sortBy {
+			return nil, 0, "", errors.New("cursor inválido")
+		}
+		cursor = decoded
+	}
+
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	matches := make([]*indexEntry, 0)
+	for _, entry := range idx.candidates(q) {
+		if idx.matches(entry, q) {
+			matches = append(matches, entry)
+		}
+	}
+
+	less := lessFor(sortBy)
+	sort.Slice(matches, func(i, j int) bool {
+		if q.Descending {
+			return less(matches[j], matches[i])
+		}
+		return less(matches[i], matches[j])
+	})
+
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(matches), func(i int) bool {
+			cmp := compareToCursor(matches[i], sortBy, cursor)
+			if q.Descending {
+				return cmp < 0
+			}
+			return cmp > 0
+		})
+	}
+
+	end := start + limit
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	ids := make([]string, 0, end-start)
+	for _, entry := range matches[start:end] {
+		ids = append(ids, entry.id)
+	}
+
+	nextCursor := ""
+	if end < len(matches) && end > start {
+		nextCursor = encodeContractCursor(matches[end-1], sortBy)
+	}
+
+	return ids, len(matches), nextCursor, nil
+}
+
+// candidates escoge el índice más selectivo para la consulta
+func (idx *contractIndex) candidates(q ContractQuery) []*indexEntry {
+	var best map[string]struct{}
+	consider := func(set map[string]struct{}) {
+		if best == nil || len(set) < len(best) {
+			best = set
+		}
+	}
+
+	if q.EntityCode != "" {
+		consider(nonNilSet(idx.byEntity[q.EntityCode]))
+	}
+	if q.ContractType != "" {
+		consider(nonNilSet(idx.byType[q.ContractType]))
+	}
+	if q.Status != "" {
+		consider(nonNilSet(idx.byStatus[q.Status]))
+	}
+	if q.CreatedBy != "" {
+		consider(nonNilSet(idx.byCreator[q.CreatedBy]))
+	}
+
+	// Los rangos de fecha y monto se resuelven con búsqueda binaria
+	var ranged []*indexEntry
+	hasRange := false
+	if !q.CreatedFrom.IsZero() || !q.CreatedTo.IsZero() {
+		ranged = idx.createdRange(q.CreatedFrom, q.CreatedTo)
+		hasRange = true
+	}
+	if q.MinAmount != nil || q.MaxAmount != nil {
+		amountRange := idx.amountRange(q.MinAmount, q.MaxAmount)
+		if !hasRange || len(amountRange) < len(ranged) {
+			ranged = amountRange
+		}
+		hasRange = true
+	}
+
+	if hasRange && (best == nil || len(ranged) <= len(best)) {
+		return ranged
+	}
+	if best == nil {
+		return idx.byCreated
+	}
+
+	result := make([]*indexEntry, 0, len(best))
+	for id := range best {
+		result = append(result, idx.entries[id])
+	}
+	return result
+}
+
+// createdRange retorna las entradas creadas dentro del rango [from, to]
+func (idx *contractIndex) createdRange(from, to time.Time) []*indexEntry {
+	start := 0
+	if !from.IsZero() {
+		start = sort.Search(len(idx.byCreated), func(i int) bool {
+			return !idx.byCreated[i].createdAt.Before(from)
+		})
+	}
+	end := len(idx.byCreated)
+	if !to.IsZero() {
+		end = sort.Search(len(idx.byCreated), func(i int) bool {
+			return idx.byCreated[i].createdAt.After(to)
+		})
+	}
+	if start >= end {
+		return nil
+	}
+	return idx.byCreated[start:end]
+}
+
+// amountRange retorna las entradas con monto dentro del rango [min, max]
+func (idx *contractIndex) amountRange(min, max *money.Decimal) []*indexEntry {
+	start := 0
+	if min != nil {
+		start = sort.Search(len(idx.byAmount), func(i int) bool {
+			return !idx.byAmount[i].amount.LessThan(*min)
+		})
+	}
+	end := len(idx.byAmount)
+	if max != nil {
+		end = sort.Search(len(idx.byAmount), func(i int) bool {
+			return idx.byAmount[i].amount.GreaterThan(*max)
+		})
+	}
+	if start >= end {
+		return nil
+	}
+	return idx.byAmount[start:end]
+}
+
+// matches verifica todas las condiciones de la consulta sobre una entrada
+func (idx *contractIndex) matches(entry *indexEntry, q ContractQuery) bool {
+	if q.EntityCode != "" && entry.entityCode != q.EntityCode {
+		return false
+	}
+	if q.ContractType != "" && entry.contractType != q.ContractType {
+		return false
+	}
+	if q.Status != "" && entry.status != q.Status {
+		return false
+	}
+	if q.CreatedBy != "" && entry.createdBy != q.CreatedBy {
+		return false
+	}
+	if q.MinAmount != nil && entry.amount.LessThan(*q.MinAmount) {
+		return false
+	}
+	if q.MaxAmount != nil && entry.amount.GreaterThan(*q.MaxAmount) {
+		return false
+	}
+	if !q.CreatedFrom.IsZero() && entry.createdAt.Before(q.CreatedFrom) {
+		return false
+	}
+	if !q.CreatedTo.IsZero() && entry.createdAt.After(q.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// orderedIDs retorna todos los IDs ordenados por fecha de creación
+func (idx *contractIndex) orderedIDs() []string {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	ids := make([]string, 0, len(idx.byCreated))
+	for _, entry := range idx.byCreated {
+		ids = append(ids, entry.id)
+	}
+	return ids
+}
+
+// statusIDs retorna los IDs de un estado ordenados por fecha de creación
+func (idx *contractIndex) statusIDs(status ContractStatus) []string {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	set := idx.byStatus[status]
+	entries := make([]*indexEntry, 0, len(set))
+	for id := range set {
+		entries = append(entries, idx.entries[id])
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return lessByCreated(entries[i], entries[j])
+	})
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.id)
+	}
+	return ids
+}
+
+func lessByCreated(a, b *indexEntry) bool {
+	if !a.createdAt.Equal(b.createdAt) {
+		return a.createdAt.Before(b.createdAt)
+	}
+	return a.id < b.id
+}
+
+func lessByUpdated(a, b *indexEntry) bool {
+	if !a.updatedAt.Equal(b.updatedAt) {
+		return a.updatedAt.Before(b.updatedAt)
+	}
+	return a.id < b.id
+}
+
+func lessByAmount(a, b *indexEntry) bool {
+	if cmp := a.amount.Cmp(b.amount); cmp != 0 {
+		return cmp < 0
+	}
+	return a.id < b.id
+}
+
+func lessFor(sortBy string) func(a, b *indexEntry) bool {
+	switch sortBy {
+	case SortByUpdatedAt:
+		return lessByUpdated
+	case SortByAmount:
+		return lessByAmount
+	default:
+		return lessByCreated
+	}
+}
+
+// sortKey retorna el valor de ordenamiento de una entrada como texto
+func sortKey(entry *indexEntry, sortBy string) string {
+	switch sortBy {
+	case SortByUpdatedAt:
+		return strconv.FormatInt(entry.updatedAt.UnixNano(), 10)
+	case SortByAmount:
+		return strconv.FormatInt(entry.amount.Cents(), 10)
+	default:
+		return strconv.FormatInt(entry.createdAt.UnixNano(), 10)
+	}
+}
+
+// compareToCursor compara una entrada con la posición del cursor (-1, 0, 1)
+func compareToCursor(entry *indexEntry, sortBy string, cursor *contractCursor) int {
+	var cmp int
+	switch sortBy {
+	case SortByAmount:
+		key, _ := strconv.ParseInt(cursor.Key, 10, 64)
+		cmp = compareInt(entry.amount.Cents(), key)
+	default:
+		key, _ := strconv.ParseInt(cursor.Key, 10, 64)
+		value := entry.createdAt.UnixNano()
+		if sortBy == SortByUpdatedAt {
+			value = entry.updatedAt.UnixNano()
+		}
+		cmp = compareInt(value, key)
+	}
+	if cmp != 0 {
+		return cmp
+	}
+	switch {
+	case entry.id < cursor.ID:
+		return -1
+	case entry.id > cursor.ID:
+		return 1
+	}
+	return 0
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func encodeContractCursor(entry *indexEntry, sortBy string) string {
+	data, _ := json.Marshal(contractCursor{SortBy: sortBy, Key: sortKey(entry, sortBy), ID: entry.id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeContractCursor(value string) (*contractCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor contractCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func addToSet(sets map[string]map[string]struct{}, key, id string) {
+	if sets[key] == nil {
+		sets[key] = make(map[string]struct{})
+	}
+	sets[key][id] = struct{}{}
+}
+
+func removeFromSet(sets map[string]map[string]struct{}, key, id string) {
+	if set, ok := sets[key]; ok {
+		delete(set, id)
+		if len(set) == 0 {
+			delete(sets, key)
+		}
+	}
+}
+
+func nonNilSet(set map[string]struct{}) map[string]struct{} {
+	if set == nil {
+		return map[string]struct{}{}
+	}
+	return set
+}
+
+func insertSorted(list []*indexEntry, entry *indexEntry, less func(a, b *indexEntry) bool) []*indexEntry {
+	pos := sort.Search(len(list), func(i int) bool { return !less(list[i], entry) })
+	list = append(list, nil)
+	copy(list[pos+1:], list[pos:])
+	list[pos] = entry
+	return list
+}
+
+func removeSorted(list []*indexEntry, entry *indexEntry, less func(a, b *indexEntry) bool) []*indexEntry {
+	pos := sort.Search(len(list), func(i int) bool { return !less(list[i], entry) })
+	for i := pos; i < len(list); i++ {
+		if list[i].id == entry.id {
+			return append(list[:i], list[i+1:]...)
+		}
+		if less(entry, list[i]) {
+			break
+		}
+	}
+	return list
+}
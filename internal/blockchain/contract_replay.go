@@ -0,0 +1,341 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// contractBlockData reúne los campos de los bloques que modifican un contrato.
+// Se decodifica con JSON porque en los bloques recibidos de otros nodos los
+// números llegan como float64 y las fechas como texto.
+type contractBlockData struct {
+	ContractID        string         `json:"contract_id"`
+	EntityCode        string         `json:"entity_code"`
+	EntityName        string         `json:"entity_name"`
+	EntityRegion      string         `json:"entity_region"`
+	EntityLevel       string         `json:"entity_level"`
+	ContractType      string         `json:"contract_type"`
+	Description       string         `json:"description"`
+	Amount            money.Decimal  `json:"amount"`
+	Currency          money.Currency `json:"currency"`
+	Modality          string         `json:"modality"`
+	WorkflowTemplate  string         `json:"workflow_template"`
+	RequiredDocuments []string       `json:"required_documents"`
+	Steps             []AdminRole    `json:"steps"`
+	CreatedBy         string         `json:"created_by"`
+	Timestamp         time.Time      `json:"timestamp"`
+
+	// Validación de pasos (o de nodo, en bloques sin paso)
+	Step          int       `json:"step"`
+	Validator     string    `json:"validator"`
+	ValidatorName string    `json:"validator_name"`
+	Role          AdminRole `json:"role"`
+	Approved      bool      `json:"approved"`
+	Comments      string    `json:"comments"`
+
+	// Documentos y observaciones
+	Documents   []string `json:"documents"`
+	UserID      string   `json:"user_id"`
+	Auditor     string   `json:"auditor"`
+	Observation string   `json:"observation"`
+
+	// Escalamiento
+	EscalatedTo AdminRole `json:"escalated_to"`
+	DueDate     time.Time `json:"due_date"`
+
+	// Adjudicación
+	SupplierNIT       string `json:"supplier_nit"`
+	SupplierName      string `json:"supplier_name"`
+	ProposalsReceived int    `json:"proposals_received"`
+	AwardedBy         string `json:"awarded_by"`
+
+	// Casos de auditoría
+	CaseID          string    `json:"case_id"`
+	FindingID       string    `json:"finding_id"`
+	Subject         string    `json:"subject"`
+	OpenedBy        string    `json:"opened_by"`
+	Severity        string    `json:"severity"`
+	RecordedBy      string    `json:"recorded_by"`
+	ResponseDueDate time.Time `json:"response_due_date"`
+	RespondedBy     string    `json:"responded_by"`
+	Late            bool      `json:"late"`
+	Outcome         string    `json:"outcome"`
+	Conclusion      string    `json:"conclusion"`
+	ClosedBy        string    `json:"closed_by"`
+
+	// Denuncias ciudadanas
+	ComplaintID     string `json:"complaint_id"`
+	Category        string `json:"category"`
+	Anonymous       bool   `json:"anonymous"`
+	Complainant     string `json:"complainant"`
+	RoutedAuthority string `json:"routed_authority"`
+	Status          string `json:"status"`
+	UpdatedBy       string `json:"updated_by"`
+}
+
+// applyContractBlock actualiza el contrato al que se refiere un bloque recibido
+// de otro nodo o leído al reconstruir la cadena. Las operaciones registradas en
+// este nodo ya actualizan el contrato antes de agregar su bloque.
+func (bc *Blockchain) applyContractBlock(block *Block) {
+	switch block.Type {
+	case "CONTRACT_CREATION", "VALIDATION", "DOCUMENTS_ATTACHED", "AUDIT_OBSERVATION", "ESCALATION", BlockTypeContractAwarded,
+		BlockTypeAuditCaseOpened, BlockTypeAuditFinding, BlockTypeAuditResponse, BlockTypeAuditCaseClosed,
+		BlockTypeComplaintFiled, BlockTypeComplaintUpdated:
+	default:
+		return
+	}
+
+	var data contractBlockData
+	raw, err := json.Marshal(block.Data)
+	if err == nil {
+		err = json.Unmarshal(raw, &data)
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Bloque %d no se pudo aplicar a su contrato: %v\n", block.Index, err)
+		return
+	}
+	if data.ContractID == "" {
+		return
+	}
+	at := block.Timestamp
+	if !data.Timestamp.IsZero() {
+		at = config.ToColombianTime(data.Timestamp)
+	}
+
+	if block.Type == "CONTRACT_CREATION" {
+		bc.replayContractCreation(block, data, at)
+		return
+	}
+
+	contract, exists := bc.Contracts[data.ContractID]
+	if !exists {
+		return
+	}
+	wm := bc.WorkflowManager
+
+	switch block.Type {
+	case "VALIDATION":
+		// Los bloques sin paso son validaciones de nodo: solo el rechazo cambia el estado
+		if data.Step == 0 {
+			if !data.Approved {
+				contract.Status = StatusRejected
+			}
+			break
+		}
+		if data.Step != contract.CurrentStep || data.Step > len(contract.ValidationSteps) {
+			fmt.Printf("⚠️ Bloque %d valida el paso %d del contrato %s, que está en el paso %d\n", block.Index, data.Step, contract.ID, contract.CurrentStep)
+			return
+		}
+		step := &contract.ValidationSteps[data.Step-1]
+		step.ValidatorID = data.Validator
+		step.ValidatorName = data.ValidatorName
+		step.Timestamp = at
+		step.Comments = data.Comments
+		if data.Approved {
+			step.Status = ValidationApproved
+			contract.CurrentStep++
+			contract.Status = wm.getStatusForStep(contract)
+			wm.startStep(contract, contract.CurrentStep, at)
+			replayAuditEntry(contract, block, at, "STEP_APPROVED", data.Validator, data.Role, fmt.Sprintf("Paso %d aprobado: %s", data.Step, data.Comments))
+		} else {
+			step.Status = ValidationRejected
+			contract.Status = StatusRejected
+			replayAuditEntry(contract, block, at, "STEP_REJECTED", data.Validator, data.Role, fmt.Sprintf("Paso %d rechazado: %s", data.Step, data.Comments))
+		}
+
+	case "DOCUMENTS_ATTACHED":
+		var added []string
+		for _, document := range data.Documents {
+			if document != "" && !containsString(contract.Documents, document) && !containsString(added, document) {
+				added = append(added, document)
+			}
+		}
+		contract.Documents = append(contract.Documents, added...)
+		replayAuditEntry(contract, block, at, "DOCUMENTS_ATTACHED", data.UserID, data.Role, fmt.Sprintf("Documentos aportados: %v", added))
+
+	case BlockTypeContractAwarded:
+		// El bloque guarda el NIT sin dígito de verificación
+		nit := data.SupplierNIT
+		if supplier := bc.GetSupplier(nit); supplier != nil {
+			nit = supplier.FormattedNIT()
+		}
+		contract.SupplierNIT = nit
+		contract.SupplierName = data.SupplierName
+		contract.ProposalsReceived = data.ProposalsReceived
+		contract.AwardedAt = at
+		contract.Status = StatusAwarded
+		replayAuditEntry(contract, block, at, "CONTRACT_AWARDED", data.AwardedBy, data.Role, fmt.Sprintf("Adjudicado a %s (%s) entre %d propuestas: %s", data.SupplierName, nit, data.ProposalsReceived, data.Comments))
+
+	case "AUDIT_OBSERVATION":
+		// Las observaciones no modifican el contrato, solo su registro de auditoría
+		replayAuditEntry(contract, block, at, "AUDIT_OBSERVATION", data.Auditor, data.Role, data.Observation)
+		return
+
+	case BlockTypeComplaintFiled:
+		// Las denuncias tampoco modifican el contrato, solo su registro de auditoría
+		filedBy := data.Complainant
+		if data.Anonymous {
+			filedBy = AnonymousComplainant
+		}
+		replayAuditEntry(contract, block, at, BlockTypeComplaintFiled, filedBy, RoleCitizen,
+			fmt.Sprintf("Denuncia %s radicada y remitida a %s: %s", data.Category, data.RoutedAuthority, data.Subject))
+		return
+
+	case BlockTypeComplaintUpdated:
+		complaint := bc.GetComplaint(data.ComplaintID)
+		if complaint == nil {
+			return
+		}
+		replayAuditEntry(contract, block, at, BlockTypeComplaintUpdated, data.UpdatedBy, data.Role, fmt.Sprintf("Denuncia %s: %s", complaint.Number, data.Status))
+		return
+
+	case "ESCALATION":
+		if data.Step < 1 || data.Step > len(contract.ValidationSteps) {
+			return
+		}
+		step := &contract.ValidationSteps[data.Step-1]
+		step.Escalated = true
+		step.EscalatedAt = at
+		replayAuditEntry(contract, block, at, "STEP_ESCALATED", "SYSTEM", data.EscalatedTo,
+			fmt.Sprintf("Paso %d (%s) vencido el %s, escalado a %s", data.Step, data.Role, config.ToColombianTime(data.DueDate).Format("2006-01-02"), data.EscalatedTo))
+
+	case BlockTypeAuditCaseOpened:
+		replayAuditEntry(contract, block, at, "AUDIT_CASE_OPENED", data.OpenedBy, data.Role, fmt.Sprintf("Caso de auditoría abierto: %s", data.Subject))
+		contract.AuditStatus = bc.auditStatusFor(contract.ID)
+
+	case BlockTypeAuditFinding:
+		// El registro de casos ya aplicó el bloque y conoce el número del caso
+		auditCase := bc.GetAuditCase(data.CaseID)
+		if auditCase == nil {
+			return
+		}
+		replayAuditEntry(contract, block, at, "AUDIT_FINDING_ADDED", data.RecordedBy, data.Role,
+			fmt.Sprintf("Hallazgo %s en el caso %s: %s (respuesta hasta %s)", data.Severity, auditCase.Number, data.Description, config.ToColombianTime(data.ResponseDueDate).Format("2006-01-02")))
+		contract.AuditStatus = bc.auditStatusFor(contract.ID)
+
+	case BlockTypeAuditResponse:
+		auditCase := bc.GetAuditCase(data.CaseID)
+		if auditCase == nil {
+			return
+		}
+		number := 0
+		for _, finding := range auditCase.Findings {
+			if finding.ID == data.FindingID {
+				number = finding.Number
+			}
+		}
+		if number == 0 {
+			return
+		}
+		description := fmt.Sprintf("Respuesta al hallazgo %d del caso %s", number, auditCase.Number)
+		if data.Late {
+			description += " (extemporánea)"
+		}
+		replayAuditEntry(contract, block, at, "AUDIT_RESPONSE_SUBMITTED", data.RespondedBy, data.Role, description)
+		contract.AuditStatus = bc.auditStatusFor(contract.ID)
+
+	case BlockTypeAuditCaseClosed:
+		auditCase := bc.GetAuditCase(data.CaseID)
+		if auditCase == nil {
+			return
+		}
+		replayAuditEntry(contract, block, at, "AUDIT_CASE_CLOSED", data.ClosedBy, data.Role, fmt.Sprintf("Caso %s cerrado (%s): %s", auditCase.Number, data.Outcome, data.Conclusion))
+		contract.AuditStatus = bc.auditStatusFor(contract.ID)
+	}
+
+	contract.UpdatedAt = at
+	bc.reindexContract(contract)
+}
+
+// replayContractCreation crea un contrato a partir de su bloque CONTRACT_CREATION.
+// Los bloques anteriores a que se registraran los pasos usan el flujo que
+// corresponde a la plantilla en este nodo.
+func (bc *Blockchain) replayContractCreation(block *Block, data contractBlockData, at time.Time) {
+	if _, exists := bc.Contracts[data.ContractID]; exists {
+		return
+	}
+
+	contract := &Contract{
+		ID:                data.ContractID,
+		EntityCode:        data.EntityCode,
+		EntityName:        data.EntityName,
+		EntityRegion:      data.EntityRegion,
+		EntityLevel:       data.EntityLevel,
+		ContractType:      data.ContractType,
+		Description:       data.Description,
+		Amount:            data.Amount,
+		Currency:          data.Currency,
+		Modality:          data.Modality,
+		WorkflowTemplate:  data.WorkflowTemplate,
+		RequiredDocuments: data.RequiredDocuments,
+		Documents:         data.Documents,
+		Status:            StatusDraft,
+		CreatedBy:         data.CreatedBy,
+		CreatedAt:         at,
+		UpdatedAt:         at,
+		CurrentStep:       1,
+	}
+
+	wm := bc.WorkflowManager
+	roles := data.Steps
+	if len(roles) == 0 {
+		for _, step := range wm.withHigherAuthority(wm.templateSteps(contract.WorkflowTemplate), bc.contractValue(contract)) {
+			roles = append(roles, step.Role)
+		}
+	}
+	contract.ValidationSteps = make([]ValidationStep, len(roles))
+	for i, role := range roles {
+		contract.ValidationSteps[i] = ValidationStep{
+			StepNumber: i + 1,
+			Role:       role,
+			Status:     ValidationPending,
+			Required:   true,
+		}
+	}
+	wm.startStep(contract, 1, at)
+	replayAuditEntry(contract, block, at, "WORKFLOW_INITIALIZED", data.CreatedBy, RoleProjectDeveloper, "Flujo de trabajo inicializado")
+
+	bc.Contracts[contract.ID] = contract
+	bc.reindexContract(contract)
+}
+
+// replayAuditEntry agrega al registro de auditoría la entrada de un bloque reproducido
+func replayAuditEntry(contract *Contract, block *Block, at time.Time, action string, userID string, role AdminRole, description string) {
+	contract.AuditTrail = append(contract.AuditTrail, AuditEntry{
+		ID:          uuid.New().String(),
+		Action:      action,
+		UserID:      userID,
+		UserRole:    role,
+		Timestamp:   at,
+		Description: description,
+		BlockHash:   block.Hash,
+	})
+}
+
+// stepRoles retorna los roles de los pasos de validación de un contrato en orden
+func stepRoles(contract *Contract) []string {
+	roles := make([]string, len(contract.ValidationSteps))
+	for i, step := range contract.ValidationSteps {
+		roles[i] = string(step.Role)
+	}
+	return roles
+}
+
+// rebuildContracts reconstruye los contratos reproduciendo los bloques de la cadena
+func (bc *Blockchain) rebuildContracts() {
+	bc.Contracts = make(map[string]*Contract)
+	bc.rebuildContractIndex()
+	for _, block := range bc.Chain {
+		bc.applyContractBlock(block)
+	}
+	for _, contract := range bc.Contracts {
+		contract.AuditStatus = bc.auditStatusFor(contract.ID)
+	}
+	bc.rebuildContractIndex()
+	fmt.Printf("🔄 Contratos reconstruidos: %d\n", len(bc.Contracts))
+}
@@ -0,0 +1,128 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"secop-blockchain/internal/money"
+	"testing"
+)
+
+// replicate copies the chain to a new node the way peers receive it: as JSON
+func replicate(t *testing.T, bc *Blockchain) *Blockchain {
+	t.Helper()
+	raw, err := json.Marshal(bc.GetChain())
+	if err != nil {
+		t.Fatalf("marshal chain: %v", err)
+	}
+	var chain []*Block
+	if err := json.Unmarshal(raw, &chain); err != nil {
+		t.Fatalf("unmarshal chain: %v", err)
+	}
+	peer := NewBlockchain()
+	if err := peer.ReplaceChain(chain); err != nil {
+		t.Fatalf("ReplaceChain: %v", err)
+	}
+	return peer
+}
+
+// assertSameTrail compares the audit trail of a contract on two nodes. Entry
+// IDs are generated on each node and are not compared.
+func assertSameTrail(t *testing.T, origin, replica *Blockchain, contractID string) {
+	t.Helper()
+	want, err := origin.GetContract(contractID)
+	if err != nil {
+		t.Fatalf("origin: %v", err)
+	}
+	got, err := replica.GetContract(contractID)
+	if err != nil {
+		t.Fatalf("replica: %v", err)
+	}
+	if len(got.AuditTrail) != len(want.AuditTrail) {
+		t.Fatalf("replayed trail has %d entries, want %d", len(got.AuditTrail), len(want.AuditTrail))
+	}
+	for i, w := range want.AuditTrail {
+		g := got.AuditTrail[i]
+		if g.Action != w.Action || g.UserID != w.UserID || g.UserRole != w.UserRole || g.Description != w.Description || g.BlockHash != w.BlockHash {
+			t.Errorf("entry %d:\n got %s %s %s %q %s\nwant %s %s %s %q %s", i,
+				g.Action, g.UserID, g.UserRole, g.Description, g.BlockHash,
+				w.Action, w.UserID, w.UserRole, w.Description, w.BlockHash)
+		}
+	}
+	if got.AuditStatus != want.AuditStatus {
+		t.Errorf("audit status %s, want %s", got.AuditStatus, want.AuditStatus)
+	}
+}
+
+func newAuditedContract(t *testing.T) (*Blockchain, string) {
+	t.Helper()
+	bc := NewBlockchain()
+	bc.WorkflowManager.ConfigureAuditAuthority(AuditAuthority{EntityType: string(EntityControl), Level: LevelNacional})
+	err := bc.AddContract(&Contract{
+		ID:           "C1",
+		EntityCode:   "E1",
+		EntityName:   "Alcaldía",
+		Description:  "Obra",
+		Amount:       money.New(1000000),
+		Currency:     money.COP,
+		CreatedBy:    "u1",
+		ContractType: "OBRA",
+	})
+	if err != nil {
+		t.Fatalf("AddContract: %v", err)
+	}
+	return bc, "C1"
+}
+
+func TestReplayAuditCaseTrail(t *testing.T) {
+	bc, contractID := newAuditedContract(t)
+	wm := bc.WorkflowManager
+
+	auditCase, err := wm.OpenAuditCase(contractID, "aud1", RoleComptroller, "Sobrecostos")
+	if err != nil {
+		t.Fatalf("OpenAuditCase: %v", err)
+	}
+	finding, err := wm.AddAuditFinding(auditCase.ID, "aud1", RoleComptroller, &AuditFinding{Description: "Precios por encima del mercado", Severity: FindingHigh}, 5)
+	if err != nil {
+		t.Fatalf("AddAuditFinding: %v", err)
+	}
+	if err := wm.RespondAuditFinding(auditCase.ID, finding.ID, "u1", RoleProjectDeveloper, "Estudio de mercado adjunto"); err != nil {
+		t.Fatalf("RespondAuditFinding: %v", err)
+	}
+	if err := wm.CloseAuditCase(auditCase.ID, "aud1", RoleComptroller, AuditOutcomeConfirmed, "Plan de mejoramiento"); err != nil {
+		t.Fatalf("CloseAuditCase: %v", err)
+	}
+
+	peer := replicate(t, bc)
+	assertSameTrail(t, bc, peer, contractID)
+
+	contract, _ := peer.GetContract(contractID)
+	if last := contract.AuditTrail[len(contract.AuditTrail)-1]; last.Action != "AUDIT_CASE_CLOSED" {
+		t.Errorf("last replayed entry is %s, want AUDIT_CASE_CLOSED", last.Action)
+	}
+}
+
+func TestReplayComplaintTrail(t *testing.T) {
+	bc, contractID := newAuditedContract(t)
+	wm := bc.WorkflowManager
+
+	auditCase, err := wm.OpenAuditCase(contractID, "aud1", RoleComptroller, "Sobrecostos")
+	if err != nil {
+		t.Fatalf("OpenAuditCase: %v", err)
+	}
+	complaint, _, err := wm.FileComplaint(contractID, &Complaint{Subject: "Sobreprecio", Description: "Precios por encima del mercado", Category: ComplaintFiscal, Complainant: "c1"})
+	if err != nil {
+		t.Fatalf("FileComplaint: %v", err)
+	}
+	if _, _, err := wm.FileComplaint(contractID, &Complaint{Subject: "Obra abandonada", Description: "Sin avance", Anonymous: true}); err != nil {
+		t.Fatalf("FileComplaint anonymous: %v", err)
+	}
+	role := complaint.Route.Role
+	if err := wm.UpdateComplaintStatus(complaint.ID, "aud1", role, ComplaintUnderReview, "", ""); err != nil {
+		t.Fatalf("UpdateComplaintStatus: %v", err)
+	}
+	if err := wm.UpdateComplaintStatus(complaint.ID, "aud1", role, ComplaintAuditOpened, "", auditCase.ID); err != nil {
+		t.Fatalf("UpdateComplaintStatus: %v", err)
+	}
+
+	peer := replicate(t, bc)
+	assertSameTrail(t, bc, peer, contractID)
+}
@@ -0,0 +1,199 @@
+package blockchain
+
+import (
+	"fmt"
+	"secop-blockchain/internal/config"
+	"sort"
+	"time"
+)
+
+// DefaultStepBusinessDays es el plazo de un paso sin configuración específica
+const DefaultStepBusinessDays = 5
+
+// BusinessCalendar calcula fechas en días hábiles. La implementación por
+// defecto es el calendario de festivos colombianos (paquete calendar).
+type BusinessCalendar interface {
+	IsBusinessDay(t time.Time) bool
+	AddBusinessDays(from time.Time, days int) time.Time
+	BusinessDaysBetween(from, to time.Time) int
+}
+
+// OverdueStep representa un paso de validación con el plazo vencido
+type OverdueStep struct {
+	ContractID  string    `json:"contract_id"`
+	EntityCode  string    `json:"entity_code"`
+	EntityName  string    `json:"entity_name"`
+	StepNumber  int       `json:"step_number"`
+	Role        AdminRole `json:"role"`
+	StartedAt   time.Time `json:"started_at"`
+	DueDate     time.Time `json:"due_date"`
+	DaysOverdue int       `json:"business_days_overdue"`
+	Escalated   bool      `json:"escalated"`
+}
+
+// OverdueReport agrupa los pasos vencidos por entidad y rol
+type OverdueReport struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Total       int            `json:"total"`
+	ByEntity    map[string]int `json:"by_entity"`
+	ByRole      map[string]int `json:"by_role"`
+	Steps       []OverdueStep  `json:"steps"`
+}
+
+// ConfigureDeadlines establece el calendario y los plazos en días hábiles por rol
+func (wm *WorkflowManager) ConfigureDeadlines(calendar BusinessCalendar, stepDays map[AdminRole]int, defaultDays int) {
+	if calendar != nil {
+		wm.calendar = calendar
+	}
+	wm.stepDays = stepDays
+	wm.defaultStepDays = defaultDays
+}
+
+// startStep marca el inicio de un paso en la fecha dada y calcula su fecha límite
+func (wm *WorkflowManager) startStep(contract *Contract, stepNumber int, at time.Time) {
+	if stepNumber < 1 || stepNumber > len(contract.ValidationSteps) {
+		return
+	}
+	step := &contract.ValidationSteps[stepNumber-1]
+	step.StartedAt = at
+	step.DueDate = wm.dueDate(at, step.Role)
+}
+
+// dueDate calcula el vencimiento al final del último día hábil del plazo
+func (wm *WorkflowManager) dueDate(start time.Time, role AdminRole) time.Time {
+	days := wm.defaultStepDays
+	if configured, ok := wm.stepDays[role]; ok {
+		days = configured
+	}
+	if days <= 0 {
+		days = DefaultStepBusinessDays
+	}
+
+	due := wm.businessCalendar().AddBusinessDays(start, days)
+	return time.Date(due.Year(), due.Month(), due.Day(), 23, 59, 59, 0, config.ColombianTimezone)
+}
+
+// businessCalendar retorna el calendario configurado o el calendario colombiano.
+// Se crea junto con el gestor para que las tareas programadas no lo inicialicen
+// en paralelo con los handlers.
+func (wm *WorkflowManager) businessCalendar() BusinessCalendar {
+	return wm.calendar
+}
+
+// currentPendingStep retorna el paso actual si está pendiente
+func currentPendingStep(contract *Contract) *ValidationStep {
+	if contract.Status == StatusRejected || contract.CurrentStep < 1 || contract.CurrentStep > len(contract.ValidationSteps) {
+		return nil
+	}
+	step := &contract.ValidationSteps[contract.CurrentStep-1]
+	if step.Status != ValidationPending || step.DueDate.IsZero() {
+		return nil
+	}
+	return step
+}
+
+// GetOverdueReport retorna los pasos vencidos, filtrando opcionalmente por entidad y rol
+func (wm *WorkflowManager) GetOverdueReport(entityCode string, role AdminRole) *OverdueReport {
+	now := config.GetColombianTime()
+	report := &OverdueReport{
+		GeneratedAt: now,
+		ByEntity:    make(map[string]int),
+		ByRole:      make(map[string]int),
+		Steps:       make([]OverdueStep, 0),
+	}
+
+	for _, contract := range wm.blockchain.GetAllContracts() {
+		step := currentPendingStep(contract)
+		if step == nil || !now.After(step.DueDate) {
+			continue
+		}
+		if entityCode != "" && contract.EntityCode != entityCode {
+			continue
+		}
+		if role != "" && step.Role != role {
+			continue
+		}
+
+		report.Steps = append(report.Steps, OverdueStep{
+			ContractID:  contract.ID,
+			EntityCode:  contract.EntityCode,
+			EntityName:  contract.EntityName,
+			StepNumber:  step.StepNumber,
+			Role:        step.Role,
+			StartedAt:   step.StartedAt,
+			DueDate:     step.DueDate,
+			DaysOverdue: wm.businessCalendar().BusinessDaysBetween(step.DueDate, now),
+			Escalated:   step.Escalated,
+		})
+		report.ByEntity[contract.EntityCode]++
+		report.ByRole[string(step.Role)]++
+	}
+
+	sort.Slice(report.Steps, func(i, j int) bool {
+		return report.Steps[i].DueDate.Before(report.Steps[j].DueDate)
+	})
+	report.Total = len(report.Steps)
+	return report
+}
+
+// CheckDeadlines escala los pasos vencidos que aún no han sido escalados,
+// registrando un bloque ESCALATION por cada uno. Retorna la cantidad escalada.
+func (wm *WorkflowManager) CheckDeadlines() (int, error) {
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+
+	now := config.GetColombianTime()
+	escalated := 0
+
+	for _, id := range wm.blockchain.index.orderedIDs() {
+		contract, exists := wm.blockchain.Contracts[id]
+		if !exists {
+			continue
+		}
+		step := currentPendingStep(contract)
+		if step == nil || step.Escalated || !now.After(step.DueDate) {
+			continue
+		}
+
+		escalateTo := escalationRole(step.Role)
+		daysOverdue := wm.businessCalendar().BusinessDaysBetween(step.DueDate, now)
+		step.Escalated = true
+		step.EscalatedAt = now
+		contract.UpdatedAt = now
+
+		wm.addAuditEntry(contract, "STEP_ESCALATED", "SYSTEM", escalateTo,
+			fmt.Sprintf("Paso %d (%s) vencido el %s, escalado a %s", step.StepNumber, step.Role, step.DueDate.Format("2006-01-02"), escalateTo))
+
+		blockData := map[string]interface{}{
+			"type":         "ESCALATION",
+			"contract_id":  contract.ID,
+			"step":         step.StepNumber,
+			"role":         string(step.Role),
+			"escalated_to": string(escalateTo),
+			"due_date":     step.DueDate,
+			"days_overdue": daysOverdue,
+			"timestamp":    now,
+		}
+
+		block, err := wm.blockchain.addBlock(blockData)
+		if err != nil {
+			return escalated, err
+		}
+		contract.AuditTrail[len(contract.AuditTrail)-1].BlockHash = block.Hash
+
+		wm.notifyListeners(contract)
+		escalated++
+	}
+
+	return escalated, nil
+}
+
+// escalationRole retorna el rol superior al que se escala un paso vencido
+func escalationRole(role AdminRole) AdminRole {
+	switch role {
+	case RoleAdminChief, RoleContractingCommittee, RoleBudgetAuthority:
+		return RoleBudgetAuthority
+	default:
+		return RoleAdminChief
+	}
+}
@@ -0,0 +1,46 @@
+package blockchain
+
+import (
+	"time"
+)
+
+// HistoryEvent representa un evento del historial de un contrato con su bloque
+type HistoryEvent struct {
+	ID          string    `json:"id"`
+	Action      string    `json:"action"`
+	UserID      string    `json:"user_id"`
+	UserRole    AdminRole `json:"user_role"`
+	Timestamp   time.Time `json:"timestamp"`
+	Description string    `json:"description"`
+	BlockIndex  int       `json:"block_index"`
+	BlockHash   string    `json:"block_hash"`
+}
+
+// GetContractHistory retorna el registro de auditoría de un contrato
+// enlazado con el índice de bloque de cada evento
+func (bc *Blockchain) GetContractHistory(contractID string) ([]HistoryEvent, error) {
+	contract, err := bc.GetContract(contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]HistoryEvent, 0, len(contract.AuditTrail))
+	for _, entry := range contract.AuditTrail {
+		event := HistoryEvent{
+			ID:          entry.ID,
+			Action:      entry.Action,
+			UserID:      entry.UserID,
+			UserRole:    entry.UserRole,
+			Timestamp:   entry.Timestamp,
+			Description: entry.Description,
+			BlockIndex:  -1,
+			BlockHash:   entry.BlockHash,
+		}
+		if block := bc.GetBlockByHash(entry.BlockHash); block != nil {
+			event.BlockIndex = block.Index
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
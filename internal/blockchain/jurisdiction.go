@@ -0,0 +1,133 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Niveles territoriales de entidades y órganos de control
+const (
+	LevelNacional      = "NACIONAL"
+	LevelDepartamental = "DEPARTAMENTAL"
+	LevelMunicipal     = "MUNICIPAL"
+)
+
+// Canales por los que se reciben observaciones de auditoría
+const (
+	ObservationChannelControl = "CONTROL"
+	ObservationChannelCitizen = "CITIZEN_VERIFIED"
+)
+
+// AuditAuthority describe la entidad del nodo para verificar facultades de control
+type AuditAuthority struct {
+	EntityType string // ENTITY_TYPE del nodo; solo CONTROL puede ejercer roles de auditoría
+	EntityCode string
+	Region     string // Municipio y/o departamento, ej: "Medellín, Antioquia"
+	Level      string // Nacional, Departamental o Municipal
+}
+
+// ConfigureAuditAuthority establece la entidad del nodo que ejerce control
+func (wm *WorkflowManager) ConfigureAuditAuthority(authority AuditAuthority) {
+	wm.auditAuthority = authority
+}
+
+// GetAuditAuthority retorna la entidad de control configurada en el nodo
+func (wm *WorkflowManager) GetAuditAuthority() AuditAuthority {
+	return wm.auditAuthority
+}
+
+// checkAuditAuthority verifica que el nodo sea una entidad de control y que el
+// contrato esté dentro de su jurisdicción
+func (wm *WorkflowManager) checkAuditAuthority(contract *Contract, role AdminRole) error {
+	if !isControlRole(role) {
+		return fmt.Errorf("el rol %s no tiene facultades de auditoría", role)
+	}
+	authority := wm.auditAuthority
+	if EntityType(strings.ToUpper(authority.EntityType)) != EntityControl {
+		return fmt.Errorf("el rol %s solo puede ejercerse desde un nodo de entidad de control (ENTITY_TYPE=%s)", role, EntityControl)
+	}
+	return checkJurisdiction(authority, contract)
+}
+
+// checkJurisdiction aplica las reglas de competencia territorial: el control
+// nacional audita todo, el departamental a entidades de su departamento y el
+// municipal solo a entidades de su municipio
+func checkJurisdiction(authority AuditAuthority, contract *Contract) error {
+	level := normalizeLevel(authority.Level)
+	if level == "" {
+		return errors.New("la entidad de control no tiene nivel territorial configurado (ENTITY_LEVEL)")
+	}
+	if level == LevelNacional {
+		return nil
+	}
+
+	contractLevel := normalizeLevel(contract.EntityLevel)
+	contractRegion := regionParts(contract.EntityRegion)
+	authorityRegion := regionParts(authority.Region)
+	if len(authorityRegion) == 0 {
+		return errors.New("la entidad de control no tiene región configurada (ENTITY_REGION)")
+	}
+	if contractLevel == "" || len(contractRegion) == 0 {
+		return fmt.Errorf("el contrato no registra nivel y región de la entidad; solo el control nacional puede auditarlo")
+	}
+
+	outside := fmt.Errorf("el contrato de %s (%s, %s) está fuera de la jurisdicción de un control %s de %s",
+		contract.EntityCode, contract.EntityLevel, contract.EntityRegion, strings.ToLower(level), authority.Region)
+
+	switch level {
+	case LevelDepartamental:
+		if contractLevel == LevelNacional {
+			return outside
+		}
+		department := authorityRegion[len(authorityRegion)-1]
+		for _, part := range contractRegion {
+			if part == department {
+				return nil
+			}
+		}
+		return outside
+	case LevelMunicipal:
+		if contractLevel != LevelMunicipal || contractRegion[0] != authorityRegion[0] {
+			return outside
+		}
+		// Hay municipios homónimos en distintos departamentos
+		if len(contractRegion) > 1 && len(authorityRegion) > 1 && contractRegion[len(contractRegion)-1] != authorityRegion[len(authorityRegion)-1] {
+			return outside
+		}
+		return nil
+	}
+	return fmt.Errorf("nivel territorial desconocido: %s", authority.Level)
+}
+
+// normalizeLevel unifica las variantes de nivel territorial ("Nacional", "distrital", ...)
+func normalizeLevel(level string) string {
+	switch normalizeName(level) {
+	case "nacional", "national":
+		return LevelNacional
+	case "departamental", "department", "departmental":
+		return LevelDepartamental
+	case "municipal", "distrital", "municipality", "district":
+		return LevelMunicipal
+	case "":
+		return ""
+	}
+	return strings.ToUpper(strings.TrimSpace(level))
+}
+
+// regionParts separa una región "Municipio, Departamento" en componentes normalizados
+func regionParts(region string) []string {
+	var parts []string
+	for _, part := range strings.Split(region, ",") {
+		if normalized := normalizeName(part); normalized != "" {
+			parts = append(parts, normalized)
+		}
+	}
+	return parts
+}
+
+// normalizeName compara nombres sin mayúsculas ni tildes
+func normalizeName(name string) string {
+	replacer := strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+	return replacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,133 @@
+package blockchain
+
+import (
+	"fmt"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/modality"
+	"secop-blockchain/internal/money"
+)
+
+// EntityLimits restringe los contratos que puede tramitar el nodo según la
+// configuración de la entidad. Los valores están en pesos.
+type EntityLimits struct {
+	MaxContractValue         money.Decimal // Valor máximo de contrato (0 = sin límite)
+	HigherAuthorityThreshold money.Decimal // Valor desde el cual se agrega el paso del comité de contratación (0 = nunca)
+	BudgetAuthority          bool          // Si la entidad puede autorizar como ordenador del gasto
+	AnnualBudget             money.Decimal // Presupuesto anual, determina la menor cuantía
+}
+
+// ConfigureEntityLimits establece los límites de la entidad del nodo
+func (bc *Blockchain) ConfigureEntityLimits(limits EntityLimits) {
+	bc.limits = limits
+}
+
+// GetEntityLimits retorna los límites configurados
+func (bc *Blockchain) GetEntityLimits() EntityLimits {
+	return bc.limits
+}
+
+// ConfigureConverter establece las tasas de cambio y la tabla de SMMLV
+func (bc *Blockchain) ConfigureConverter(converter *money.Converter) {
+	bc.converter = converter
+}
+
+// GetConverter retorna el conversor de montos del nodo
+func (bc *Blockchain) GetConverter() *money.Converter {
+	return bc.converter
+}
+
+// ConfigureModalityRules establece las reglas de modalidad de selección
+func (bc *Blockchain) ConfigureModalityRules(engine *modality.Engine) {
+	bc.modality = engine
+}
+
+// GetModalityRules retorna las reglas de modalidad en uso
+func (bc *Blockchain) GetModalityRules() *modality.Engine {
+	return bc.modality
+}
+
+// EvaluateModality determina la modalidad, documentos y flujo que exige un contrato
+func (bc *Blockchain) EvaluateModality(contractType string, amount money.Decimal, currency money.Currency) (*modality.Decision, error) {
+	year := config.GetColombianTime().Year()
+	value, err := bc.converter.ToSMMLV(amount, currency, year)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := bc.converter.ToSMMLV(bc.limits.AnnualBudget, money.COP, year)
+	if err != nil {
+		return nil, err
+	}
+	return bc.modality.Evaluate(modality.Input{
+		ContractType:      contractType,
+		ValueSMMLV:        value,
+		EntityBudgetSMMLV: budget,
+	})
+}
+
+// applyModality asigna al contrato la modalidad exigida por las reglas. Si el
+// contrato declara otra modalidad se rechaza.
+func (bc *Blockchain) applyModality(contract *Contract) error {
+	decision, err := bc.EvaluateModality(contract.ContractType, contract.Amount, contract.Currency)
+	if err != nil {
+		return err
+	}
+	if contract.Modality != "" && contract.Modality != decision.Modality {
+		return fmt.Errorf("la modalidad %s no corresponde al contrato: se requiere %s (%s)", contract.Modality, decision.Modality, decision.Rule)
+	}
+	contract.Modality = decision.Modality
+	contract.WorkflowTemplate = decision.Workflow
+	contract.RequiredDocuments = decision.RequiredDocuments
+	return nil
+}
+
+// missingDocuments retorna los documentos requeridos que el contrato no ha aportado
+func missingDocuments(contract *Contract) []string {
+	provided := make(map[string]bool, len(contract.Documents))
+	for _, document := range contract.Documents {
+		provided[document] = true
+	}
+	missing := make([]string, 0)
+	for _, document := range contract.RequiredDocuments {
+		if !provided[document] {
+			missing = append(missing, document)
+		}
+	}
+	return missing
+}
+
+// ValueInCOP convierte un monto a pesos
+func (bc *Blockchain) ValueInCOP(amount money.Decimal, currency money.Currency) (money.Decimal, error) {
+	return bc.converter.ToCOP(amount, currency)
+}
+
+// ValueInSMMLV expresa un monto en salarios mínimos del año de la fecha actual
+func (bc *Blockchain) ValueInSMMLV(amount money.Decimal, currency money.Currency) (money.Decimal, error) {
+	return bc.converter.ToSMMLV(amount, currency, config.GetColombianTime().Year())
+}
+
+// contractValue retorna el valor del contrato en pesos. Si la moneda no tiene
+// tasa configurada se usa el monto sin convertir.
+func (bc *Blockchain) contractValue(contract *Contract) money.Decimal {
+	value, err := bc.ValueInCOP(contract.Amount, contract.Currency)
+	if err != nil {
+		return contract.Amount
+	}
+	return value
+}
+
+// checkContractValue verifica que el valor del contrato no supere el máximo de la entidad
+func (bc *Blockchain) checkContractValue(contract *Contract) error {
+	value, err := bc.ValueInCOP(contract.Amount, contract.Currency)
+	if err != nil {
+		return err
+	}
+	if bc.limits.MaxContractValue.IsPositive() && value.GreaterThan(bc.limits.MaxContractValue) {
+		return fmt.Errorf("valor %s COP supera el valor máximo de contrato de la entidad (%s COP)", value, bc.limits.MaxContractValue)
+	}
+	return nil
+}
+
+// requiresHigherAuthority indica si un valor en pesos exige aprobación del comité de contratación
+func (bc *Blockchain) requiresHigherAuthority(value money.Decimal) bool {
+	return bc.limits.HigherAuthorityThreshold.IsPositive() && value.GreaterThan(bc.limits.HigherAuthorityThreshold)
+}
@@ -1,12 +1,14 @@
 package blockchain
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 	"secop-blockchain/internal/config"
 )
@@ -18,6 +20,8 @@ type Peer struct {
 	Port     string `json:"port"`
 	LastSeen time.Time `json:"last_seen"`
 	Active   bool   `json:"active"`
+	Reputation *PeerScore `json:"reputation,omitempty"`
+	Outbox     *OutboxStatus `json:"outbox,omitempty"`
 }
 
 // P2PNetwork maneja la comunicación entre nodos
@@ -29,20 +33,44 @@ type P2PNetwork struct {
 	Blockchain    *Blockchain
 	PeerDiscovery *PeerDiscovery
 	mutex         sync.RWMutex
+	syncing       sync.Mutex  // Serializa las sincronizaciones de la cadena
+	reputation    *reputation
+	broadcast     *broadcaster // Colas de envío de bloques por peer
+	ready         atomic.Bool // Set once the initial sync finishes
+	stop          chan struct{}
 }
 
+// peerHealthTimeout bounds each peer health check
+const peerHealthTimeout = 5 * time.Second
+
+// NodeIDHeader identifica al nodo que envía un bloque
+const NodeIDHeader = "X-Node-ID"
+
+// errPeerViolation marca los errores causados por un peer que violó el protocolo
+var errPeerViolation = errors.New("violación del protocolo")
+
+// p2pClient bounds requests to peers so an unresponsive node cannot stall a sync
+var p2pClient = &http.Client{Timeout: 10 * time.Second}
+
 // NewP2PNetwork crea una nueva instancia de red P2P
-func NewP2PNetwork(nodeID, address, port string, blockchain *Blockchain, discoveryRegistryURL, entityType string) *P2PNetwork {
+func NewP2PNetwork(nodeID, address, port string, blockchain *Blockchain, discoveryRegistryURLs []string, entityType string) *P2PNetwork {
 	network := &P2PNetwork{
 		NodeID:     nodeID,
 		Address:    address,
 		Port:       port,
 		Peers:      make(map[string]*Peer),
 		Blockchain: blockchain,
+		reputation: newReputation(),
+		broadcast:  newBroadcaster(),
 	}
 	
 	// Initialize peer discovery
-	network.PeerDiscovery = NewPeerDiscovery(discoveryRegistryURL, nodeID, address, entityType)
+	network.PeerDiscovery = NewPeerDiscovery(discoveryRegistryURLs, nodeID, address, port, entityType)
+	
+	// Every block created on this node is sent to the peers, whatever operation created it
+	blockchain.AddLocalBlockListener(func(block *Block) {
+		network.BroadcastBlock(*block)
+	})
 	
 	return network
 }
@@ -54,31 +82,64 @@ func (p2p *P2PNetwork) Start() error {
 		return fmt.Errorf("failed to start peer discovery: %v", err)
 	}
 	
-	// Start periodic peer synchronization
-	go p2p.syncPeersLoop()
+	// Periodic peer list refresh, health checks and sync run in the scheduler
+	p2p.stop = make(chan struct{})
+	p2p.RefreshPeers()
 	
-	fmt.Printf("P2P network started for node %s", p2p.NodeID)
+	fmt.Printf("🌐 Red P2P iniciada para el nodo %s\n", p2p.NodeID)
 	return nil
 }
 
 // Stop stops the P2P network
 func (p2p *P2PNetwork) Stop() {
+	if p2p.stop != nil {
+		close(p2p.stop)
+		p2p.stop = nil
+	}
+	p2p.broadcast.cancel()
+	p2p.broadcast.wg.Wait()
 	p2p.PeerDiscovery.Stop()
-	fmt.Printf("P2P network stopped for node %s", p2p.NodeID)
+	fmt.Printf("🛑 Red P2P detenida para el nodo %s\n", p2p.NodeID)
 }
 
-// syncPeersLoop periodically synchronizes with discovered peers
-func (p2p *P2PNetwork) syncPeersLoop() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-	
-	for range ticker.C {
-		p2p.syncWithDiscoveredPeers()
+// InitialSync catches up with the network before the node reports ready. It
+// retries until a peer answers or the timeout passes; a node without peers,
+// such as the first of a network, is ready at once.
+func (p2p *P2PNetwork) InitialSync(timeout time.Duration) {
+	defer p2p.ready.Store(true)
+
+	stop := p2p.stop
+	deadline := time.Now().Add(timeout)
+	for {
+		p2p.RefreshPeers()
+		reached, total := p2p.syncChains()
+		switch {
+		case total == 0:
+			fmt.Printf("🔄 Sin peers para la sincronización inicial, iniciando con la cadena local\n")
+			return
+		case reached > 0:
+			fmt.Printf("✅ Sincronización inicial completada con %d de %d peers (%d bloques)\n", reached, total, p2p.Blockchain.GetBlockchainHeight())
+			return
+		case time.Now().After(deadline):
+			fmt.Printf("⚠️ Ningún peer respondió en %s, iniciando con la cadena local\n", timeout)
+			return
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(2 * time.Second):
+		}
 	}
 }
 
-// syncWithDiscoveredPeers synchronizes the peer list with discovered peers
-func (p2p *P2PNetwork) syncWithDiscoveredPeers() {
+// IsReady reports whether the initial sync has finished
+func (p2p *P2PNetwork) IsReady() bool {
+	return p2p.ready.Load()
+}
+
+// RefreshPeers synchronizes the peer list with discovered peers
+func (p2p *P2PNetwork) RefreshPeers() {
 	discoveredPeers := p2p.PeerDiscovery.GetActivePeers()
 	
 	p2p.mutex.Lock()
@@ -114,8 +175,8 @@ func (p2p *P2PNetwork) syncWithDiscoveredPeers() {
 }
 
 // AddBootstrapPeer adds a bootstrap peer for initial network formation
-func (p2p *P2PNetwork) AddBootstrapPeer(id, address, entityType string) {
-	p2p.PeerDiscovery.AddBootstrapPeer(id, address, entityType)
+func (p2p *P2PNetwork) AddBootstrapPeer(id, address, port string) {
+	p2p.PeerDiscovery.AddBootstrapPeer(id, address, port)
 	
 	p2p.mutex.Lock()
 	defer p2p.mutex.Unlock()
@@ -124,7 +185,7 @@ func (p2p *P2PNetwork) AddBootstrapPeer(id, address, entityType string) {
 	p2p.Peers[id] = &Peer{
 		ID:       id,
 		Address:  address,
-		Port:     "", // Will be extracted from address if needed
+		Port:     port,
 		LastSeen: config.GetColombianTime(),
 		Active:   true,
 	}
@@ -139,6 +200,9 @@ func (p2p *P2PNetwork) AddPeer(peerID, address, port string) error {
 		return fmt.Errorf("peer %s already exists", peerID)
 	}
 	
+	// Keep it across discovery refreshes
+	p2p.PeerDiscovery.AddManualPeer(peerID, address, port)
+	
 	// Use the provided peerID (which should be the actual NODE_ID)
 	p2p.Peers[peerID] = &Peer{
 		ID:       peerID,
@@ -152,124 +216,162 @@ func (p2p *P2PNetwork) AddPeer(peerID, address, port string) error {
 	return nil
 }
 
-// BroadcastBlock envía un nuevo bloque a todos los peers
-func (p2p *P2PNetwork) BroadcastBlock(block Block) {
-	p2p.mutex.RLock()
-	defer p2p.mutex.RUnlock()
-	
-	fmt.Printf("📡 Broadcasting bloque %s a %d peers\n", block.Hash, len(p2p.Peers))
-	
-	for peerID, peer := range p2p.Peers {
-		if !peer.Active {
+// SyncWithPeers sincroniza la blockchain con todos los peers
+func (p2p *P2PNetwork) SyncWithPeers() error {
+	p2p.syncChains()
+	return nil
+}
+
+// syncChains adopta la cadena válida más larga de los peers activos y retorna
+// cuántos respondieron de los consultados
+func (p2p *P2PNetwork) syncChains() (reached, total int) {
+	p2p.syncing.Lock()
+	defer p2p.syncing.Unlock()
+	
+	// Los peers de mejor reputación primero; los bloqueados no se consultan
+	peers := p2p.rankedPeers()
+	fmt.Printf("🔄 Iniciando sincronización con %d peers\n", len(peers))
+	
+	for _, peer := range peers {
+		peerID := peer.ID
+		total++
+		
+		started := time.Now()
+		chain, err := p2p.requestChainFromPeer(&peer)
+		if err != nil {
+			fmt.Printf("❌ Error obteniendo cadena de %s: %v\n", peerID, err)
+			p2p.recordFailure(peerID)
 			continue
 		}
+		reached++
+		p2p.recordSuccess(peerID, time.Since(started))
 		
-		go func(peerID string, peer *Peer) {
-			err := p2p.sendBlockToPeer(peer, block)
-			if err != nil {
-				fmt.Printf("❌ Error enviando bloque a %s: %v\n", peerID, err)
-				p2p.markPeerInactive(peerID)
-			} else {
-				fmt.Printf("✅ Bloque enviado a %s\n", peerID)
-			}
-		}(peerID, peer)
-	}
-}
-
-// sendBlockToPeer envía un bloque a un peer específico
-func (p2p *P2PNetwork) sendBlockToPeer(peer *Peer, block Block) error {
-	url := fmt.Sprintf("http://%s:%s/api/p2p/receive-block", peer.Address, peer.Port)
-	
-	blockData, err := json.Marshal(block)
-	if err != nil {
-		return err
-	}
-	
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(blockData))
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("peer respondió con status %d", resp.StatusCode)
+		if len(chain) <= p2p.Blockchain.GetBlockchainHeight() {
+			continue
+		}
+		if !p2p.Blockchain.IsValidChain(chain) {
+			p2p.ReportViolation(peerID, "cadena inválida")
+			continue
+		}
+		
+		// Si el peer tiene una cadena más larga y válida, la adoptamos
+		fmt.Printf("🔄 Adoptando cadena más larga de %s (%d bloques)\n", peerID, len(chain))
+		newChain := make([]*Block, len(chain))
+		for i := range chain {
+			newChain[i] = &chain[i]
+		}
+		if err := p2p.Blockchain.ReplaceChain(newChain); err != nil {
+			fmt.Printf("❌ Error adoptando cadena de %s: %v\n", peerID, err)
+		}
 	}
 	
-	return nil
+	return reached, total
 }
 
-// ReceiveBlock procesa un bloque recibido de otro peer
-func (p2p *P2PNetwork) ReceiveBlock(block Block) error {
-	fmt.Printf("📥 Bloque recibido de peer: %s\n", block.Hash)
-	
-	// Validar el bloque
-	if !p2p.Blockchain.IsValidBlock(block) {
-		return fmt.Errorf("bloque inválido recibido")
+// SyncIncremental descarga de cada peer activo solo los bloques posteriores a
+// la altura local. Si un peer está en otra bifurcación se recurre a la
+// sincronización completa, que adopta la cadena válida más larga.
+func (p2p *P2PNetwork) SyncIncremental(ctx context.Context) error {
+	// Los peers de mejor reputación primero; los bloqueados no se consultan
+	peers := p2p.rankedPeers()
+	
+	reached, appended, diverged := 0, 0, false
+	var lastErr error
+	for _, peer := range peers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		started := time.Now()
+		added, forked, err := p2p.pullBlocks(ctx, peer)
+		appended += added
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", peer.ID, err)
+			if !errors.Is(err, errPeerViolation) {
+				p2p.recordFailure(peer.ID)
+			}
+			continue
+		}
+		reached++
+		p2p.recordSuccess(peer.ID, time.Since(started))
+		diverged = diverged || forked
 	}
 	
-	// Verificar si ya tenemos este bloque
-	if p2p.Blockchain.HasBlock(block.Hash) {
-		fmt.Printf("⚠️ Bloque %s ya existe, ignorando\n", block.Hash)
-		return nil
+	if appended > 0 {
+		fmt.Printf("🔄 Sincronización incremental: %d bloques nuevos (altura %d)\n", appended, p2p.Blockchain.GetBlockchainHeight())
 	}
-	
-	// Agregar el bloque a nuestra cadena
-	blockData := map[string]interface{}{
-		"type":          block.Type,
-		"data":          block.Data,
-		"timestamp":     block.Timestamp,
-		"previous_hash": block.PreviousHash,
-		"nonce":         block.Nonce,
+	if diverged {
+		p2p.syncChains()
 	}
-	
-	_, err := p2p.Blockchain.AddBlock(blockData)
-	if err != nil {
-		return fmt.Errorf("error agregando bloque: %v", err)
+	if len(peers) > 0 && reached == 0 {
+		return fmt.Errorf("ningún peer disponible para sincronizar: %v", lastErr)
 	}
-	
-	fmt.Printf("✅ Bloque %s agregado exitosamente\n", block.Hash)
 	return nil
 }
 
-// SyncWithPeers sincroniza la blockchain con todos los peers
-func (p2p *P2PNetwork) SyncWithPeers() error {
-	p2p.mutex.RLock()
-	defer p2p.mutex.RUnlock()
-	
-	fmt.Printf("🔄 Iniciando sincronización con %d peers\n", len(p2p.Peers))
-	
-	for peerID, peer := range p2p.Peers {
-		if !peer.Active {
-			continue
+// pullBlocks agrega los bloques que el peer tiene después de la altura local.
+// Reporta si el peer está en otra bifurcación.
+func (p2p *P2PNetwork) pullBlocks(ctx context.Context, peer Peer) (added int, forked bool, err error) {
+	p2p.syncing.Lock()
+	defer p2p.syncing.Unlock()
+	
+	for {
+		height := p2p.Blockchain.GetBlockchainHeight()
+		url := fmt.Sprintf("http://%s:%s/api/blocks?cursor=%d&limit=%d", peer.Address, peer.Port, height-1, MaxPageSize)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return added, false, err
 		}
-		
-		chain, err := p2p.requestChainFromPeer(peer)
+		resp, err := p2pClient.Do(req)
 		if err != nil {
-			fmt.Printf("❌ Error obteniendo cadena de %s: %v\n", peerID, err)
-			continue
+			return added, false, err
+		}
+		var page BlockPage
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("peer respondió con status %d", resp.StatusCode)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return added, false, err
 		}
 		
-		// Si el peer tiene una cadena más larga y válida, la adoptamos
-		if len(chain) > len(p2p.Blockchain.Chain) && p2p.Blockchain.IsValidChain(chain) {
-			fmt.Printf("🔄 Adoptando cadena más larga de %s (%d bloques)\n", peerID, len(chain))
-			// Convertir []Block a []*Block
-			p2p.Blockchain.Chain = make([]*Block, len(chain))
-			for i, block := range chain {
-				blockCopy := block
-				p2p.Blockchain.Chain[i] = &blockCopy
+		if page.Height <= height || len(page.Blocks) == 0 {
+			return added, false, nil
+		}
+		if page.Blocks[0].PreviousHash != p2p.Blockchain.GetLastBlockHash() {
+			return added, true, nil
+		}
+		for _, block := range page.Blocks {
+			if !block.IsValid() {
+				reason := fmt.Sprintf("bloque %d con hash que no corresponde a su contenido", block.Index)
+				p2p.ReportViolation(peer.ID, reason)
+				return added, false, fmt.Errorf("%w: %s", errPeerViolation, reason)
+			}
+			// Un bloque válido que no enlaza puede deberse a un bloque local
+			// agregado durante la descarga: se resuelve con la sincronización completa
+			err := p2p.Blockchain.appendReceivedBlock(block)
+			if errors.Is(err, ErrInvalidBlockData) {
+				reason := fmt.Sprintf("bloque %d: %v", block.Index, err)
+				p2p.ReportViolation(peer.ID, reason)
+				return added, false, fmt.Errorf("%w: %s", errPeerViolation, reason)
 			}
-			p2p.rebuildContractsFromChain()
+			if err != nil {
+				return added, true, nil
+			}
+			added++
+		}
+		if page.NextCursor == "" {
+			return added, false, nil
 		}
 	}
-	
-	return nil
 }
 
 // requestChainFromPeer solicita la blockchain completa de un peer
 func (p2p *P2PNetwork) requestChainFromPeer(peer *Peer) ([]Block, error) {
 	url := fmt.Sprintf("http://%s:%s/api/p2p/get-chain", peer.Address, peer.Port)
 	
-	resp, err := http.Get(url)
+	resp, err := p2pClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -296,23 +398,6 @@ func (p2p *P2PNetwork) requestChainFromPeer(peer *Peer) ([]Block, error) {
 	return response.Chain, nil
 }
 
-// rebuildContractsFromChain reconstruye el mapa de contratos desde la cadena
-func (p2p *P2PNetwork) rebuildContractsFromChain() {
-	p2p.Blockchain.Contracts = make(map[string]*Contract)
-	
-	for _, block := range p2p.Blockchain.Chain {
-		if block.Type == "CONTRACT_CREATION" {
-			var contract Contract
-			err := json.Unmarshal([]byte(fmt.Sprintf("%v", block.Data)), &contract)
-			if err == nil {
-				p2p.Blockchain.Contracts[contract.ID] = &contract
-			}
-		}
-	}
-	
-	fmt.Printf("🔄 Contratos reconstruidos: %d\n", len(p2p.Blockchain.Contracts))
-}
-
 // markPeerInactive marca un peer como inactivo
 func (p2p *P2PNetwork) markPeerInactive(peerID string) {
 	p2p.mutex.Lock()
@@ -339,30 +424,82 @@ func (p2p *P2PNetwork) GetActivePeers() []*Peer {
 	return activePeers
 }
 
-// HealthCheck verifica el estado de todos los peers
-func (p2p *P2PNetwork) HealthCheck() {
+// HealthCheck verifica en paralelo el estado de todos los peers. Las
+// peticiones se hacen sin bloquear la red; solo la actualización del estado
+// toma el lock.
+func (p2p *P2PNetwork) HealthCheck(ctx context.Context) error {
+	p2p.mutex.RLock()
+	peers := make([]Peer, 0, len(p2p.Peers))
+	for _, peer := range p2p.Peers {
+		if peer.Port != "" && !p2p.reputation.banned(peer.ID) {
+			peers = append(peers, *peer)
+		}
+	}
+	p2p.mutex.RUnlock()
+	
+	healthy := make([]bool, len(peers))
+	latency := make([]time.Duration, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			latency[i], healthy[i] = p2p.pingPeer(ctx, peer)
+		}()
+	}
+	wg.Wait()
+	
 	p2p.mutex.Lock()
 	defer p2p.mutex.Unlock()
 	
-	for peerID, peer := range p2p.Peers {
-		url := fmt.Sprintf("http://%s:%s/api/health", peer.Address, peer.Port)
-		
-		client := &http.Client{Timeout: 5 * time.Second}
-		resp, err := client.Get(url)
-		
-		if err != nil || resp.StatusCode != http.StatusOK {
-			peer.Active = false
-			fmt.Printf("💔 Peer %s no responde\n", peerID)
-		} else {
+	alive := 0
+	for i, checked := range peers {
+		peer, exists := p2p.Peers[checked.ID]
+		if !exists {
+			continue
+		}
+		if healthy[i] {
+			alive++
+			p2p.reputation.success(peer.ID, latency[i])
+			if !peer.Active {
+				fmt.Printf("💚 Peer %s activo de nuevo\n", peer.ID)
+			}
 			peer.Active = true
 			peer.LastSeen = config.GetColombianTime()
-			fmt.Printf("💚 Peer %s activo\n", peerID)
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
+			// Entregar los bloques que quedaron pendientes mientras no respondía
+			p2p.broadcast.resume(peer.ID)
+		} else if p2p.reputation.failure(peer.ID) >= peerMaxConsecutiveFailures {
+			// Un error aislado no basta para dejar de usar el peer
+			if peer.Active {
+				fmt.Printf("💔 Peer %s no responde\n", peer.ID)
+			}
+			peer.Active = false
 		}
 	}
+	
+	if len(peers) > 0 && alive == 0 {
+		return fmt.Errorf("ningún peer respondió (%d consultados)", len(peers))
+	}
+	return nil
+}
+
+// pingPeer consulta el endpoint de salud de un peer
+func (p2p *P2PNetwork) pingPeer(ctx context.Context, peer Peer) (time.Duration, bool) {
+	ctx, cancel := context.WithTimeout(ctx, peerHealthTimeout)
+	defer cancel()
+	
+	url := fmt.Sprintf("http://%s:%s/api/health/live", peer.Address, peer.Port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, false
+	}
+	started := time.Now()
+	resp, err := p2pClient.Do(req)
+	if err != nil {
+		return 0, false
+	}
+	resp.Body.Close()
+	return time.Since(started), resp.StatusCode == http.StatusOK
 }
 
 // GetNetworkHealth returns the health status of the P2P network
@@ -386,10 +523,11 @@ func (p2p *P2PNetwork) GetNetworkHealth() map[string]interface{} {
 	
 	health := map[string]interface{}{
 		"node_id":           p2p.NodeID,
-		"address":           fmt.Sprintf("%s:%d", p2p.Address, p2p.Port),
+		"address":           fmt.Sprintf("%s:%s", p2p.Address, p2p.Port),
 		"total_peers":       totalPeers,
 		"active_peers":      activePeers,
-		"peer_discovery":    p2p.PeerDiscovery != nil,
+		"peer_discovery":    p2p.PeerDiscovery.Status(),
+		"ready":             p2p.IsReady(),
 		"blockchain_health": blockchainHealth,
 		"timestamp":         config.GetColombianTime(),
 	}
@@ -397,11 +535,15 @@ func (p2p *P2PNetwork) GetNetworkHealth() map[string]interface{} {
 	// Add peer details
 	peerDetails := make(map[string]interface{})
 	for id, peer := range p2p.Peers {
+		outbox, _ := p2p.broadcast.status(id)
 		peerDetails[id] = map[string]interface{}{
 			"address":   peer.Address,
 			"port":      peer.Port,
 			"last_seen": peer.LastSeen,
 			"active":    peer.LastSeen.After(fiveMinutesAgo),
+			"score":     p2p.reputation.snapshot(id).Score,
+			"banned":    p2p.reputation.banned(id),
+			"pending":   outbox.Pending,
 		}
 	}
 	health["peers"] = peerDetails
@@ -416,7 +558,13 @@ func (p2p *P2PNetwork) GetPeers() map[string]*Peer {
 	
 	peers := make(map[string]*Peer)
 	for id, peer := range p2p.Peers {
-		peers[id] = peer
+		view := *peer
+		score := p2p.reputation.snapshot(id)
+		view.Reputation = &score
+		if outbox, exists := p2p.broadcast.status(id); exists {
+			view.Outbox = &outbox
+		}
+		peers[id] = &view
 	}
 	return peers
 }
@@ -448,6 +596,8 @@ func (p2p *P2PNetwork) RemovePeer(id string) error {
 	}
 	
 	delete(p2p.Peers, id)
+	p2p.broadcast.remove(id)
+	p2p.PeerDiscovery.RemovePeer(id)
 	fmt.Printf("❌ Peer %s eliminado\n", id)
 	return nil
 }
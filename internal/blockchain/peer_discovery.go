@@ -1,7 +1,9 @@
 package blockchain
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,15 +15,53 @@ import (
 
 // PeerDiscovery manages dynamic peer discovery for government entities
 type PeerDiscovery struct {
-	registryURL     string
-	nodeID          string
-	nodeAddress     string
-	entityType      string
-	knownPeers      map[string]*PeerInfo
-	mutex           sync.RWMutex
-	discoveryTicker *time.Ticker
+	registryURLs      []string
+	nodeID            string
+	nodeAddress       string
+	nodePort          string
+	entityType        string
+	knownPeers        map[string]*PeerInfo
+	mutex             sync.RWMutex
+	heartbeatInterval time.Duration
+	client            *http.Client
+	current           int  // Preferred registry (the last one that answered)
+	registered        bool
+	degraded          bool // Registry unreachable: known peers are kept
+	failures          int
+	lastContact       time.Time
+	gossip            *peerExchange // nil unless peer exchange is configured
 }
 
+// DiscoveryStatus describes the node's relation with the peer registry
+type DiscoveryStatus struct {
+	Mode                string    `json:"mode"` // BOOTSTRAP, REGISTRY or DEGRADED
+	Registry            string    `json:"registry,omitempty"`
+	Registries          []string  `json:"registries,omitempty"`
+	Registered          bool      `json:"registered"`
+	KnownPeers          int       `json:"known_peers"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastContact         time.Time `json:"last_contact,omitempty"`
+	Gossip              bool      `json:"gossip"`
+	VerifiedPeers       int       `json:"verified_peers"`
+	GossipCandidates    int       `json:"gossip_candidates"`
+}
+
+// Discovery modes
+const (
+	DiscoveryBootstrap = "BOOTSTRAP"
+	DiscoveryRegistry  = "REGISTRY"
+	DiscoveryDegraded  = "DEGRADED"
+)
+
+// Retry backoff against the registry
+const (
+	DiscoveryRetryMin = 2 * time.Second
+	DiscoveryRetryMax = 2 * time.Minute
+)
+
+// errNotRegistered means the registry answered but does not know this node
+var errNotRegistered = errors.New("node not registered")
+
 // PeerInfo contains information about a discovered peer
 type PeerInfo struct {
 	ID          string    `json:"id"`
@@ -31,6 +71,8 @@ type PeerInfo struct {
 	LastSeen    time.Time `json:"last_seen"`
 	IsActive    bool      `json:"is_active"`
 	PublicKey   string    `json:"public_key,omitempty"`
+	Verified    bool      `json:"verified,omitempty"` // Confirmed its signed record by direct contact
+	Source      string    `json:"source,omitempty"`   // registry, bootstrap, gossip or manual
 }
 
 // EntityType defines the type of government entity
@@ -45,142 +87,285 @@ const (
 	EntityDNP          EntityType = "DNP"          // Departamento Nacional de Planeación (legacy)
 )
 
-// NewPeerDiscovery creates a new peer discovery service
-func NewPeerDiscovery(registryURL, nodeID, nodeAddress, entityType string) *PeerDiscovery {
+// NewPeerDiscovery creates a new peer discovery service. Registry URLs are
+// tried in order, failing over to the next when one is unreachable.
+func NewPeerDiscovery(registryURLs []string, nodeID, nodeAddress, nodePort, entityType string) *PeerDiscovery {
+	urls := make([]string, 0, len(registryURLs))
+	for _, url := range registryURLs {
+		if url = strings.TrimRight(strings.TrimSpace(url), "/"); url != "" {
+			urls = append(urls, url)
+		}
+	}
 	return &PeerDiscovery{
-		registryURL: registryURL,
-		nodeID:      nodeID,
-		nodeAddress: nodeAddress,
-		entityType:  entityType,
-		knownPeers:  make(map[string]*PeerInfo),
+		registryURLs:      urls,
+		nodeID:            nodeID,
+		nodeAddress:       nodeAddress,
+		nodePort:          nodePort,
+		entityType:        entityType,
+		knownPeers:        make(map[string]*PeerInfo),
+		heartbeatInterval: 30 * time.Second,
+		client:            &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
-// Start begins the peer discovery process
-func (pd *PeerDiscovery) Start() error {
-	// Register this node with the discovery service
-	if err := pd.registerNode(); err != nil {
-		return fmt.Errorf("failed to register node: %v", err)
+// ConfigureHeartbeat sets how often the node refreshes its registration. It
+// must be shorter than the registry TTL.
+func (pd *PeerDiscovery) ConfigureHeartbeat(interval time.Duration) {
+	if interval > 0 {
+		pd.heartbeatInterval = interval
 	}
+}
 
-	// Start periodic peer discovery
-	pd.discoveryTicker = time.NewTicker(30 * time.Second)
-	go pd.discoveryLoop()
+// HeartbeatInterval returns how often the registration must be refreshed
+func (pd *PeerDiscovery) HeartbeatInterval() time.Duration {
+	return pd.heartbeatInterval
+}
+
+// UsesRegistry reports whether the node discovers peers through a registry
+func (pd *PeerDiscovery) UsesRegistry() bool {
+	return len(pd.registryURLs) > 0
+}
+
+// Start begins the peer discovery process by registering with the registry.
+// An unreachable registry does not prevent the node from starting: it runs
+// degraded with its known peers while the scheduler keeps calling Refresh.
+func (pd *PeerDiscovery) Start() error {
+	if len(pd.registryURLs) == 0 {
+		// If no registry URL, use bootstrap mode (for first nodes)
+		log.Println("No registry URL configured, running in bootstrap mode")
+		return nil
+	}
 
-	log.Printf("Peer discovery started for node %s (%s)", pd.nodeID, pd.entityType)
+	pd.Refresh()
+	log.Printf("Peer discovery started for node %s (%s) with %d registries", pd.nodeID, pd.entityType, len(pd.registryURLs))
 	return nil
 }
 
 // Stop stops the peer discovery process
 func (pd *PeerDiscovery) Stop() {
-	if pd.discoveryTicker != nil {
-		pd.discoveryTicker.Stop()
-	}
-	
 	// Unregister from discovery service
 	pd.unregisterNode()
 }
 
-// registerNode registers this node with the central discovery service
-func (pd *PeerDiscovery) registerNode() error {
-	if pd.registryURL == "" {
-		// If no registry URL, use bootstrap mode (for first nodes)
-		log.Println("No registry URL configured, running in bootstrap mode")
+// Refresh sends a heartbeat (registering if needed) and fetches the peer
+// list. The caller retries failures with backoff.
+func (pd *PeerDiscovery) Refresh() error {
+	err := pd.heartbeat()
+	if errors.Is(err, errNotRegistered) {
+		err = pd.registerNode()
+	}
+	if err == nil {
+		err = pd.discoverPeers()
+	}
+
+	pd.mutex.Lock()
+	defer pd.mutex.Unlock()
+
+	if err == nil {
+		if pd.degraded {
+			log.Printf("Peer registry reachable again at %s, leaving degraded mode", pd.registryURLs[pd.current])
+		}
+		pd.degraded = false
+		pd.failures = 0
+		pd.lastContact = config.GetColombianTime()
 		return nil
 	}
 
+	pd.failures++
+	if !pd.degraded {
+		log.Printf("Peer registry unreachable (%v), running degraded with %d known peers", err, len(pd.knownPeers))
+	}
+	pd.degraded = true
+	pd.registered = false
+	return err
+}
+
+// withRegistry runs request against the preferred registry, failing over to
+// the others when it is unreachable. A registry that answers that it does not
+// know this node stops the failover: it is reachable and the caller registers.
+// Switching registries marks the node unregistered so it registers there.
+func (pd *PeerDiscovery) withRegistry(request func(registryURL string) error) error {
+	pd.mutex.RLock()
+	first := pd.current
+	pd.mutex.RUnlock()
+
+	var lastErr error
+	for i := range pd.registryURLs {
+		index := (first + i) % len(pd.registryURLs)
+		err := request(pd.registryURLs[index])
+		if err == nil || errors.Is(err, errNotRegistered) {
+			pd.mutex.Lock()
+			if pd.current != index {
+				log.Printf("Peer registry failover: using %s", pd.registryURLs[index])
+				pd.current = index
+				pd.registered = false
+			}
+			pd.mutex.Unlock()
+			return err
+		}
+		lastErr = fmt.Errorf("%s: %v", pd.registryURLs[index], err)
+	}
+	return lastErr
+}
+
+// registerNode registers this node with the discovery service
+func (pd *PeerDiscovery) registerNode() error {
 	nodeInfo := PeerInfo{
 		ID:         pd.nodeID,
 		Address:    pd.nodeAddress,
-		Port:       "", // Add empty port field
+		Port:       pd.nodePort,
 		EntityType: pd.entityType,
 		LastSeen:   config.GetColombianTime(),
 		IsActive:   true,
 	}
+	// Signed like the peer exchange record, so only this node can later change
+	// or remove its entry
+	registration := struct {
+		PeerInfo
+		Timestamp int64  `json:"timestamp,omitempty"`
+		Signature string `json:"signature,omitempty"`
+	}{PeerInfo: nodeInfo}
+	if pd.gossip != nil {
+		record := pd.selfRecord()
+		registration.PublicKey = record.PublicKey
+		registration.Timestamp = record.Timestamp
+		registration.Signature = record.Signature
+	}
 
-	data, err := json.Marshal(nodeInfo)
+	data, err := json.Marshal(registration)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.Post(
-		fmt.Sprintf("%s/api/peers/register", pd.registryURL),
-		"application/json",
-		strings.NewReader(string(data)),
-	)
+	err = pd.withRegistry(func(registryURL string) error {
+		resp, err := pd.client.Post(
+			fmt.Sprintf("%s/api/peers/register", registryURL),
+			"application/json",
+			bytes.NewReader(data),
+		)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("registration failed with status: %d", resp.StatusCode)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("registration failed with status: %d", resp.StatusCode)
+	pd.mutex.Lock()
+	pd.registered = true
+	pd.mutex.Unlock()
+	log.Printf("Node %s registered with peer registry", pd.nodeID)
+	return nil
+}
+
+// heartbeat keeps this node's registry entry alive
+func (pd *PeerDiscovery) heartbeat() error {
+	pd.mutex.RLock()
+	registered := pd.registered
+	pd.mutex.RUnlock()
+	if !registered {
+		return errNotRegistered
 	}
 
-	return nil
+	return pd.withRegistry(func(registryURL string) error {
+		resp, err := pd.client.Post(fmt.Sprintf("%s/api/peers/heartbeat/%s", registryURL, pd.nodeID), "application/json", nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return nil
+		case http.StatusNotFound:
+			return errNotRegistered
+		}
+		return fmt.Errorf("heartbeat failed with status: %d", resp.StatusCode)
+	})
+}
+
+// UnregisterPayload is what a node signs to remove its registry entry
+func UnregisterPayload(id string, timestamp int64) []byte {
+	return []byte(fmt.Sprintf("unregister|%s|%d", id, timestamp))
 }
 
 // unregisterNode removes this node from the discovery service
 func (pd *PeerDiscovery) unregisterNode() {
-	if pd.registryURL == "" {
+	pd.mutex.RLock()
+	registered := pd.registered
+	pd.mutex.RUnlock()
+	if len(pd.registryURLs) == 0 || !registered {
 		return
 	}
 
-	url := fmt.Sprintf("%s/api/peers/unregister/%s", pd.registryURL, pd.nodeID)
+	pd.mutex.RLock()
+	url := fmt.Sprintf("%s/api/peers/unregister/%s", pd.registryURLs[pd.current], pd.nodeID)
+	pd.mutex.RUnlock()
+	if pd.gossip != nil {
+		timestamp := time.Now().Unix()
+		signature := pd.gossip.identity.Sign(UnregisterPayload(pd.nodeID, timestamp))
+		url += fmt.Sprintf("?timestamp=%d&signature=%s", timestamp, signature)
+	}
 	req, err := http.NewRequest("DELETE", url, nil)
 	if err != nil {
 		log.Printf("Error creating unregister request: %v", err)
 		return
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := pd.client.Do(req)
 	if err != nil {
 		log.Printf("Error unregistering node: %v", err)
 		return
 	}
 	defer resp.Body.Close()
-}
 
-// discoveryLoop periodically discovers new peers
-func (pd *PeerDiscovery) discoveryLoop() {
-	for range pd.discoveryTicker.C {
-		if err := pd.discoverPeers(); err != nil {
-			log.Printf("Peer discovery error: %v", err)
-		}
-	}
+	pd.mutex.Lock()
+	pd.registered = false
+	pd.mutex.Unlock()
 }
 
 // discoverPeers fetches the list of active peers from the discovery service
 func (pd *PeerDiscovery) discoverPeers() error {
-	if pd.registryURL == "" {
-		return nil // Bootstrap mode, no discovery needed
-	}
+	var peers []PeerInfo
+	err := pd.withRegistry(func(registryURL string) error {
+		resp, err := pd.client.Get(fmt.Sprintf("%s/api/peers", registryURL))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
 
-	resp, err := http.Get(fmt.Sprintf("%s/api/peers", pd.registryURL))
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("peer list failed with status: %d", resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(&peers)
+	})
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	var peers []PeerInfo
-	if err := json.NewDecoder(resp.Body).Decode(&peers); err != nil {
-		return err
-	}
 
 	pd.mutex.Lock()
 	defer pd.mutex.Unlock()
 
-	// Update known peers
+	// Update known peers, keeping what gossip already verified
 	for _, peer := range peers {
 		if peer.ID != pd.nodeID { // Don't add ourselves
+			peer.Source = PeerSourceRegistry
+			if known, ok := pd.knownPeers[peer.ID]; ok {
+				peer.Verified = known.Verified && known.Address == peer.Address && known.Port == peer.Port
+			}
 			pd.knownPeers[peer.ID] = &peer
 		}
 	}
 
-	// Remove inactive peers
+	// Remove registry peers that stopped appearing; gossip expires its own
 	for id, peer := range pd.knownPeers {
-		if config.GetColombianTime().Sub(peer.LastSeen) > 5*time.Minute {
+		if peer.Source == PeerSourceRegistry && config.GetColombianTime().Sub(peer.LastSeen) > 5*time.Minute {
 			delete(pd.knownPeers, id)
 		}
 	}
@@ -189,6 +374,38 @@ func (pd *PeerDiscovery) discoverPeers() error {
 	return nil
 }
 
+// Status returns the discovery mode and registry health
+func (pd *PeerDiscovery) Status() DiscoveryStatus {
+	pd.mutex.RLock()
+	defer pd.mutex.RUnlock()
+
+	status := DiscoveryStatus{
+		Mode:                DiscoveryBootstrap,
+		Registries:          pd.registryURLs,
+		Registered:          pd.registered,
+		KnownPeers:          len(pd.knownPeers),
+		ConsecutiveFailures: pd.failures,
+		LastContact:         pd.lastContact,
+	}
+	for _, peer := range pd.knownPeers {
+		if peer.Verified {
+			status.VerifiedPeers++
+		}
+	}
+	if pd.gossip != nil {
+		status.Gossip = true
+		status.GossipCandidates = len(pd.gossip.candidates)
+	}
+	if len(pd.registryURLs) > 0 {
+		status.Mode = DiscoveryRegistry
+		status.Registry = pd.registryURLs[pd.current]
+		if pd.degraded {
+			status.Mode = DiscoveryDegraded
+		}
+	}
+	return status
+}
+
 // GetActivePeers returns a list of currently active peers
 func (pd *PeerDiscovery) GetActivePeers() []*PeerInfo {
 	pd.mutex.RLock()
@@ -219,21 +436,50 @@ func (pd *PeerDiscovery) GetPeersByType(entityType EntityType) []*PeerInfo {
 	return peers
 }
 
-// AddBootstrapPeer manually adds a bootstrap peer (for initial network formation)
-func (pd *PeerDiscovery) AddBootstrapPeer(id, address, entityType string) {
+// AddBootstrapPeer manually adds a bootstrap peer (for initial network
+// formation). Its entity type is learned on the first peer exchange.
+func (pd *PeerDiscovery) AddBootstrapPeer(id, address, port string) {
+	pd.addStaticPeer(id, address, port, PeerSourceBootstrap)
+	log.Printf("Added bootstrap peer: %s (%s:%s)", id, address, port)
+}
+
+// AddManualPeer keeps a peer added by an operator, so the periodic peer sync
+// does not drop it
+func (pd *PeerDiscovery) AddManualPeer(id, address, port string) {
+	pd.addStaticPeer(id, address, port, PeerSourceManual)
+}
+
+// RemovePeer forgets a peer until a registry or gossip reports it again
+func (pd *PeerDiscovery) RemovePeer(id string) {
 	pd.mutex.Lock()
 	defer pd.mutex.Unlock()
 
-	pd.knownPeers[id] = &PeerInfo{
-		ID:         id,
-		Address:    address,
-		Port:       "", // Add empty port field
-		EntityType: entityType,
-		LastSeen:   config.GetColombianTime(),
-		IsActive:   true,
+	delete(pd.knownPeers, id)
+	if pd.gossip != nil {
+		delete(pd.gossip.records, id)
+		delete(pd.gossip.failures, id)
 	}
+}
+
+// addStaticPeer adds a peer that discovery never expires
+func (pd *PeerDiscovery) addStaticPeer(id, address, port, source string) {
+	pd.mutex.Lock()
+	defer pd.mutex.Unlock()
 
-	log.Printf("Added bootstrap peer: %s (%s)", id, entityType)
+	peer := &PeerInfo{
+		ID:       id,
+		Address:  address,
+		Port:     port,
+		LastSeen: config.GetColombianTime(),
+		IsActive: true,
+		Source:   source,
+	}
+	if known, ok := pd.knownPeers[id]; ok {
+		peer.EntityType = known.EntityType
+		peer.PublicKey = known.PublicKey
+		peer.Verified = known.Verified && known.Address == address && known.Port == port
+	}
+	pd.knownPeers[id] = peer
 }
 
 // GetPeerCount returns the number of known active peers
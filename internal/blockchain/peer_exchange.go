@@ -0,0 +1,437 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/identity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Peer sources
+const (
+	PeerSourceRegistry  = "registry"
+	PeerSourceBootstrap = "bootstrap"
+	PeerSourceGossip    = "gossip"
+	PeerSourceManual    = "manual"
+)
+
+// Limits against peer-list poisoning
+const (
+	gossipMaxRecords    = 64               // Records accepted from a single exchange
+	gossipMaxPerSource  = 16               // Unverified candidates a single remote address may introduce
+	gossipMaxCandidates = 256              // Unverified candidates held at once
+	gossipRecordMaxAge  = 30 * time.Minute // Older records are ignored and stale gossip peers dropped
+	gossipClockSkew     = time.Minute      // Tolerance for records signed in the future
+	gossipMaxFailures   = 3                // Failed exchanges before a gossip peer is dropped
+)
+
+// ErrPeerExchangeDisabled is returned when the node does not take part in gossip
+var ErrPeerExchangeDisabled = errors.New("peer exchange disabled on this node")
+
+// PeerRecord is a node's self-description signed with its identity key.
+// Records are relayed unchanged, so a node cannot forge another's record.
+type PeerRecord struct {
+	ID         string `json:"id"`
+	Address    string `json:"address"`
+	Port       string `json:"port"`
+	EntityType string `json:"entity_type"`
+	PublicKey  string `json:"public_key"`
+	Timestamp  int64  `json:"timestamp"` // Unix seconds when the node signed the record
+	Signature  string `json:"signature"`
+}
+
+// PeerExchange is the gossip message: the sender's record and the peers it vouches for
+type PeerExchange struct {
+	From  PeerRecord   `json:"from"`
+	Peers []PeerRecord `json:"peers"`
+}
+
+// gossipCandidate is a peer learned through gossip that has not been contacted yet
+type gossipCandidate struct {
+	record PeerRecord
+	source string // Address of the node that introduced it
+}
+
+// peerExchange holds the gossip state, guarded by the PeerDiscovery mutex
+type peerExchange struct {
+	identity   *identity.Identity
+	interval   time.Duration
+	fanout     int
+	maxPeers   int
+	records    map[string]PeerRecord // Latest record of each verified peer
+	keys       map[string]string     // Public key first seen for each node ID
+	candidates map[string]*gossipCandidate
+	introduced map[string]int // Candidates currently held per introducing address
+	failures   map[string]int
+}
+
+func (r PeerRecord) payload() []byte {
+	return []byte(fmt.Sprintf("%s|%s|%s|%s|%s|%d", r.ID, r.Address, r.Port, r.EntityType, r.PublicKey, r.Timestamp))
+}
+
+// validate checks the record's fields, freshness and signature
+func (r PeerRecord) validate(now time.Time) error {
+	if r.ID == "" || len(r.ID) > 128 {
+		return errors.New("invalid peer id")
+	}
+	if r.Address == "" || len(r.Address) > 253 || strings.ContainsAny(r.Address, "/?#@ ") {
+		return fmt.Errorf("invalid address for peer %s", r.ID)
+	}
+	if port, err := strconv.Atoi(r.Port); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port for peer %s", r.ID)
+	}
+	if r.EntityType == "" {
+		return fmt.Errorf("missing entity type for peer %s", r.ID)
+	}
+	if key, err := hex.DecodeString(r.PublicKey); err != nil || len(key) != 32 {
+		return fmt.Errorf("invalid public key for peer %s", r.ID)
+	}
+	return r.Verify(now)
+}
+
+// Verify checks only the record's freshness and signature. The registry uses it
+// for registrations, whose address it may fill in from the connection.
+func (r PeerRecord) Verify(now time.Time) error {
+	signedAt := time.Unix(r.Timestamp, 0)
+	if now.Sub(signedAt) > gossipRecordMaxAge || signedAt.Sub(now) > gossipClockSkew {
+		return fmt.Errorf("stale record for peer %s", r.ID)
+	}
+	if !identity.Verify(r.PublicKey, r.payload(), r.Signature) {
+		return fmt.Errorf("invalid signature for peer %s", r.ID)
+	}
+	return nil
+}
+
+// ConfigureGossip enables peer exchange signed with the node identity
+func (pd *PeerDiscovery) ConfigureGossip(nodeIdentity *identity.Identity, interval time.Duration, fanout, maxPeers int) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if fanout <= 0 {
+		fanout = 3
+	}
+	if maxPeers <= 0 {
+		maxPeers = 128
+	}
+
+	pd.mutex.Lock()
+	defer pd.mutex.Unlock()
+	pd.gossip = &peerExchange{
+		identity:   nodeIdentity,
+		interval:   interval,
+		fanout:     fanout,
+		maxPeers:   maxPeers,
+		records:    make(map[string]PeerRecord),
+		keys:       make(map[string]string),
+		candidates: make(map[string]*gossipCandidate),
+		introduced: make(map[string]int),
+		failures:   make(map[string]int),
+	}
+}
+
+// selfRecord signs a fresh record describing this node
+func (pd *PeerDiscovery) selfRecord() PeerRecord {
+	record := PeerRecord{
+		ID:         pd.nodeID,
+		Address:    pd.nodeAddress,
+		Port:       pd.nodePort,
+		EntityType: pd.entityType,
+		PublicKey:  pd.gossip.identity.PublicKey(),
+		Timestamp:  time.Now().Unix(),
+	}
+	record.Signature = pd.gossip.identity.Sign(record.payload())
+	return record
+}
+
+// HandleExchange answers a peer's gossip message with this node's record and
+// the peers it has verified. The sender and the peers it lists become
+// candidates until this node contacts them itself. Candidates are counted
+// against remoteIP, since the sender chooses the ID in its own record.
+func (pd *PeerDiscovery) HandleExchange(msg PeerExchange, remoteIP string) (*PeerExchange, error) {
+	if pd.gossip == nil {
+		return nil, ErrPeerExchangeDisabled
+	}
+	if err := msg.From.validate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	pd.mutex.Lock()
+	pd.learn(msg.From, remoteIP)
+	for i, record := range msg.Peers {
+		if i >= gossipMaxRecords {
+			break
+		}
+		pd.learn(record, remoteIP)
+	}
+	shared := pd.shareable()
+	pd.mutex.Unlock()
+
+	return &PeerExchange{From: pd.selfRecord(), Peers: shared}, nil
+}
+
+// learn considers a gossiped record. Must be called with the mutex held.
+func (pd *PeerDiscovery) learn(record PeerRecord, source string) {
+	g := pd.gossip
+	if record.ID == pd.nodeID || record.validate(time.Now()) != nil {
+		return
+	}
+	key, pinned := g.keys[record.ID]
+	if pinned && key != record.PublicKey {
+		log.Printf("Peer exchange: ignoring record for %s with a different key (from %s)", record.ID, source)
+		return
+	}
+	// Registry, bootstrap and manual peers are verified at their configured
+	// address first; until then gossip cannot say where they are
+	if _, ok := pd.knownPeers[record.ID]; ok && !pinned {
+		return
+	}
+
+	if known, ok := pd.knownPeers[record.ID]; ok && known.Verified {
+		if known.Address == record.Address && known.Port == record.Port {
+			if record.Timestamp > g.records[record.ID].Timestamp {
+				g.records[record.ID] = record
+			}
+			return
+		}
+		// The peer moved: verify the new address before using it
+	}
+
+	if candidate, ok := g.candidates[record.ID]; ok {
+		if record.Timestamp > candidate.record.Timestamp {
+			candidate.record = record
+		}
+		return
+	}
+	if len(g.candidates) >= gossipMaxCandidates || g.introduced[source] >= gossipMaxPerSource {
+		return
+	}
+	g.candidates[record.ID] = &gossipCandidate{record: record, source: source}
+	g.introduced[source]++
+}
+
+// dropCandidate removes a candidate. Must be called with the mutex held.
+func (pd *PeerDiscovery) dropCandidate(id string) {
+	g := pd.gossip
+	if candidate, ok := g.candidates[id]; ok {
+		g.introduced[candidate.source]--
+		if g.introduced[candidate.source] <= 0 {
+			delete(g.introduced, candidate.source)
+		}
+		delete(g.candidates, id)
+	}
+}
+
+// shareable returns the records of recently verified peers, in random order.
+// Must be called with the mutex held.
+func (pd *PeerDiscovery) shareable() []PeerRecord {
+	cutoff := time.Now().Add(-gossipRecordMaxAge).Unix()
+	records := make([]PeerRecord, 0, len(pd.gossip.records))
+	for id, record := range pd.gossip.records {
+		if peer, ok := pd.knownPeers[id]; ok && peer.Verified && record.Timestamp >= cutoff {
+			records = append(records, record)
+		}
+	}
+	rand.Shuffle(len(records), func(i, j int) { records[i], records[j] = records[j], records[i] })
+	if len(records) > gossipMaxRecords {
+		records = records[:gossipMaxRecords]
+	}
+	return records
+}
+
+// gossipTarget is a peer to contact in a gossip round
+type gossipTarget struct {
+	id        string
+	address   string
+	port      string
+	publicKey string // Expected key, empty when not yet known
+	candidate bool
+}
+
+// GossipInterval returns how often peer exchange rounds run, or zero when
+// peer exchange is disabled
+func (pd *PeerDiscovery) GossipInterval() time.Duration {
+	if pd.gossip == nil {
+		return 0
+	}
+	return pd.gossip.interval
+}
+
+// GossipRound exchanges peer lists with a few known peers and verifies a few
+// candidates by contacting them directly
+func (pd *PeerDiscovery) GossipRound(ctx context.Context) error {
+	if pd.gossip == nil {
+		return ErrPeerExchangeDisabled
+	}
+
+	pd.mutex.Lock()
+	g := pd.gossip
+	pd.pruneGossipPeers()
+
+	var known, candidates []gossipTarget
+	for id, peer := range pd.knownPeers {
+		if peer.Port != "" {
+			known = append(known, gossipTarget{id: id, address: peer.Address, port: peer.Port, publicKey: g.keys[id]})
+		}
+	}
+	for id, candidate := range g.candidates {
+		candidates = append(candidates, gossipTarget{
+			id:        id,
+			address:   candidate.record.Address,
+			port:      candidate.record.Port,
+			publicKey: candidate.record.PublicKey,
+			candidate: true,
+		})
+	}
+	shared := pd.shareable()
+	pd.mutex.Unlock()
+
+	rand.Shuffle(len(known), func(i, j int) { known[i], known[j] = known[j], known[i] })
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	targets := append(known[:min(len(known), g.fanout)], candidates[:min(len(candidates), g.fanout)]...)
+
+	reached := 0
+	var lastErr error
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		response, err := pd.exchangeWith(ctx, target, shared)
+		pd.mutex.Lock()
+		if err != nil {
+			lastErr = err
+			pd.exchangeFailed(target, err)
+		} else {
+			reached++
+			pd.promote(response.From, target)
+			for i, record := range response.Peers {
+				if i >= gossipMaxRecords {
+					break
+				}
+				pd.learn(record, target.address)
+			}
+		}
+		pd.mutex.Unlock()
+	}
+
+	if len(targets) > 0 && reached == 0 {
+		return fmt.Errorf("no peer answered the exchange (%d contacted): %v", len(targets), lastErr)
+	}
+	return nil
+}
+
+// exchangeWith sends this node's gossip message to a peer and checks that the
+// answer comes from the node expected at that address
+func (pd *PeerDiscovery) exchangeWith(ctx context.Context, target gossipTarget, shared []PeerRecord) (*PeerExchange, error) {
+	data, err := json.Marshal(PeerExchange{From: pd.selfRecord(), Peers: shared})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("http://%s:%s/api/p2p/peer-exchange", target.address, target.port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := pd.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer exchange failed with status: %d", resp.StatusCode)
+	}
+
+	var response PeerExchange
+	if err := json.NewDecoder(http.MaxBytesReader(nil, resp.Body, 1<<20)).Decode(&response); err != nil {
+		return nil, err
+	}
+	if err := response.From.validate(time.Now()); err != nil {
+		return nil, err
+	}
+	if response.From.ID != target.id {
+		return nil, fmt.Errorf("expected peer %s at %s:%s, found %s", target.id, target.address, target.port, response.From.ID)
+	}
+	if target.publicKey != "" && response.From.PublicKey != target.publicKey {
+		return nil, fmt.Errorf("peer %s answered with a different key", target.id)
+	}
+	return &response, nil
+}
+
+// promote records a peer verified by direct contact. Must be called with the mutex held.
+func (pd *PeerDiscovery) promote(record PeerRecord, target gossipTarget) {
+	g := pd.gossip
+	pd.dropCandidate(record.ID)
+
+	peer, known := pd.knownPeers[record.ID]
+	if _, pinned := g.keys[record.ID]; known && !pinned && target.candidate {
+		// The candidate claims the ID of a peer configured elsewhere whose key
+		// is not pinned yet: only its configured address can pin it
+		log.Printf("Peer exchange: ignoring %s at %s:%s, not yet verified at its configured address", record.ID, target.address, target.port)
+		return
+	}
+	delete(g.failures, record.ID)
+
+	if !known {
+		gossiped := 0
+		for _, existing := range pd.knownPeers {
+			if existing.Source == PeerSourceGossip {
+				gossiped++
+			}
+		}
+		if gossiped >= g.maxPeers {
+			return
+		}
+		peer = &PeerInfo{ID: record.ID, Source: PeerSourceGossip}
+		pd.knownPeers[record.ID] = peer
+		log.Printf("Peer exchange: verified new peer %s (%s) at %s:%s", record.ID, record.EntityType, record.Address, record.Port)
+	}
+
+	g.keys[record.ID] = record.PublicKey
+	g.records[record.ID] = record
+	peer.Address = record.Address
+	peer.Port = record.Port
+	peer.EntityType = record.EntityType
+	peer.PublicKey = record.PublicKey
+	peer.LastSeen = config.GetColombianTime()
+	peer.IsActive = true
+	peer.Verified = true
+}
+
+// exchangeFailed drops unreachable candidates and gossip peers that keep
+// failing. Must be called with the mutex held.
+func (pd *PeerDiscovery) exchangeFailed(target gossipTarget, err error) {
+	g := pd.gossip
+	if target.candidate {
+		pd.dropCandidate(target.id)
+		return
+	}
+	g.failures[target.id]++
+	if peer, ok := pd.knownPeers[target.id]; ok && peer.Source == PeerSourceGossip && g.failures[target.id] >= gossipMaxFailures {
+		log.Printf("Peer exchange: dropping peer %s after %d failed exchanges: %v", target.id, g.failures[target.id], err)
+		delete(pd.knownPeers, target.id)
+		delete(g.records, target.id)
+		delete(g.failures, target.id)
+	}
+}
+
+// pruneGossipPeers drops gossip peers not verified for a long time. Must be
+// called with the mutex held.
+func (pd *PeerDiscovery) pruneGossipPeers() {
+	for id, peer := range pd.knownPeers {
+		if peer.Source == PeerSourceGossip && time.Since(peer.LastSeen) > gossipRecordMaxAge {
+			delete(pd.knownPeers, id)
+			delete(pd.gossip.records, id)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package blockchain
+
+import (
+	"fmt"
+	"net"
+	"secop-blockchain/internal/config"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Puntajes de reputación de los peers
+const (
+	scoreInitial   = 50  // Puntaje de un peer nuevo
+	scoreMax       = 100 // Puntaje máximo
+	scoreSuccess   = 1   // Premio por cada respuesta correcta
+	scoreFailure   = -5  // Castigo por un error de red; nunca lleva al bloqueo
+	scoreViolation = -30 // Castigo por violar el protocolo (bloques o cadenas inválidas)
+	scoreAfterBan  = 25  // Puntaje con el que vuelve un peer al terminar su bloqueo
+
+	peerMaxConsecutiveFailures = 3              // Errores seguidos antes de marcar el peer inactivo
+	peerMaxBanDuration         = 24 * time.Hour // Tope de los bloqueos repetidos
+)
+
+// PeerScore es la reputación de un peer según su comportamiento observado
+type PeerScore struct {
+	Score               int        `json:"score"` // 0 a 100; en 0 el peer se bloquea
+	Successes           int        `json:"successes"`
+	Failures            int        `json:"failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Violations          int        `json:"violations"`
+	LastViolation       string     `json:"last_violation,omitempty"`
+	LatencyMs           int64      `json:"latency_ms"` // Promedio móvil de las respuestas
+	Bans                int        `json:"bans"`
+	BannedUntil         *time.Time `json:"banned_until,omitempty"`
+	Banned              bool       `json:"banned"`
+}
+
+// reputation lleva los puntajes de los peers. Sobrevive a la salida y regreso
+// de un peer de la lista, así un peer bloqueado no se libra al ser redescubierto.
+type reputation struct {
+	scores      map[string]*PeerScore
+	banDuration time.Duration
+	mutex       sync.Mutex
+}
+
+func newReputation() *reputation {
+	return &reputation{
+		scores:      make(map[string]*PeerScore),
+		banDuration: 30 * time.Minute,
+	}
+}
+
+// score retorna el puntaje de un peer, creándolo si no existe. Requiere el lock.
+func (r *reputation) score(peerID string) *PeerScore {
+	score, ok := r.scores[peerID]
+	if !ok {
+		score = &PeerScore{Score: scoreInitial}
+		r.scores[peerID] = score
+	}
+	if score.Banned && !config.GetColombianTime().Before(*score.BannedUntil) {
+		score.Banned = false
+		score.BannedUntil = nil
+		score.Score = scoreAfterBan
+		fmt.Printf("🔓 Termina el bloqueo del peer %s\n", peerID)
+	}
+	return score
+}
+
+// success registra una respuesta correcta y su latencia
+func (r *reputation) success(peerID string, latency time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	score := r.score(peerID)
+	score.Successes++
+	score.ConsecutiveFailures = 0
+	score.Score = min(score.Score+scoreSuccess, scoreMax)
+	ms := latency.Milliseconds()
+	if score.LatencyMs == 0 {
+		score.LatencyMs = ms
+	} else {
+		score.LatencyMs = (3*score.LatencyMs + ms) / 4
+	}
+}
+
+// failure registra un error de red y retorna cuántos van seguidos
+func (r *reputation) failure(peerID string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	score := r.score(peerID)
+	score.Failures++
+	score.ConsecutiveFailures++
+	score.Score = max(score.Score+scoreFailure, 1)
+	return score.ConsecutiveFailures
+}
+
+// violation registra una violación del protocolo y bloquea al peer si su
+// puntaje se agota. Retorna si quedó bloqueado.
+func (r *reputation) violation(peerID, reason string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	score := r.score(peerID)
+	score.Violations++
+	score.LastViolation = reason
+	score.Score = max(score.Score+scoreViolation, 0)
+	fmt.Printf("🚨 Peer %s violó el protocolo: %s (puntaje %d)\n", peerID, reason, score.Score)
+	if score.Score > 0 || score.Banned {
+		return score.Banned
+	}
+
+	// Cada bloqueo repetido dura el doble
+	duration := r.banDuration << uint(min(score.Bans, 10))
+	if duration > peerMaxBanDuration {
+		duration = peerMaxBanDuration
+	}
+	score.Bans++
+	score.Banned = true
+	until := config.GetColombianTime().Add(duration)
+	score.BannedUntil = &until
+	fmt.Printf("⛔ Peer %s bloqueado por %s\n", peerID, duration)
+	return true
+}
+
+// banned indica si el peer está bloqueado
+func (r *reputation) banned(peerID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.scores[peerID]; !ok {
+		return false
+	}
+	return r.score(peerID).Banned
+}
+
+// unban levanta el bloqueo de un peer
+func (r *reputation) unban(peerID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	score, ok := r.scores[peerID]
+	if !ok || !score.Banned {
+		return false
+	}
+	score.Banned = false
+	score.BannedUntil = nil
+	score.Score = scoreAfterBan
+	return true
+}
+
+// snapshot retorna una copia del puntaje de un peer
+func (r *reputation) snapshot(peerID string) PeerScore {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return *r.score(peerID)
+}
+
+// rank ordena los peers de mejor a peor reputación, a igual puntaje por menor
+// latencia, y descarta los bloqueados
+func (r *reputation) rank(peers []Peer) []Peer {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ranked := make([]Peer, 0, len(peers))
+	for _, peer := range peers {
+		if !r.score(peer.ID).Banned {
+			ranked = append(ranked, peer)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := r.scores[ranked[i].ID], r.scores[ranked[j].ID]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.LatencyMs < b.LatencyMs
+	})
+	return ranked
+}
+
+// ConfigureReputation sets how long a peer is banned the first time it
+// exhausts its score; repeated bans double it
+func (p2p *P2PNetwork) ConfigureReputation(banDuration time.Duration) {
+	if banDuration > 0 {
+		p2p.reputation.banDuration = banDuration
+	}
+}
+
+// ReportViolation penalizes a peer for breaking the protocol, such as sending
+// a block whose hash does not match its contents
+func (p2p *P2PNetwork) ReportViolation(peerID, reason string) {
+	if p2p.reputation.violation(peerID, reason) {
+		p2p.mutex.Lock()
+		if peer, exists := p2p.Peers[peerID]; exists {
+			peer.Active = false
+		}
+		p2p.mutex.Unlock()
+	}
+}
+
+// IsBanned reports whether a peer is temporarily banned
+func (p2p *P2PNetwork) IsBanned(peerID string) bool {
+	return p2p.reputation.banned(peerID)
+}
+
+// Unban lifts a peer's ban before it expires
+func (p2p *P2PNetwork) Unban(peerID string) error {
+	if !p2p.reputation.unban(peerID) {
+		return fmt.Errorf("peer %s no está bloqueado", peerID)
+	}
+	fmt.Printf("🔓 Bloqueo del peer %s levantado manualmente\n", peerID)
+	return nil
+}
+
+// recordSuccess registra una respuesta correcta de un peer
+func (p2p *P2PNetwork) recordSuccess(peerID string, latency time.Duration) {
+	p2p.reputation.success(peerID, latency)
+}
+
+// recordFailure registra un error de red; tras varios seguidos el peer se
+// marca inactivo hasta que vuelva a responder
+func (p2p *P2PNetwork) recordFailure(peerID string) {
+	if p2p.reputation.failure(peerID) >= peerMaxConsecutiveFailures {
+		p2p.markPeerInactive(peerID)
+	}
+}
+
+// rankedPeers retorna los peers activos no bloqueados, los mejores primero
+func (p2p *P2PNetwork) rankedPeers() []Peer {
+	p2p.mutex.RLock()
+	peers := make([]Peer, 0, len(p2p.Peers))
+	for _, peer := range p2p.Peers {
+		if peer.Active && peer.Port != "" {
+			peers = append(peers, *peer)
+		}
+	}
+	p2p.mutex.RUnlock()
+
+	return p2p.reputation.rank(peers)
+}
+
+// KnownSender reports whether a request claiming to come from peerID really
+// comes from that peer's address, so a forged header cannot get another
+// peer penalized
+func (p2p *P2PNetwork) KnownSender(peerID, remoteIP string) bool {
+	p2p.mutex.RLock()
+	peer, exists := p2p.Peers[peerID]
+	var address string
+	if exists {
+		address = peer.Address
+	}
+	p2p.mutex.RUnlock()
+	if !exists || remoteIP == "" {
+		return false
+	}
+	return addressMatches(address, remoteIP)
+}
+
+// BannedSender reports whether a request claiming to come from peerID must be
+// refused. A sender that cannot be matched to its peer's address (no header,
+// an unknown or a forged ID) is refused when the address is a banned peer's.
+func (p2p *P2PNetwork) BannedSender(peerID, remoteIP string) bool {
+	if peerID != "" && p2p.IsBanned(peerID) {
+		return true
+	}
+	if p2p.KnownSender(peerID, remoteIP) || remoteIP == "" {
+		return false
+	}
+
+	p2p.mutex.RLock()
+	addresses := make(map[string]string, len(p2p.Peers))
+	for id, peer := range p2p.Peers {
+		addresses[id] = peer.Address
+	}
+	p2p.mutex.RUnlock()
+	for id, address := range addresses {
+		if p2p.IsBanned(id) && addressMatches(address, remoteIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// addressMatches reports whether a peer address (IP or host name) resolves to remoteIP
+func addressMatches(address, remoteIP string) bool {
+	if address == remoteIP {
+		return true
+	}
+	addrs, err := net.LookupHost(address)
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if addr == remoteIP {
+			return true
+		}
+	}
+	return false
+}
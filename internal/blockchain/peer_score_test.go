@@ -0,0 +1,31 @@
+package blockchain
+
+import "testing"
+
+func TestBannedSender(t *testing.T) {
+	p2p := NewP2PNetwork("n1", "127.0.0.1", "8081", NewBlockchain(), nil, "")
+	p2p.Peers["n2"] = &Peer{ID: "n2", Address: "10.0.0.2", Port: "8082", Active: true}
+	p2p.Peers["n3"] = &Peer{ID: "n3", Address: "10.0.0.3", Port: "8083", Active: true}
+	for !p2p.IsBanned("n2") {
+		p2p.ReportViolation("n2", "bloque inválido")
+	}
+
+	cases := []struct {
+		name     string
+		peerID   string
+		remoteIP string
+		want     bool
+	}{
+		{"banned peer", "n2", "10.0.0.2", true},
+		{"banned peer without node ID", "", "10.0.0.2", true},
+		{"banned peer claiming another ID", "n3", "10.0.0.2", true},
+		{"banned peer with an unknown ID", "n9", "10.0.0.2", true},
+		{"peer in good standing", "n3", "10.0.0.3", false},
+		{"unknown sender without node ID", "", "10.0.0.9", false},
+	}
+	for _, tc := range cases {
+		if got := p2p.BannedSender(tc.peerID, tc.remoteIP); got != tc.want {
+			t.Errorf("%s: BannedSender(%q, %q) = %v, want %v", tc.name, tc.peerID, tc.remoteIP, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/money"
+	"secop-blockchain/internal/sanctions"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tipos de bloque del registro de proveedores
+const (
+	BlockTypeSupplierRegistered = "SUPPLIER_REGISTERED"
+	BlockTypeSupplierUpdated    = "SUPPLIER_UPDATED"
+	BlockTypeContractAwarded    = "CONTRACT_AWARDED"
+)
+
+// Tipos de persona del proveedor
+const (
+	PersonNatural  = "NATURAL"
+	PersonJuridica = "JURIDICA"
+)
+
+// nitWeights son los factores de la DIAN para el dígito de verificación, de derecha a izquierda
+var nitWeights = []int{3, 7, 13, 17, 19, 23, 29, 37, 41, 43, 47, 53, 59, 67, 71}
+
+// rupExemptModalities no exigen inscripción en el RUP (Decreto 1082 de 2015, art. 2.2.1.1.1.5.2)
+var rupExemptModalities = map[string]bool{
+	"CONTRATACION_DIRECTA": true,
+	"MINIMA_CUANTIA":       true,
+}
+
+// LegalRepresentative identifica al representante legal del proveedor
+type LegalRepresentative struct {
+	Name           string `json:"name"`
+	DocumentType   string `json:"document_type"` // CC, CE, PASAPORTE
+	DocumentNumber string `json:"document_number"`
+}
+
+// RUP resume la inscripción del proveedor en el Registro Único de Proponentes
+type RUP struct {
+	Number           string        `json:"number"`
+	Chamber          string        `json:"chamber"` // Cámara de comercio que expide el certificado
+	RenewedAt        time.Time     `json:"renewed_at"`
+	ExpiresAt        time.Time     `json:"expires_at"`
+	UNSPSCCodes      []string      `json:"unspsc_codes"`      // Clasificación de bienes y servicios
+	ResidualCapacity money.Decimal `json:"residual_capacity"` // Capacidad residual de contratación en pesos
+}
+
+// Supplier es un proveedor registrado en la cadena
+type Supplier struct {
+	NIT                 string              `json:"nit"` // Sin dígito de verificación
+	CheckDigit          int                 `json:"check_digit"`
+	Name                string              `json:"name"`
+	PersonType          string              `json:"person_type"`
+	LegalRepresentative LegalRepresentative `json:"legal_representative"`
+	RUP                 *RUP                `json:"rup,omitempty"`
+	Email               string              `json:"email"`
+	City                string              `json:"city"`
+	RegisteredBy        string              `json:"registered_by"`
+	RegisteredAt        time.Time           `json:"registered_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+	BlockHash           string              `json:"block_hash"`
+}
+
+// FormattedNIT retorna el NIT con su dígito de verificación
+func (s *Supplier) FormattedNIT() string {
+	return fmt.Sprintf("%s-%d", s.NIT, s.CheckDigit)
+}
+
+// RUPValid indica si el RUP del proveedor está vigente en una fecha
+func (s *Supplier) RUPValid(at time.Time) bool {
+	return s.RUP != nil && s.RUP.Number != "" && !at.After(s.RUP.ExpiresAt)
+}
+
+// SupplierCheck es el resultado de verificar la habilitación de un proveedor
+type SupplierCheck struct {
+	Supplier  *Supplier          `json:"supplier"`
+	RUPValid  bool               `json:"rup_valid"`
+	Sanctions []sanctions.Record `json:"sanctions"`
+	Eligible  bool               `json:"eligible"`
+}
+
+// supplierRegistry mantiene los proveedores derivados de los bloques
+type supplierRegistry struct {
+	suppliers map[string]*Supplier
+	mutex     sync.RWMutex
+	ops       sync.Mutex // Serializa validación y registro
+}
+
+func newSupplierRegistry() *supplierRegistry {
+	return &supplierRegistry{suppliers: make(map[string]*Supplier)}
+}
+
+// ConfigureSanctions establece la fuente de sanciones e inhabilidades
+func (bc *Blockchain) ConfigureSanctions(checker sanctions.Checker) {
+	bc.sanctions = checker
+}
+
+// ParseNIT valida un NIT con dígito de verificación ("900.123.456-8") y
+// retorna sus dígitos y el dígito de verificación
+func ParseNIT(value string) (string, int, error) {
+	value = strings.TrimSpace(value)
+	base, dv, hasDV := strings.Cut(value, "-")
+	if !hasDV {
+		return "", 0, fmt.Errorf("NIT %q sin dígito de verificación", value)
+	}
+
+	nit := strings.NewReplacer(".", "", " ", "", ",", "").Replace(base)
+	if nit == "" || len(nit) > len(nitWeights) {
+		return "", 0, fmt.Errorf("NIT inválido: %s", value)
+	}
+	for _, r := range nit {
+		if r < '0' || r > '9' {
+			return "", 0, fmt.Errorf("NIT inválido: %s", value)
+		}
+	}
+
+	checkDigit, err := strconv.Atoi(strings.TrimSpace(dv))
+	if err != nil || checkDigit < 0 || checkDigit > 9 {
+		return "", 0, fmt.Errorf("dígito de verificación inválido: %s", value)
+	}
+	if expected := NITCheckDigit(nit); checkDigit != expected {
+		return "", 0, fmt.Errorf("dígito de verificación incorrecto para el NIT %s: se esperaba %d", nit, expected)
+	}
+	return nit, checkDigit, nil
+}
+
+// NITCheckDigit calcula el dígito de verificación de la DIAN
+func NITCheckDigit(nit string) int {
+	sum := 0
+	for i := 0; i < len(nit); i++ {
+		digit := int(nit[len(nit)-1-i] - '0')
+		sum += digit * nitWeights[i]
+	}
+	remainder := sum % 11
+	if remainder > 1 {
+		return 11 - remainder
+	}
+	return remainder
+}
+
+// RegisterSupplier registra un proveedor en la cadena. El NIT se recibe con
+// dígito de verificación.
+func (bc *Blockchain) RegisterSupplier(supplier *Supplier, nit string) error {
+	bc.suppliers.ops.Lock()
+	defer bc.suppliers.ops.Unlock()
+
+	number, checkDigit, err := ParseNIT(nit)
+	if err != nil {
+		return err
+	}
+	if bc.GetSupplier(number) != nil {
+		return fmt.Errorf("el proveedor con NIT %s-%d ya está registrado", number, checkDigit)
+	}
+	supplier.NIT = number
+	supplier.CheckDigit = checkDigit
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+
+	supplier.RegisteredAt = config.GetColombianTime()
+	if _, err := bc.AddBlock(supplierBlockData(BlockTypeSupplierRegistered, supplier)); err != nil {
+		return err
+	}
+
+	*supplier = *bc.GetSupplier(number)
+	return nil
+}
+
+// UpdateSupplier actualiza los datos de un proveedor registrado (representante, RUP, contacto)
+func (bc *Blockchain) UpdateSupplier(nit string, update *Supplier) error {
+	bc.suppliers.ops.Lock()
+	defer bc.suppliers.ops.Unlock()
+
+	existing := bc.GetSupplier(nit)
+	if existing == nil {
+		return errors.New("proveedor no encontrado")
+	}
+	update.NIT = existing.NIT
+	update.CheckDigit = existing.CheckDigit
+	update.RegisteredAt = existing.RegisteredAt
+	if update.PersonType == "" {
+		update.PersonType = existing.PersonType
+	}
+	if update.Name == "" {
+		update.Name = existing.Name
+	}
+	if err := validateSupplier(update); err != nil {
+		return err
+	}
+
+	if _, err := bc.AddBlock(supplierBlockData(BlockTypeSupplierUpdated, update)); err != nil {
+		return err
+	}
+
+	*update = *bc.GetSupplier(existing.NIT)
+	return nil
+}
+
+// validateSupplier verifica los campos obligatorios del proveedor
+func validateSupplier(supplier *Supplier) error {
+	if strings.TrimSpace(supplier.Name) == "" {
+		return errors.New("razón social requerida")
+	}
+	supplier.PersonType = strings.ToUpper(strings.TrimSpace(supplier.PersonType))
+	if supplier.PersonType == "" {
+		supplier.PersonType = PersonJuridica
+	}
+	if supplier.PersonType != PersonNatural && supplier.PersonType != PersonJuridica {
+		return fmt.Errorf("tipo de persona inválido: %s", supplier.PersonType)
+	}
+	if supplier.PersonType == PersonJuridica {
+		if supplier.LegalRepresentative.Name == "" || supplier.LegalRepresentative.DocumentNumber == "" {
+			return errors.New("representante legal requerido para personas jurídicas")
+		}
+	}
+	if supplier.RUP != nil {
+		if supplier.RUP.Number == "" {
+			return errors.New("número de RUP requerido")
+		}
+		if supplier.RUP.ExpiresAt.IsZero() {
+			return errors.New("fecha de vencimiento del RUP requerida")
+		}
+		if supplier.RUP.ResidualCapacity.LessThan(money.Zero) {
+			return errors.New("la capacidad residual no puede ser negativa")
+		}
+	}
+	return nil
+}
+
+// supplierBlockData construye los datos del bloque de registro o actualización
+func supplierBlockData(blockType string, supplier *Supplier) map[string]interface{} {
+	data := map[string]interface{}{
+		"type":                   blockType,
+		"nit":                    supplier.NIT,
+		"check_digit":            supplier.CheckDigit,
+		"name":                   supplier.Name,
+		"person_type":            supplier.PersonType,
+		"representative_name":    supplier.LegalRepresentative.Name,
+		"representative_doctype": supplier.LegalRepresentative.DocumentType,
+		"representative_number":  supplier.LegalRepresentative.DocumentNumber,
+		"email":                  supplier.Email,
+		"city":                   supplier.City,
+		"registered_by":          supplier.RegisteredBy,
+		"timestamp":              config.GetColombianTime(),
+	}
+	if supplier.RUP != nil {
+		codes := make([]interface{}, len(supplier.RUP.UNSPSCCodes))
+		for i, code := range supplier.RUP.UNSPSCCodes {
+			codes[i] = code
+		}
+		data["rup_number"] = supplier.RUP.Number
+		data["rup_chamber"] = supplier.RUP.Chamber
+		data["rup_renewed_at"] = supplier.RUP.RenewedAt
+		data["rup_expires_at"] = supplier.RUP.ExpiresAt
+		data["rup_unspsc_codes"] = codes
+		data["rup_residual_capacity"] = supplier.RUP.ResidualCapacity.String()
+	}
+	return data
+}
+
+// GetSupplier obtiene un proveedor por NIT (con o sin dígito de verificación)
+func (bc *Blockchain) GetSupplier(nit string) *Supplier {
+	bc.suppliers.mutex.RLock()
+	defer bc.suppliers.mutex.RUnlock()
+
+	supplier, exists := bc.suppliers.suppliers[sanctions.NormalizeDocument(nit)]
+	if !exists {
+		return nil
+	}
+	return supplierView(supplier)
+}
+
+// GetSuppliers lista los proveedores, opcionalmente filtrados por nombre o NIT
+func (bc *Blockchain) GetSuppliers(query string) []*Supplier {
+	bc.suppliers.mutex.RLock()
+	defer bc.suppliers.mutex.RUnlock()
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	result := make([]*Supplier, 0)
+	for _, supplier := range bc.suppliers.suppliers {
+		if query != "" && !strings.Contains(strings.ToLower(supplier.Name), query) && !strings.HasPrefix(supplier.NIT, query) {
+			continue
+		}
+		result = append(result, supplierView(supplier))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// CheckSupplier verifica el RUP y las sanciones vigentes del proveedor y de su representante legal
+func (bc *Blockchain) CheckSupplier(nit string) (*SupplierCheck, error) {
+	supplier := bc.GetSupplier(nit)
+	if supplier == nil {
+		return nil, errors.New("proveedor no encontrado")
+	}
+
+	check := &SupplierCheck{
+		Supplier:  supplier,
+		RUPValid:  supplier.RUPValid(config.GetColombianTime()),
+		Sanctions: make([]sanctions.Record, 0),
+	}
+	documents := []string{supplier.NIT}
+	if supplier.LegalRepresentative.DocumentNumber != "" {
+		documents = append(documents, supplier.LegalRepresentative.DocumentNumber)
+	}
+	for _, document := range documents {
+		records, err := bc.sanctions.Check(document)
+		if err != nil {
+			return nil, fmt.Errorf("no fue posible consultar sanciones: %v", err)
+		}
+		check.Sanctions = append(check.Sanctions, records...)
+	}
+	check.Eligible = len(check.Sanctions) == 0
+	return check, nil
+}
+
+// checkAward verifica que un proveedor pueda ser adjudicatario de un contrato
+func (bc *Blockchain) checkAward(contract *Contract, nit string) (*Supplier, error) {
+	check, err := bc.CheckSupplier(nit)
+	if err != nil {
+		return nil, err
+	}
+	supplier := check.Supplier
+	if len(check.Sanctions) > 0 {
+		sanction := check.Sanctions[0]
+		return nil, fmt.Errorf("el proveedor %s registra sanción vigente (%s, %s): %s", supplier.FormattedNIT(), sanction.Type, sanction.Source, sanction.Reason)
+	}
+	if !rupExemptModalities[contract.Modality] {
+		if !check.RUPValid {
+			return nil, fmt.Errorf("el proveedor %s no tiene RUP vigente, exigido para la modalidad %s", supplier.FormattedNIT(), contract.Modality)
+		}
+		value := bc.contractValue(contract)
+		if supplier.RUP.ResidualCapacity.IsPositive() && value.GreaterThan(supplier.RUP.ResidualCapacity) {
+			return nil, fmt.Errorf("el valor del contrato (%s COP) supera la capacidad residual del proveedor (%s COP)", value, supplier.RUP.ResidualCapacity)
+		}
+	}
+	return supplier, nil
+}
+
+// supplierView retorna una copia del proveedor
+func supplierView(supplier *Supplier) *Supplier {
+	view := *supplier
+	if supplier.RUP != nil {
+		rup := *supplier.RUP
+		rup.UNSPSCCodes = append([]string{}, supplier.RUP.UNSPSCCodes...)
+		view.RUP = &rup
+	}
+	return &view
+}
+
+// apply actualiza el registro con un bloque de proveedor. Se usa tanto para
+// bloques nuevos como al reconstruir desde la cadena.
+func (r *supplierRegistry) apply(block *Block) {
+	if block.Type != BlockTypeSupplierRegistered && block.Type != BlockTypeSupplierUpdated {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	data := block.Data
+	nit := dataString(data, "nit")
+	existing, exists := r.suppliers[nit]
+	if block.Type == BlockTypeSupplierUpdated && !exists {
+		return
+	}
+
+	timestamp := dataTime(data, "timestamp", block.Timestamp)
+	supplier := &Supplier{
+		NIT:        nit,
+		CheckDigit: int(dataFloat(data, "check_digit")),
+		Name:       dataString(data, "name"),
+		PersonType: dataString(data, "person_type"),
+		LegalRepresentative: LegalRepresentative{
+			Name:           dataString(data, "representative_name"),
+			DocumentType:   dataString(data, "representative_doctype"),
+			DocumentNumber: dataString(data, "representative_number"),
+		},
+		Email:        dataString(data, "email"),
+		City:         dataString(data, "city"),
+		RegisteredBy: dataString(data, "registered_by"),
+		RegisteredAt: timestamp,
+		UpdatedAt:    timestamp,
+		BlockHash:    block.Hash,
+	}
+	if exists {
+		supplier.RegisteredBy = existing.RegisteredBy
+		supplier.RegisteredAt = existing.RegisteredAt
+	}
+	if number := dataString(data, "rup_number"); number != "" {
+		supplier.RUP = &RUP{
+			Number:           number,
+			Chamber:          dataString(data, "rup_chamber"),
+			RenewedAt:        dataTime(data, "rup_renewed_at", time.Time{}),
+			ExpiresAt:        dataTime(data, "rup_expires_at", time.Time{}),
+			UNSPSCCodes:      dataStrings(data, "rup_unspsc_codes"),
+			ResidualCapacity: dataDecimal(data, "rup_residual_capacity"),
+		}
+	}
+	r.suppliers[nit] = supplier
+}
+
+func (r *supplierRegistry) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.suppliers = make(map[string]*Supplier)
+}
+
+// rebuildSupplierRegistry reconstruye el registro de proveedores desde la cadena
+func (bc *Blockchain) rebuildSupplierRegistry() {
+	bc.suppliers.reset()
+	for _, block := range bc.Chain {
+		bc.suppliers.apply(block)
+	}
+}
+
+// dataStrings lee una lista de textos de los datos de un bloque
+func dataStrings(data map[string]interface{}, key string) []string {
+	switch values := data[key].(type) {
+	case []string:
+		return append([]string{}, values...)
+	case []interface{}:
+		result := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
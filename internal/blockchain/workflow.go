@@ -3,34 +3,97 @@ package blockchain
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+	"secop-blockchain/internal/calendar"
 	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/modality"
+	"secop-blockchain/internal/money"
 
 	"github.com/google/uuid"
 )
 
 // WorkflowManager maneja el flujo de validación de contratos
 type WorkflowManager struct {
-	blockchain *Blockchain
+	blockchain      *Blockchain
+	listeners       []WorkflowListener
+	calendar        BusinessCalendar
+	stepDays        map[AdminRole]int
+	defaultStepDays int
+	auditResponseDays int
+	auditAuthority  AuditAuthority
 }
 
+// WorkflowEvent describe una entrada de auditoría ya registrada en la cadena
+type WorkflowEvent struct {
+	Action   string
+	Contract *Contract
+	Entry    AuditEntry
+	NextRole AdminRole
+}
+
+// WorkflowListener es notificado por cada entrada de auditoría del flujo. Se
+// invoca con el mutex de la cadena tomado, así que no debe consultar la cadena.
+type WorkflowListener func(event WorkflowEvent)
+
 // NewWorkflowManager crea un nuevo gestor de flujo de trabajo
 func NewWorkflowManager(bc *Blockchain) *WorkflowManager {
 	return &WorkflowManager{
 		blockchain: bc,
+		calendar:   calendar.New(nil),
 	}
 }
 
-// GetWorkflowSteps define los pasos del flujo de trabajo SECOP
+// GetWorkflowSteps define los pasos del flujo de trabajo SECOP estándar
 func (wm *WorkflowManager) GetWorkflowSteps() []WorkflowStep {
-	return []WorkflowStep{
-		{StepNumber: 1, Role: RoleProjectDeveloper, Name: "Creación del Proyecto", Required: true},
-		{StepNumber: 2, Role: RoleTechnicalCommission, Name: "Revisión Técnica", Required: true},
-		{StepNumber: 3, Role: RoleLegalCommission, Name: "Revisión Jurídica", Required: true},
-		{StepNumber: 4, Role: RoleContractsChief, Name: "Aprobación Jefe de Contratos", Required: true},
-		{StepNumber: 5, Role: RoleAdminChief, Name: "Aprobación Jefe Administrativo", Required: true},
-		{StepNumber: 6, Role: RoleBudgetAuthority, Name: "Autorización Ordenador del Gasto", Required: true},
+	return wm.templateSteps(modality.DefaultWorkflow)
+}
+
+// GetWorkflowStepsFor retorna los pasos que aplican a un contrato según su tipo y monto
+func (wm *WorkflowManager) GetWorkflowStepsFor(contractType string, amount money.Decimal, currency money.Currency) ([]WorkflowStep, error) {
+	decision, err := wm.blockchain.EvaluateModality(contractType, amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	value, err := wm.blockchain.ValueInCOP(amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	return wm.withHigherAuthority(wm.templateSteps(decision.Workflow), value), nil
+}
+
+// templateSteps convierte una plantilla de flujo de las reglas de modalidad en pasos
+func (wm *WorkflowManager) templateSteps(template string) []WorkflowStep {
+	templateSteps, ok := wm.blockchain.modality.Workflow(template)
+	if !ok {
+		templateSteps, _ = wm.blockchain.modality.Workflow(modality.DefaultWorkflow)
+	}
+
+	steps := make([]WorkflowStep, len(templateSteps))
+	for i, step := range templateSteps {
+		steps[i] = WorkflowStep{StepNumber: i + 1, Role: AdminRole(step.Role), Name: step.Name, Required: true}
+	}
+	return steps
+}
+
+// withHigherAuthority agrega la aprobación del comité de contratación antes de la
+// autorización del ordenador del gasto cuando el valor supera el umbral de la entidad
+func (wm *WorkflowManager) withHigherAuthority(steps []WorkflowStep, value money.Decimal) []WorkflowStep {
+	if !wm.blockchain.requiresHigherAuthority(value) {
+		return steps
 	}
+
+	result := make([]WorkflowStep, 0, len(steps)+1)
+	for _, step := range steps {
+		if step.Role == RoleBudgetAuthority {
+			result = append(result, WorkflowStep{Role: RoleContractingCommittee, Name: "Aprobación Comité de Contratación", Required: true})
+		}
+		result = append(result, step)
+	}
+	for i := range result {
+		result[i].StepNumber = i + 1
+	}
+	return result
 }
 
 // WorkflowStep representa un paso en el flujo de trabajo
@@ -43,7 +106,7 @@ type WorkflowStep struct {
 
 // InitializeContractWorkflow inicializa el flujo de trabajo para un contrato
 func (wm *WorkflowManager) InitializeContractWorkflow(contract *Contract) error {
-	steps := wm.GetWorkflowSteps()
+	steps := wm.withHigherAuthority(wm.templateSteps(contract.WorkflowTemplate), wm.blockchain.contractValue(contract))
 	contract.ValidationSteps = make([]ValidationStep, len(steps))
 	
 	for i, step := range steps {
@@ -59,6 +122,7 @@ func (wm *WorkflowManager) InitializeContractWorkflow(contract *Contract) error
 	contract.CurrentStep = 1
 	contract.Status = StatusDraft
 	contract.UpdatedAt = config.GetColombianTime()
+	wm.startStep(contract, 1, contract.CreatedAt)
 	
 	// Registrar en auditoría
 	wm.addAuditEntry(contract, "WORKFLOW_INITIALIZED", contract.CreatedBy, RoleProjectDeveloper, "Flujo de trabajo inicializado")
@@ -68,6 +132,9 @@ func (wm *WorkflowManager) InitializeContractWorkflow(contract *Contract) error
 
 // ValidateStep valida un paso específico del flujo de trabajo
 func (wm *WorkflowManager) ValidateStep(contractID string, stepNumber int, validatorID string, validatorName string, role AdminRole, approved bool, comments string) error {
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+	
 	contract, exists := wm.blockchain.Contracts[contractID]
 	if !exists {
 		return errors.New("contrato no encontrado")
@@ -86,16 +153,44 @@ func (wm *WorkflowManager) ValidateStep(contractID string, stepNumber int, valid
 	// Obtener el paso actual
 	step := &contract.ValidationSteps[stepNumber-1]
 	
-	// Actualizar el paso
+	// Verificar que el rol corresponde al paso
+	if role != step.Role {
+		return fmt.Errorf("el paso %d corresponde al rol %s, no a %s", stepNumber, step.Role, role)
+	}
+	
+	// Verificar los límites de la entidad antes de aprobar
+	if approved {
+		if err := wm.blockchain.checkContractValue(contract); err != nil {
+			return err
+		}
+		if step.Role == RoleBudgetAuthority {
+			if !wm.blockchain.limits.BudgetAuthority {
+				return errors.New("la entidad no tiene autoridad presupuestal para autorizar como ordenador del gasto")
+			}
+			if err := wm.blockchain.checkBudgetCoverage(contract); err != nil {
+				return err
+			}
+		}
+		if stepNumber == len(contract.ValidationSteps) {
+			if missing := missingDocuments(contract); len(missing) > 0 {
+				return fmt.Errorf("faltan documentos requeridos para la modalidad %s: %v", contract.Modality, missing)
+			}
+		}
+	}
+	
+	// Actualizar el paso con la misma fecha del bloque, para que los nodos que
+	// reproducen la cadena obtengan los mismos tiempos
+	now := config.GetColombianTime()
 	step.ValidatorID = validatorID
 	step.ValidatorName = validatorName
-	step.Timestamp = config.GetColombianTime()
+	step.Timestamp = now
 	step.Comments = comments
 	
 	if approved {
 		step.Status = ValidationApproved
 		contract.CurrentStep++
-		contract.Status = wm.getStatusForStep(contract.CurrentStep)
+		contract.Status = wm.getStatusForStep(contract)
+		wm.startStep(contract, contract.CurrentStep, step.Timestamp)
 		wm.addAuditEntry(contract, "STEP_APPROVED", validatorID, role, fmt.Sprintf("Paso %d aprobado: %s", stepNumber, comments))
 	} else {
 		step.Status = ValidationRejected
@@ -103,22 +198,26 @@ func (wm *WorkflowManager) ValidateStep(contractID string, stepNumber int, valid
 		wm.addAuditEntry(contract, "STEP_REJECTED", validatorID, role, fmt.Sprintf("Paso %d rechazado: %s", stepNumber, comments))
 	}
 	
-	contract.UpdatedAt = config.GetColombianTime()
+	contract.UpdatedAt = now
+	wm.blockchain.reindexContract(contract)
 	
 	// Crear bloque para registrar la validación
 	blockData := map[string]interface{}{
-		"type":        "VALIDATION",
-		"contract_id": contractID,
-		"step":        stepNumber,
-		"validator":   validatorID,
-		"role":        string(role),
-		"approved":    approved,
-		"comments":    comments,
-		"timestamp":   config.GetColombianTime(),
+		"type":           "VALIDATION",
+		"contract_id":    contractID,
+		"step":           stepNumber,
+		"validator":      validatorID,
+		"validator_name": validatorName,
+		"role":           string(role),
+		"approved":       approved,
+		"comments":       comments,
+		"status":         string(contract.Status),
+		"next_role":      string(wm.getNextRole(contract)),
+		"timestamp":      now,
 	}
 	
-	// Agregar bloque y obtener hash
-	block, err := wm.blockchain.AddBlock(blockData)
+	// Agregar bloque con la fecha del paso y obtener hash
+	block, err := wm.blockchain.addBlockAt(blockData, now)
 	if err != nil {
 		return err
 	}
@@ -128,40 +227,84 @@ func (wm *WorkflowManager) ValidateStep(contractID string, stepNumber int, valid
 		contract.AuditTrail[len(contract.AuditTrail)-1].BlockHash = block.Hash
 	}
 
+	wm.notifyListeners(contract)
 	return nil
 }
 
-// getStatusForStep retorna el estado correspondiente al paso actual
-func (wm *WorkflowManager) getStatusForStep(stepNumber int) ContractStatus {
-	switch stepNumber {
-	case 1:
+// getStatusForStep retorna el estado correspondiente al paso actual según su rol
+func (wm *WorkflowManager) getStatusForStep(contract *Contract) ContractStatus {
+	if contract.CurrentStep < 1 || contract.CurrentStep > len(contract.ValidationSteps) {
+		return StatusAuthorizedForPublication
+	}
+	switch contract.ValidationSteps[contract.CurrentStep-1].Role {
+	case RoleProjectDeveloper:
 		return StatusDraft
-	case 2:
+	case RoleTechnicalCommission:
 		return StatusTechnicalReview
-	case 3:
+	case RoleLegalCommission:
 		return StatusLegalReview
-	case 4:
+	case RoleContractsChief:
 		return StatusContractsReview
-	case 5:
+	case RoleAdminChief:
 		return StatusAdminReview
-	case 6:
+	case RoleContractingCommittee:
+		return StatusCommitteeReview
+	case RoleBudgetAuthority:
 		return StatusBudgetReview
 	default:
 		return StatusAuthorizedForPublication
 	}
 }
 
-// AddAuditObservation agrega una observación de auditoría (control externo)
+// AddAuditObservation agrega una observación de auditoría (control externo).
+// Solo la ejercen órganos de control desde un nodo CONTROL con jurisdicción sobre la entidad.
 func (wm *WorkflowManager) AddAuditObservation(contractID string, auditorID string, role AdminRole, observation string) error {
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+	
 	contract, exists := wm.blockchain.Contracts[contractID]
 	if !exists {
 		return errors.New("contrato no encontrado")
 	}
 	
-	// Verificar que es un rol de control externo
-	if role != RoleComptroller && role != RoleProsecutor && role != RoleCitizen {
+	// Los ciudadanos observan por el canal de identidad verificada
+	if role == RoleCitizen {
+		return errors.New("las observaciones ciudadanas se registran por el canal de identidad verificada")
+	}
+	
+	// Verificar que es un rol de control externo con jurisdicción sobre el contrato
+	if !isControlRole(role) {
 		return errors.New("rol no autorizado para auditoría")
 	}
+	if err := wm.checkAuditAuthority(contract, role); err != nil {
+		return err
+	}
+	
+	return wm.recordObservation(contract, auditorID, role, observation, ObservationChannelControl)
+}
+
+// AddCitizenObservation registra una observación ciudadana recibida por el canal
+// verificado. El ciudadano se identifica con un seudónimo, nunca con su documento.
+func (wm *WorkflowManager) AddCitizenObservation(contractID string, pseudonym string, observation string) error {
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+	
+	contract, exists := wm.blockchain.Contracts[contractID]
+	if !exists {
+		return errors.New("contrato no encontrado")
+	}
+	if pseudonym == "" {
+		return errors.New("identidad ciudadana verificada requerida")
+	}
+	
+	return wm.recordObservation(contract, pseudonym, RoleCitizen, observation, ObservationChannelCitizen)
+}
+
+// recordObservation agrega la observación al audit trail y la registra en un bloque
+func (wm *WorkflowManager) recordObservation(contract *Contract, auditorID string, role AdminRole, observation string, channel string) error {
+	if strings.TrimSpace(observation) == "" {
+		return errors.New("observación requerida")
+	}
 	
 	// Agregar observación de auditoría
 	auditEntry := AuditEntry{
@@ -179,15 +322,16 @@ func (wm *WorkflowManager) AddAuditObservation(contractID string, auditorID stri
 	// Crear bloque para registrar la observación de auditoría
 	blockData := map[string]interface{}{
 		"type":        "AUDIT_OBSERVATION",
-		"contract_id": contractID,
+		"contract_id": contract.ID,
 		"auditor":     auditorID,
 		"role":        string(role),
+		"channel":     channel,
 		"observation": observation,
 		"timestamp":   config.GetColombianTime(),
 	}
 	
 	// Agregar bloque y obtener hash
-	block, err := wm.blockchain.AddBlock(blockData)
+	block, err := wm.blockchain.addBlock(blockData)
 	if err != nil {
 		return err
 	}
@@ -195,9 +339,139 @@ func (wm *WorkflowManager) AddAuditObservation(contractID string, auditorID stri
 	// Actualizar audit trail con block hash
 	contract.AuditTrail[len(contract.AuditTrail)-1].BlockHash = block.Hash
 	
+	wm.notifyListeners(contract)
+	return nil
+}
+
+// AttachDocuments registra los tipos de documento aportados a un contrato
+func (wm *WorkflowManager) AttachDocuments(contractID string, userID string, role AdminRole, documents []string) error {
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+	
+	contract, exists := wm.blockchain.Contracts[contractID]
+	if !exists {
+		return errors.New("contrato no encontrado")
+	}
+	if len(documents) == 0 {
+		return errors.New("documentos requeridos")
+	}
+	
+	var added []string
+	for _, document := range documents {
+		if document != "" && !containsString(contract.Documents, document) && !containsString(added, document) {
+			added = append(added, document)
+		}
+	}
+	if len(added) == 0 {
+		return errors.New("los documentos ya fueron aportados")
+	}
+	
+	contract.Documents = append(contract.Documents, added...)
+	contract.UpdatedAt = config.GetColombianTime()
+	missing := missingDocuments(contract)
+	wm.addAuditEntry(contract, "DOCUMENTS_ATTACHED", userID, role, fmt.Sprintf("Documentos aportados: %v", added))
+	
+	blockData := map[string]interface{}{
+		"type":        "DOCUMENTS_ATTACHED",
+		"contract_id": contractID,
+		"documents":   added,
+		"missing":     missing,
+		"user_id":     userID,
+		"role":        string(role),
+		"timestamp":   config.GetColombianTime(),
+	}
+	
+	block, err := wm.blockchain.addBlock(blockData)
+	if err != nil {
+		return err
+	}
+	contract.AuditTrail[len(contract.AuditTrail)-1].BlockHash = block.Hash
+	
+	wm.blockchain.reindexContract(contract)
+	wm.notifyListeners(contract)
 	return nil
 }
 
+// AwardContract adjudica un contrato autorizado a un proveedor registrado y habilitado
+func (wm *WorkflowManager) AwardContract(contractID string, supplierNIT string, userID string, role AdminRole, proposalsReceived int, comments string) error {
+	wm.blockchain.lock()
+	defer wm.blockchain.unlock()
+	
+	contract, exists := wm.blockchain.Contracts[contractID]
+	if !exists {
+		return errors.New("contrato no encontrado")
+	}
+	
+	// La adjudicación corresponde al ordenador del gasto
+	if role != RoleBudgetAuthority {
+		return fmt.Errorf("la adjudicación corresponde al rol %s, no a %s", RoleBudgetAuthority, role)
+	}
+	switch contract.Status {
+	case StatusAuthorizedForPublication, StatusPublished, StatusProposalsReceived, StatusEvaluated:
+	case StatusAwarded:
+		return fmt.Errorf("el contrato ya fue adjudicado a %s", contract.SupplierNIT)
+	default:
+		return fmt.Errorf("el contrato no está autorizado para adjudicación (estado %s)", contract.Status)
+	}
+	if proposalsReceived < 0 {
+		return errors.New("número de propuestas inválido")
+	}
+	if proposalsReceived == 0 {
+		if contract.Modality != "CONTRATACION_DIRECTA" {
+			return errors.New("número de propuestas recibidas requerido")
+		}
+		proposalsReceived = 1
+	}
+	
+	supplier, err := wm.blockchain.checkAward(contract, supplierNIT)
+	if err != nil {
+		return err
+	}
+	
+	now := config.GetColombianTime()
+	contract.SupplierNIT = supplier.FormattedNIT()
+	contract.SupplierName = supplier.Name
+	contract.ProposalsReceived = proposalsReceived
+	contract.AwardedAt = now
+	contract.Status = StatusAwarded
+	contract.UpdatedAt = now
+	wm.addAuditEntry(contract, "CONTRACT_AWARDED", userID, role, fmt.Sprintf("Adjudicado a %s (%s) entre %d propuestas: %s", supplier.Name, supplier.FormattedNIT(), proposalsReceived, comments))
+	
+	blockData := map[string]interface{}{
+		"type":               BlockTypeContractAwarded,
+		"contract_id":        contractID,
+		"supplier_nit":       supplier.NIT,
+		"supplier_name":      supplier.Name,
+		"proposals_received": proposalsReceived,
+		"amount":             contract.Amount.String(),
+		"currency":           string(contract.Currency),
+		"awarded_by":         userID,
+		"role":               string(role),
+		"comments":           comments,
+		"status":             string(contract.Status),
+		"timestamp":          now,
+	}
+	
+	block, err := wm.blockchain.addBlock(blockData)
+	if err != nil {
+		return err
+	}
+	contract.AuditTrail[len(contract.AuditTrail)-1].BlockHash = block.Hash
+	
+	wm.blockchain.reindexContract(contract)
+	wm.notifyListeners(contract)
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // addAuditEntry agrega una entrada al registro de auditoría
 func (wm *WorkflowManager) addAuditEntry(contract *Contract, action string, userID string, role AdminRole, description string) {
 	entry := AuditEntry{
@@ -213,8 +487,35 @@ func (wm *WorkflowManager) addAuditEntry(contract *Contract, action string, user
 	contract.AuditTrail = append(contract.AuditTrail, entry)
 }
 
+// AddListener registra una función que se invoca por cada entrada de auditoría
+func (wm *WorkflowManager) AddListener(listener WorkflowListener) {
+	wm.listeners = append(wm.listeners, listener)
+}
+
+// notifyListeners informa la última entrada de auditoría del contrato. Los
+// listeners reciben una copia porque se invocan con el mutex de la cadena tomado
+// y pueden conservar el contrato después.
+func (wm *WorkflowManager) notifyListeners(contract *Contract) {
+	if len(contract.AuditTrail) == 0 {
+		return
+	}
+	event := WorkflowEvent{
+		Contract: contract.clone(),
+		Entry:    contract.AuditTrail[len(contract.AuditTrail)-1],
+		NextRole: wm.getNextRole(contract),
+	}
+	event.Action = event.Entry.Action
+
+	for _, listener := range wm.listeners {
+		listener(event)
+	}
+}
+
 // GetContractWorkflowStatus retorna el estado actual del flujo de trabajo
 func (wm *WorkflowManager) GetContractWorkflowStatus(contractID string) (*WorkflowStatus, error) {
+	wm.blockchain.mutex.RLock()
+	defer wm.blockchain.mutex.RUnlock()
+	
 	contract, exists := wm.blockchain.Contracts[contractID]
 	if !exists {
 		return nil, errors.New("contrato no encontrado")
@@ -251,6 +552,9 @@ type WorkflowStatus struct {
 
 // getNextRole retorna el siguiente rol que debe validar
 func (wm *WorkflowManager) getNextRole(contract *Contract) AdminRole {
+	if contract.Status == StatusRejected {
+		return ""
+	}
 	if contract.CurrentStep <= len(contract.ValidationSteps) {
 		return contract.ValidationSteps[contract.CurrentStep-1].Role
 	}
@@ -259,6 +563,9 @@ func (wm *WorkflowManager) getNextRole(contract *Contract) AdminRole {
 
 // GetWorkflowStatus obtiene el estado actual del flujo de trabajo de un contrato
 func (wm *WorkflowManager) GetWorkflowStatus(contractID string) (map[string]interface{}, error) {
+	wm.blockchain.mutex.RLock()
+	defer wm.blockchain.mutex.RUnlock()
+	
 	contract, exists := wm.blockchain.Contracts[contractID]
 	if !exists {
 		return nil, errors.New("contrato no encontrado")
@@ -283,8 +590,8 @@ func (wm *WorkflowManager) GetWorkflowStatus(contractID string) (map[string]inte
 		"completed_steps":  completedSteps,
 		"progress":         progress,
 		"status":           string(contract.Status),
-		"validation_steps": contract.ValidationSteps,
-		"audit_trail":      contract.AuditTrail,
+		"validation_steps": append([]ValidationStep(nil), contract.ValidationSteps...),
+		"audit_trail":      append([]AuditEntry(nil), contract.AuditTrail...),
 		"created_at":       contract.CreatedAt,
 		"updated_at":       contract.UpdatedAt,
 	}
@@ -0,0 +1,237 @@
+// Package calendar computes Colombian public holidays and business days.
+//
+// Holidays follow Ley 51 de 1983 ("Ley Emiliani"): some religious and civic
+// dates are moved to the following Monday, and several dates depend on Easter.
+package calendar
+
+import (
+	"fmt"
+	"secop-blockchain/internal/config"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dateLayout is the format used for dates in holiday lists and the API
+const dateLayout = "2006-01-02"
+
+// Holiday is a Colombian public holiday
+type Holiday struct {
+	Date  string `json:"date"`
+	Name  string `json:"name"`
+	Moved bool   `json:"moved"` // Trasladado al lunes por la Ley Emiliani
+}
+
+// fixedHolidays are celebrated on their calendar date
+var fixedHolidays = []struct {
+	month time.Month
+	day   int
+	name  string
+}{
+	{time.January, 1, "Año Nuevo"},
+	{time.May, 1, "Día del Trabajo"},
+	{time.July, 20, "Día de la Independencia"},
+	{time.August, 7, "Batalla de Boyacá"},
+	{time.December, 8, "Inmaculada Concepción"},
+	{time.December, 25, "Navidad"},
+}
+
+// emilianiHolidays are moved to the next Monday when they fall on another day
+var emilianiHolidays = []struct {
+	month time.Month
+	day   int
+	name  string
+}{
+	{time.January, 6, "Día de los Reyes Magos"},
+	{time.March, 19, "Día de San José"},
+	{time.June, 29, "San Pedro y San Pablo"},
+	{time.August, 15, "Asunción de la Virgen"},
+	{time.October, 12, "Día de la Raza"},
+	{time.November, 1, "Todos los Santos"},
+	{time.November, 11, "Independencia de Cartagena"},
+}
+
+// easterHolidays are offsets in days from Easter Sunday
+var easterHolidays = []struct {
+	offset int
+	name   string
+	moved  bool
+}{
+	{-3, "Jueves Santo", false},
+	{-2, "Viernes Santo", false},
+	{43, "Ascensión del Señor", true},      // Jueves +39, trasladado al lunes
+	{64, "Corpus Christi", true},           // Jueves +60, trasladado al lunes
+	{71, "Sagrado Corazón de Jesús", true}, // Viernes +68, trasladado al lunes
+}
+
+// Calendar answers business-day questions using Colombian holidays plus
+// optional additional non-working dates
+type Calendar struct {
+	extra map[string]string
+	cache map[int]map[string]Holiday
+	mutex sync.Mutex
+}
+
+// New creates a calendar. Extra dates (YYYY-MM-DD) are treated as non-working days.
+func New(extraDates []string) *Calendar {
+	extra := make(map[string]string, len(extraDates))
+	for _, date := range extraDates {
+		if _, err := time.Parse(dateLayout, date); err == nil {
+			extra[date] = "Día no hábil adicional"
+		}
+	}
+	return &Calendar{
+		extra: extra,
+		cache: make(map[int]map[string]Holiday),
+	}
+}
+
+// Easter returns Easter Sunday of a year (anonymous Gregorian algorithm)
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, config.ColombianTimezone)
+}
+
+// Holidays returns the Colombian public holidays of a year ordered by date
+func Holidays(year int) []Holiday {
+	var holidays []Holiday
+
+	for _, h := range fixedHolidays {
+		date := time.Date(year, h.month, h.day, 0, 0, 0, 0, config.ColombianTimezone)
+		holidays = append(holidays, Holiday{Date: date.Format(dateLayout), Name: h.name})
+	}
+
+	for _, h := range emilianiHolidays {
+		date := time.Date(year, h.month, h.day, 0, 0, 0, 0, config.ColombianTimezone)
+		moved := nextMonday(date)
+		holidays = append(holidays, Holiday{Date: moved.Format(dateLayout), Name: h.name, Moved: !moved.Equal(date)})
+	}
+
+	easter := Easter(year)
+	for _, h := range easterHolidays {
+		date := easter.AddDate(0, 0, h.offset)
+		holidays = append(holidays, Holiday{Date: date.Format(dateLayout), Name: h.name, Moved: h.moved})
+	}
+
+	sort.SliceStable(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+	return holidays
+}
+
+// nextMonday returns the date itself if it is a Monday, otherwise the following Monday
+func nextMonday(date time.Time) time.Time {
+	days := (int(time.Monday) - int(date.Weekday()) + 7) % 7
+	return date.AddDate(0, 0, days)
+}
+
+// HolidaysInYear returns the holidays of a year including extra non-working dates
+func (c *Calendar) HolidaysInYear(year int) []Holiday {
+	byDate := c.yearHolidays(year)
+	holidays := make([]Holiday, 0, len(byDate))
+	for _, holiday := range byDate {
+		holidays = append(holidays, holiday)
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+	return holidays
+}
+
+// yearHolidays returns the holidays of a year indexed by date, cached
+func (c *Calendar) yearHolidays(year int) map[string]Holiday {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if byDate, ok := c.cache[year]; ok {
+		return byDate
+	}
+
+	byDate := make(map[string]Holiday)
+	for _, holiday := range Holidays(year) {
+		// Dos festivos pueden coincidir en la misma fecha
+		if existing, ok := byDate[holiday.Date]; ok {
+			holiday.Name = strings.Join([]string{existing.Name, holiday.Name}, " / ")
+		}
+		byDate[holiday.Date] = holiday
+	}
+	for date, name := range c.extra {
+		if strings.HasPrefix(date, fmt.Sprintf("%04d-", year)) {
+			if _, ok := byDate[date]; !ok {
+				byDate[date] = Holiday{Date: date, Name: name}
+			}
+		}
+	}
+
+	c.cache[year] = byDate
+	return byDate
+}
+
+// Holiday returns the holiday on a date, if any
+func (c *Calendar) Holiday(t time.Time) (Holiday, bool) {
+	t = config.ToColombianTime(t)
+	holiday, ok := c.yearHolidays(t.Year())[t.Format(dateLayout)]
+	return holiday, ok
+}
+
+// IsBusinessDay reports whether a date is a working day (Monday to Friday, not a holiday)
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	t = config.ToColombianTime(t)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// AddBusinessDays returns the date that is the given number of business days after from
+func (c *Calendar) AddBusinessDays(from time.Time, days int) time.Time {
+	t := config.ToColombianTime(from)
+	step := 1
+	if days < 0 {
+		step, days = -1, -days
+	}
+	for days > 0 {
+		t = t.AddDate(0, 0, step)
+		if c.IsBusinessDay(t) {
+			days--
+		}
+	}
+	return t
+}
+
+// NextBusinessDay returns the first business day on or after t
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	t = config.ToColombianTime(t)
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// BusinessDaysBetween counts the business days after from up to and including to
+func (c *Calendar) BusinessDaysBetween(from, to time.Time) int {
+	from = config.ToColombianTime(from)
+	to = config.ToColombianTime(to)
+	if to.Before(from) {
+		return -c.BusinessDaysBetween(to, from)
+	}
+
+	days := 0
+	for t := from.AddDate(0, 0, 1); !t.After(to); t = t.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(t) {
+			days++
+		}
+	}
+	return days
+}
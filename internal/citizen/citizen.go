@@ -0,0 +1,226 @@
+// Package citizen implements the verified-identity channel through which
+// citizens submit observations on contracts.
+//
+// Citizens authenticate with a token signed by the identity provider using a
+// shared secret. The chain only records a pseudonym derived from the document
+// number, so a citizen's observations can be correlated without publishing
+// their identity.
+package citizen
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by the channel
+var (
+	ErrDisabled     = errors.New("canal ciudadano deshabilitado: CITIZEN_IDENTITY_SECRET no configurado")
+	ErrInvalidToken = errors.New("token de identidad ciudadana inválido")
+	ErrExpiredToken = errors.New("token de identidad ciudadana vencido")
+)
+
+// Identity is a verified citizen
+type Identity struct {
+	Document  string    `json:"doc"`
+	Name      string    `json:"name,omitempty"`
+	ExpiresAt time.Time `json:"exp"`
+	Pseudonym string    `json:"-"`
+}
+
+// Verifier validates identity tokens issued with the shared secret
+type Verifier struct {
+	secret []byte
+}
+
+// NewVerifier creates a verifier; an empty secret disables the channel
+func NewVerifier(secret string) *Verifier {
+	return &Verifier{secret: []byte(secret)}
+}
+
+// Enabled reports whether a secret is configured
+func (v *Verifier) Enabled() bool {
+	return len(v.secret) > 0
+}
+
+// Issue signs a token for a citizen. Used by the identity provider, or by the
+// test issuer endpoint in development.
+func (v *Verifier) Issue(document, name string, ttl time.Duration) (string, time.Time, error) {
+	if !v.Enabled() {
+		return "", time.Time{}, ErrDisabled
+	}
+	document = normalizeDocument(document)
+	if document == "" {
+		return "", time.Time{}, errors.New("documento de identidad requerido")
+	}
+	expiresAt := time.Now().Add(ttl).UTC().Truncate(time.Second)
+	payload, err := json.Marshal(Identity{Document: document, Name: name, ExpiresAt: expiresAt})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + v.sign(encoded), expiresAt, nil
+}
+
+// Verify checks a token's signature and expiry and returns the citizen identity
+func (v *Verifier) Verify(token string) (*Identity, error) {
+	if !v.Enabled() {
+		return nil, ErrDisabled
+	}
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(v.sign(parts[0]))) {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var identity Identity
+	if err := json.Unmarshal(payload, &identity); err != nil || identity.Document == "" {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().After(identity.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+	identity.Pseudonym = v.Pseudonym(identity.Document)
+	return &identity, nil
+}
+
+// Pseudonym derives the stable public identifier recorded on-chain for a document
+func (v *Verifier) Pseudonym(document string) string {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte("pseudonym:" + normalizeDocument(document)))
+	return "CIU-" + strings.ToUpper(hex.EncodeToString(mac.Sum(nil))[:16])
+}
+
+func (v *Verifier) sign(payload string) string {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalizeDocument keeps only the digits and letters of a document number
+func normalizeDocument(document string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(document) {
+		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// RateLimiter caps how many observations a citizen submits in a sliding window
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	events map[string][]time.Time
+	mutex  sync.Mutex
+}
+
+// NewRateLimiter creates a limiter allowing limit events per window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	if limit <= 0 {
+		limit = 5
+	}
+	if window <= 0 {
+		window = 24 * time.Hour
+	}
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		events: make(map[string][]time.Time),
+	}
+}
+
+// Allow records an event for key, or returns the time until the next one is allowed
+func (r *RateLimiter) Allow(key string) (bool, time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-r.window)
+	recent := r.events[key][:0]
+	for _, at := range r.events[key] {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) >= r.limit {
+		r.events[key] = recent
+		return false, recent[0].Add(r.window).Sub(now)
+	}
+	r.events[key] = append(recent, now)
+	return true, 0
+}
+
+// Channel combines identity verification and rate limiting
+type Channel struct {
+	Verifier   *Verifier
+	limiter    *RateLimiter
+	testIssuer bool
+}
+
+// NewChannel creates the citizen observation channel
+func NewChannel(secret string, limit int, window time.Duration, testIssuer bool) *Channel {
+	return &Channel{
+		Verifier:   NewVerifier(secret),
+		limiter:    NewRateLimiter(limit, window),
+		testIssuer: testIssuer,
+	}
+}
+
+// Enabled reports whether the channel accepts observations
+func (c *Channel) Enabled() bool {
+	return c.Verifier.Enabled()
+}
+
+// TestIssuerEnabled reports whether the node issues tokens itself (development only)
+func (c *Channel) TestIssuerEnabled() bool {
+	return c.testIssuer && c.Enabled()
+}
+
+// Limits returns the configured rate limit
+func (c *Channel) Limits() (int, time.Duration) {
+	return c.limiter.limit, c.limiter.window
+}
+
+// Admit verifies the token and consumes one observation from the citizen's quota
+func (c *Channel) Admit(token string) (*Identity, error) {
+	identity, err := c.Verifier.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if ok, retryAfter := c.limiter.Allow(identity.Pseudonym); !ok {
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+	return identity, nil
+}
+
+// Throttle consumes one submission from an unauthenticated sender's quota,
+// used for anonymous complaints keyed by network address
+func (c *Channel) Throttle(key string) error {
+	if ok, retryAfter := c.limiter.Allow("anon:" + key); !ok {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RateLimitError is returned when a citizen exceeds the observation quota
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("límite de observaciones ciudadanas alcanzado, intente de nuevo en %s", e.RetryAfter.Round(time.Minute))
+}
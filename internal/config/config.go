@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -13,13 +14,23 @@ type Config struct {
 	Blockchain BlockchainConfig
 	P2P        P2PConfig
 	Entity     EntityConfig
+	Webhooks   WebhookConfig
+	Notify     NotificationConfig
+	Workflow   WorkflowConfig
+	Money      MoneyConfig
+	Suppliers  SupplierConfig
+	Analytics  AnalyticsConfig
+	Citizens   CitizenConfig
+	Complaints ComplaintConfig
+	Scheduler  SchedulerConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port    string
-	Address string
-	Mode    string // gin mode: debug, release, test
+	Port            string
+	Address         string
+	Mode            string        // gin mode: debug, release, test
+	ShutdownTimeout time.Duration // Espera máxima para cerrar conexiones al detener el nodo
 }
 
 // BlockchainConfig holds blockchain configuration
@@ -31,8 +42,22 @@ type BlockchainConfig struct {
 // P2PConfig holds P2P network configuration
 type P2PConfig struct {
 	NodeID                string
-	DiscoveryRegistryURL  string
-	BootstrapPeers        []string
+	DiscoveryRegistryURLs []string      // Registros de peers en orden de preferencia
+	HeartbeatInterval     time.Duration // Frecuencia con que el nodo renueva su registro
+	BootstrapPeers        []string      // Peers semilla en formato id:host:port
+	InitialSyncTimeout    time.Duration // Espera máxima de la sincronización inicial antes de declararse listo
+	SigningKey            string        // Semilla ed25519 en hexadecimal para firmar reportes
+	RegistryMode          bool          // El nodo atiende /api/peers como registro de descubrimiento
+	RegistryFile          string        // Archivo JSON donde el registro persiste los nodos
+	RegistryTTL           time.Duration // Tiempo sin heartbeat tras el cual un nodo deja de listarse
+	GossipEnabled         bool          // Intercambio de listas de peers entre nodos
+	GossipInterval        time.Duration // Frecuencia de cada ronda de intercambio
+	GossipFanout          int           // Peers contactados por ronda
+	GossipMaxPeers        int           // Máximo de peers aprendidos por intercambio
+	BanDuration           time.Duration // Bloqueo inicial de un peer que agota su reputación
+	BroadcastTimeout      time.Duration // Espera máxima de cada envío de un bloque a un peer
+	BroadcastMaxRetries   int           // Reintentos de un bloque antes de pausar la cola del peer
+	BroadcastQueueSize    int           // Bloques pendientes que se guardan por peer
 }
 
 // EntityConfig holds entity-specific configuration
@@ -45,6 +70,81 @@ type EntityConfig struct {
 	ContactEmail        string // Email de contacto para contratos
 	BudgetAuthority     bool   // Si tiene autoridad presupuestal
 	MaxContractValue    int64  // Valor máximo de contrato que puede manejar
+	HigherAuthorityThreshold int64 // Valor desde el cual se exige aprobación del comité de contratación
+	AnnualBudget        int64  // Presupuesto anual en pesos, determina la menor cuantía
+}
+
+// WebhookConfig holds outbound webhook delivery configuration
+type WebhookConfig struct {
+	MaxAttempts    int           // Intentos antes de enviar a dead-letter
+	Timeout        time.Duration // Timeout por intento de entrega
+	InitialBackoff time.Duration // Espera antes del primer reintento (se duplica en cada intento)
+	Workers        int           // Número de entregas concurrentes
+}
+
+// NotificationConfig holds notification channel configuration
+type NotificationConfig struct {
+	SMTPHost       string
+	SMTPPort       string
+	SMTPUsername   string
+	SMTPPassword   string
+	From           string // Remitente; por defecto ENTITY_CONTACT_EMAIL
+	DigestHour     int    // Hora (Colombia) de envío del resumen diario de pendientes
+	TestSMTPServer bool   // Inicia un servidor SMTP local en SMTPHost:SMTPPort que guarda los correos en memoria
+}
+
+// WorkflowConfig holds workflow deadline configuration
+type WorkflowConfig struct {
+	StepDeadlines         map[string]int // Plazo en días hábiles por rol (ROL:DIAS)
+	DefaultStepDays       int            // Plazo por defecto en días hábiles
+	Holidays              []string       // Festivos adicionales en formato YYYY-MM-DD
+	DeadlineCheckInterval time.Duration  // Frecuencia de revisión de plazos vencidos
+	ModalityRulesFile     string         // Reglas de modalidad en JSON; vacío usa las incorporadas
+	AuditResponseDays     int            // Plazo en días hábiles para responder hallazgos de auditoría
+}
+
+// MoneyConfig holds currency conversion configuration
+type MoneyConfig struct {
+	USDRate string        // Tasa representativa del mercado (pesos por dólar); vacío usa el valor por defecto
+	SMMLV   map[int]int64 // Salario mínimo por año que complementa la tabla incorporada (AÑO:VALOR)
+}
+
+// SupplierConfig holds supplier eligibility configuration
+type SupplierConfig struct {
+	SanctionsListFile string // Lista local de sanciones e inhabilidades en JSON; vacío desactiva la verificación
+}
+
+// AnalyticsConfig holds red-flag detection thresholds
+type AnalyticsConfig struct {
+	SplitWindowDays       int           // Ventana para detectar fraccionamiento de contratos
+	SplitMarginPercent    int           // Margen bajo el umbral considerado sospechoso
+	FastStep              time.Duration // Aprobación de un paso más rápida que esto es inusual
+	FastWorkflow          time.Duration // Flujo completo aprobado más rápido que esto es inusual
+	ValidatorSharePercent int           // Porcentaje de aprobaciones de la entidad que concentra un validador
+	ValidatorMinContracts int           // Contratos mínimos de la entidad para evaluar concentración
+}
+
+// CitizenConfig holds the verified-identity channel for citizen observations
+type CitizenConfig struct {
+	IdentitySecret string        // Secreto compartido con el proveedor de identidad; vacío deshabilita el canal
+	RateLimit      int           // Observaciones permitidas por ciudadano en la ventana
+	RateWindow     time.Duration // Ventana del límite de observaciones
+	TestIssuer     bool          // Expone POST /api/citizen/token para emitir tokens en desarrollo
+}
+
+// ComplaintConfig holds limits for evidence attached to citizen complaints
+type ComplaintConfig struct {
+	EvidenceDir      string // Directorio local donde se guardan los archivos de evidencia
+	MaxEvidenceBytes int64  // Tamaño máximo por archivo
+	MaxEvidenceFiles int    // Archivos máximos por denuncia
+}
+
+// SchedulerConfig holds the intervals of the node's background jobs
+type SchedulerConfig struct {
+	JitterPercent       int           // Variación aleatoria de cada intervalo para que los nodos no coincidan
+	HealthCheckInterval time.Duration // Verificación de salud de los peers
+	SyncInterval        time.Duration // Sincronización incremental de la cadena
+	PeerListInterval    time.Duration // Actualización de la lista de peers desde el descubrimiento
 }
 
 // ColombianTimezone represents Colombia's timezone (UTC-5)
@@ -74,18 +174,33 @@ func ToColombianTime(t time.Time) time.Time {
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:    getEnv("NODE_PORT", "8080"),
-			Address: getEnv("NODE_ADDRESS", "localhost"),
-			Mode:    getEnv("GIN_MODE", "debug"),
+			Port:            getEnv("NODE_PORT", "8080"),
+			Address:         getEnv("NODE_ADDRESS", "localhost"),
+			Mode:            getEnv("GIN_MODE", "debug"),
+			ShutdownTimeout: parseDuration(getEnv("SHUTDOWN_TIMEOUT", "15s")),
 		},
 		Blockchain: BlockchainConfig{
 			GenesisBlock: getEnv("GENESIS_BLOCK", "false") == "true",
 			Difficulty:   1,
 		},
 		P2P: P2PConfig{
-			NodeID:               getEnv("NODE_ID", "secop-government-central-bogota"),
-			DiscoveryRegistryURL: getEnv("PEER_DISCOVERY_REGISTRY_URL", ""),
-			BootstrapPeers:       parseBootstrapPeers(getEnv("BOOTSTRAP_PEERS", "")),
+			NodeID:                getEnv("NODE_ID", "secop-government-central-bogota"),
+			DiscoveryRegistryURLs: parseList(getEnv("PEER_DISCOVERY_REGISTRY_URL", "")),
+			HeartbeatInterval:     parseDuration(getEnv("PEER_HEARTBEAT_INTERVAL", "30s")),
+			BootstrapPeers:        parseBootstrapPeers(getEnv("BOOTSTRAP_PEERS", "")),
+			InitialSyncTimeout:    parseDuration(getEnv("PEER_INITIAL_SYNC_TIMEOUT", "30s")),
+			SigningKey:            getEnv("NODE_SIGNING_KEY", ""),
+			RegistryMode:          getEnv("PEER_REGISTRY_MODE", "false") == "true",
+			RegistryFile:          getEnv("PEER_REGISTRY_FILE", "data/peers.json"),
+			RegistryTTL:           parseDuration(getEnv("PEER_REGISTRY_TTL", "90s")),
+			GossipEnabled:         getEnv("PEER_GOSSIP_ENABLED", "true") == "true",
+			GossipInterval:        parseDuration(getEnv("PEER_GOSSIP_INTERVAL", "1m")),
+			GossipFanout:          int(parseInt64(getEnv("PEER_GOSSIP_FANOUT", "3"))),
+			GossipMaxPeers:        int(parseInt64(getEnv("PEER_GOSSIP_MAX_PEERS", "128"))),
+			BanDuration:           parseDuration(getEnv("PEER_BAN_DURATION", "30m")),
+			BroadcastTimeout:      parseDuration(getEnv("PEER_BROADCAST_TIMEOUT", "5s")),
+			BroadcastMaxRetries:   int(parseInt64(getEnv("PEER_BROADCAST_MAX_RETRIES", "5"))),
+			BroadcastQueueSize:    int(parseInt64(getEnv("PEER_BROADCAST_QUEUE_SIZE", "256"))),
 		},
 		Entity: EntityConfig{
 			Type:                getEnv("ENTITY_TYPE", "GOVERNMENT"),
@@ -96,6 +211,63 @@ func Load() *Config {
 			ContactEmail:        getEnv("ENTITY_CONTACT_EMAIL", ""),
 			BudgetAuthority:     getEnv("ENTITY_BUDGET_AUTHORITY", "false") == "true",
 			MaxContractValue:    parseInt64(getEnv("ENTITY_MAX_CONTRACT_VALUE", "0")),
+			HigherAuthorityThreshold: parseInt64(getEnv("ENTITY_HIGHER_AUTHORITY_THRESHOLD", "0")),
+			AnnualBudget:        parseInt64(getEnv("ENTITY_ANNUAL_BUDGET", "0")),
+		},
+		Notify: NotificationConfig{
+			SMTPHost:       getEnv("NOTIFY_SMTP_HOST", "localhost"),
+			SMTPPort:       getEnv("NOTIFY_SMTP_PORT", "2525"),
+			SMTPUsername:   getEnv("NOTIFY_SMTP_USERNAME", ""),
+			SMTPPassword:   getEnv("NOTIFY_SMTP_PASSWORD", ""),
+			From:           getEnv("NOTIFY_SMTP_FROM", getEnv("ENTITY_CONTACT_EMAIL", "")),
+			DigestHour:     int(parseInt64(getEnv("NOTIFY_DIGEST_HOUR", "7"))),
+			TestSMTPServer: getEnv("NOTIFY_SMTP_TEST_SERVER", "false") == "true",
+		},
+		Workflow: WorkflowConfig{
+			StepDeadlines:         parseStepDeadlines(getEnv("WORKFLOW_STEP_DEADLINES", "")),
+			DefaultStepDays:       int(parseInt64(getEnv("WORKFLOW_DEFAULT_STEP_DAYS", "5"))),
+			Holidays:              parseList(getEnv("WORKFLOW_HOLIDAYS", "")),
+			DeadlineCheckInterval: parseDuration(getEnv("WORKFLOW_DEADLINE_CHECK_INTERVAL", "15m")),
+			ModalityRulesFile:     getEnv("MODALITY_RULES_FILE", ""),
+			AuditResponseDays:     int(parseInt64(getEnv("AUDIT_RESPONSE_DAYS", "10"))),
+		},
+		Money: MoneyConfig{
+			USDRate: getEnv("MONEY_USD_COP_RATE", ""),
+			SMMLV:   parseYearValues(getEnv("MONEY_SMMLV", "")),
+		},
+		Suppliers: SupplierConfig{
+			SanctionsListFile: getEnv("SANCTIONS_LIST_FILE", ""),
+		},
+		Analytics: AnalyticsConfig{
+			SplitWindowDays:       int(parseInt64(getEnv("ANALYTICS_SPLIT_WINDOW_DAYS", "90"))),
+			SplitMarginPercent:    int(parseInt64(getEnv("ANALYTICS_SPLIT_MARGIN_PERCENT", "10"))),
+			FastStep:              parseDuration(getEnv("ANALYTICS_FAST_STEP", "1h")),
+			FastWorkflow:          parseDuration(getEnv("ANALYTICS_FAST_WORKFLOW", "24h")),
+			ValidatorSharePercent: int(parseInt64(getEnv("ANALYTICS_VALIDATOR_SHARE_PERCENT", "60"))),
+			ValidatorMinContracts: int(parseInt64(getEnv("ANALYTICS_VALIDATOR_MIN_CONTRACTS", "5"))),
+		},
+		Citizens: CitizenConfig{
+			IdentitySecret: getEnv("CITIZEN_IDENTITY_SECRET", ""),
+			RateLimit:      int(parseInt64(getEnv("CITIZEN_RATE_LIMIT", "5"))),
+			RateWindow:     parseDuration(getEnv("CITIZEN_RATE_WINDOW", "24h")),
+			TestIssuer:     getEnv("CITIZEN_TEST_ISSUER", "false") == "true",
+		},
+		Complaints: ComplaintConfig{
+			EvidenceDir:      getEnv("COMPLAINT_EVIDENCE_DIR", "data/evidence"),
+			MaxEvidenceBytes: parseInt64(getEnv("COMPLAINT_EVIDENCE_MAX_BYTES", "5242880")),
+			MaxEvidenceFiles: int(parseInt64(getEnv("COMPLAINT_EVIDENCE_MAX_FILES", "5"))),
+		},
+		Scheduler: SchedulerConfig{
+			JitterPercent:       int(parseInt64(getEnv("SCHEDULER_JITTER_PERCENT", "10"))),
+			HealthCheckInterval: parseDuration(getEnv("PEER_HEALTH_CHECK_INTERVAL", "1m")),
+			SyncInterval:        parseDuration(getEnv("CHAIN_SYNC_INTERVAL", "2m")),
+			PeerListInterval:    parseDuration(getEnv("PEER_LIST_REFRESH_INTERVAL", "1m")),
+		},
+		Webhooks: WebhookConfig{
+			MaxAttempts:    int(parseInt64(getEnv("WEBHOOK_MAX_ATTEMPTS", "5"))),
+			Timeout:        parseDuration(getEnv("WEBHOOK_TIMEOUT", "10s")),
+			InitialBackoff: parseDuration(getEnv("WEBHOOK_INITIAL_BACKOFF", "5s")),
+			Workers:        int(parseInt64(getEnv("WEBHOOK_WORKERS", "4"))),
 		},
 	}
 }
@@ -115,8 +287,61 @@ func parseInt64(s string) int64 {
 	return value
 }
 
+// parseDuration parses a duration string such as "30s" or "5m"
+func parseDuration(s string) time.Duration {
+	value, _ := time.ParseDuration(s)
+	return value
+}
+
+// parseList parses a comma-separated list, dropping empty items
+func parseList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseStepDeadlines parses per-role deadlines
+// Format: ROLE1:days,ROLE2:days
+func parseStepDeadlines(s string) map[string]int {
+	result := make(map[string]int)
+	for _, item := range parseList(s) {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if days, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil && days > 0 {
+			result[strings.TrimSpace(parts[0])] = days
+		}
+	}
+	return result
+}
+
+// parseYearValues parses per-year integer values
+// Format: YEAR1:value,YEAR2:value
+func parseYearValues(s string) map[int]int64 {
+	result := make(map[int]int64)
+	for _, item := range parseList(s) {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		year, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		if value := parseInt64(strings.TrimSpace(parts[1])); value > 0 {
+			result[year] = value
+		}
+	}
+	return result
+}
+
 // parseBootstrapPeers parses bootstrap peers from environment variable
-// Format: nodeId1:address1,nodeId2:address2
+// Format: nodeId1:host1:port1,nodeId2:host2:port2
 func parseBootstrapPeers(peersStr string) []string {
 	if peersStr == "" {
 		return []string{}
@@ -133,4 +358,38 @@ func parseBootstrapPeers(peersStr string) []string {
 	}
 	
 	return result
-}
\ No newline at end of file
+}
+
+// BootstrapPeer is a peer contacted at startup to join the network
+type BootstrapPeer struct {
+	ID   string
+	Host string
+	Port string
+}
+
+// ParseBootstrapPeer parses and validates a bootstrap peer in id:host:port
+// form. IPv6 hosts go in brackets: id:[::1]:8080
+func ParseBootstrapPeer(entry string) (BootstrapPeer, error) {
+	first := strings.Index(entry, ":")
+	last := strings.LastIndex(entry, ":")
+	if first <= 0 || last == first {
+		return BootstrapPeer{}, fmt.Errorf("peer bootstrap %q inválido, se espera id:host:puerto", entry)
+	}
+
+	peer := BootstrapPeer{
+		ID:   strings.TrimSpace(entry[:first]),
+		Host: strings.TrimSpace(entry[first+1 : last]),
+		Port: strings.TrimSpace(entry[last+1:]),
+	}
+	peer.Host = strings.TrimSuffix(strings.TrimPrefix(peer.Host, "["), "]")
+	if peer.ID == "" || peer.Host == "" {
+		return BootstrapPeer{}, fmt.Errorf("peer bootstrap %q inválido, se espera id:host:puerto", entry)
+	}
+	if strings.ContainsAny(peer.Host, "/?#@ ") {
+		return BootstrapPeer{}, fmt.Errorf("peer bootstrap %q: host inválido %q", entry, peer.Host)
+	}
+	if port, err := strconv.Atoi(peer.Port); err != nil || port < 1 || port > 65535 {
+		return BootstrapPeer{}, fmt.Errorf("peer bootstrap %q: puerto inválido %q", entry, peer.Port)
+	}
+	return peer, nil
+}
@@ -0,0 +1,102 @@
+// Package evidence stores files attached to citizen complaints.
+//
+// Files are content-addressed by their SHA-256 digest. Only the digest and
+// metadata go on-chain, so any node holding a copy of the file can prove it
+// is the evidence that was filed.
+package evidence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var digestPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ErrNotFound is returned when no file with the digest is stored on this node
+var ErrNotFound = errors.New("evidencia no disponible en este nodo")
+
+// Store keeps evidence files in a local directory
+type Store struct {
+	dir      string
+	maxBytes int64
+	maxFiles int
+}
+
+// NewStore creates a store rooted at dir, creating the directory if needed
+func NewStore(dir string, maxBytes int64, maxFiles int) (*Store, error) {
+	if maxBytes <= 0 {
+		maxBytes = 5 << 20
+	}
+	if maxFiles <= 0 {
+		maxFiles = 5
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("no se pudo crear el directorio de evidencias %s: %v", dir, err)
+	}
+	return &Store{dir: dir, maxBytes: maxBytes, maxFiles: maxFiles}, nil
+}
+
+// MaxFiles returns how many files a complaint may attach
+func (s *Store) MaxFiles() int {
+	return s.maxFiles
+}
+
+// MaxBytes returns the size limit per file
+func (s *Store) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Digest returns the hex SHA-256 digest of content
+func Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Save stores content and returns its digest. Saving the same content twice is a no-op.
+func (s *Store) Save(content []byte) (string, error) {
+	if len(content) == 0 {
+		return "", errors.New("archivo de evidencia vacío")
+	}
+	if int64(len(content)) > s.maxBytes {
+		return "", fmt.Errorf("el archivo de evidencia supera el máximo de %d bytes", s.maxBytes)
+	}
+	digest := Digest(content)
+	path := filepath.Join(s.dir, digest)
+	if _, err := os.Stat(path); err == nil {
+		return digest, nil
+	}
+
+	tmp, err := os.CreateTemp(s.dir, digest+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// Path returns the local path of a stored file
+func (s *Store) Path(digest string) (string, error) {
+	if !digestPattern.MatchString(digest) {
+		return "", ErrNotFound
+	}
+	path := filepath.Join(s.dir, digest)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrNotFound
+	}
+	return path, nil
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/analytics"
+	"secop-blockchain/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsHandler handles red-flag and risk score HTTP requests
+type AnalyticsHandler struct {
+	services *service.Services
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(services *service.Services) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		services: services,
+	}
+}
+
+// GetAlerts returns red flags, optionally filtered by entity, flag and minimum severity
+func (h *AnalyticsHandler) GetAlerts(c *gin.Context) {
+	filter := analytics.AlertFilter{
+		EntityCode:  c.Query("entity_code"),
+		Flag:        strings.ToUpper(c.Query("flag")),
+		MinSeverity: strings.ToUpper(c.Query("min_severity")),
+	}
+
+	alerts, computedAt := h.services.Analytics.Alerts(filter)
+	c.JSON(http.StatusOK, gin.H{
+		"count":       len(alerts),
+		"alerts":      alerts,
+		"computed_at": computedAt,
+	})
+}
+
+// GetRanking returns contracts ordered by risk score
+func (h *AnalyticsHandler) GetRanking(c *gin.Context) {
+	minScore := 0
+	if value := c.Query("min_score"); value != "" {
+		score, err := strconv.Atoi(value)
+		if err != nil || score < 0 || score > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_score inválido: " + value})
+			return
+		}
+		minScore = score
+	}
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if limit == 0 {
+		limit = 50
+	}
+
+	risks := h.services.Analytics.Ranking(c.Query("entity_code"), minScore, limit)
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(risks),
+		"contracts": risks,
+	})
+}
+
+// GetContractRisk returns the risk score and flags of a contract
+func (h *AnalyticsHandler) GetContractRisk(c *gin.Context) {
+	risk, err := h.services.Analytics.ContractRisk(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, risk)
+}
+
+// GetConfig returns the detection thresholds in effect
+func (h *AnalyticsHandler) GetConfig(c *gin.Context) {
+	cfg := h.services.Analytics.Config()
+	c.JSON(http.StatusOK, gin.H{
+		"split_window_days":       int(cfg.SplitWindow.Hours() / 24),
+		"split_margin_percent":    cfg.SplitMarginPercent,
+		"fast_step":               cfg.FastStep.String(),
+		"fast_workflow":           cfg.FastWorkflow.String(),
+		"validator_share_percent": cfg.ValidatorSharePercent,
+		"validator_min_contracts": cfg.ValidatorMinContracts,
+	})
+}
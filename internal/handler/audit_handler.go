@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler handles audit case HTTP requests from control entities
+type AuditHandler struct {
+	services *service.Services
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(services *service.Services) *AuditHandler {
+	return &AuditHandler{
+		services: services,
+	}
+}
+
+// OpenCase opens an audit case on a contract
+func (h *AuditHandler) OpenCase(c *gin.Context) {
+	var req struct {
+		AuditorID string `json:"auditor_id"`
+		Role      string `json:"role"`
+		Subject   string `json:"subject"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditCase, err := h.services.Workflow.OpenAuditCase(c.Param("id"), req.AuditorID, blockchain.AdminRole(req.Role), req.Subject)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"case":    auditCase,
+	})
+}
+
+// GetContractCases returns the audit cases of a contract
+func (h *AuditHandler) GetContractCases(c *gin.Context) {
+	contract, err := h.services.Blockchain.GetContract(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	cases := h.services.Blockchain.GetAuditCases(blockchain.AuditCaseFilter{ContractID: contract.ID})
+	c.JSON(http.StatusOK, gin.H{
+		"contract_id":  contract.ID,
+		"audit_status": contract.AuditStatus,
+		"count":        len(cases),
+		"cases":        cases,
+	})
+}
+
+// GetCases lists audit cases by contract, entity, status or overdue responses
+func (h *AuditHandler) GetCases(c *gin.Context) {
+	filter := blockchain.AuditCaseFilter{
+		ContractID:  c.Query("contract_id"),
+		EntityCode:  c.Query("entity_code"),
+		Status:      blockchain.AuditCaseStatus(strings.ToUpper(c.Query("status"))),
+		OverdueOnly: c.Query("overdue") == "true",
+	}
+
+	cases := h.services.Blockchain.GetAuditCases(filter)
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(cases),
+		"cases": cases,
+	})
+}
+
+// GetCase returns an audit case with its findings and responses
+func (h *AuditHandler) GetCase(c *gin.Context) {
+	auditCase := h.services.Blockchain.GetAuditCase(c.Param("id"))
+	if auditCase == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "caso de auditoría no encontrado"})
+		return
+	}
+	c.JSON(http.StatusOK, auditCase)
+}
+
+// AddFinding records a finding on an open case
+func (h *AuditHandler) AddFinding(c *gin.Context) {
+	var req struct {
+		AuditorID    string `json:"auditor_id"`
+		Role         string `json:"role"`
+		Description  string `json:"description"`
+		Criteria     string `json:"criteria"`
+		Severity     string `json:"severity"`
+		ResponseDays int    `json:"response_days"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	finding := &blockchain.AuditFinding{
+		Description: req.Description,
+		Criteria:    req.Criteria,
+		Severity:    blockchain.FindingSeverity(req.Severity),
+	}
+	finding, err := h.services.Workflow.AddAuditFinding(c.Param("id"), req.AuditorID, blockchain.AdminRole(req.Role), finding, req.ResponseDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"finding": finding,
+	})
+}
+
+// RespondFinding records the contracting entity's response to a finding
+func (h *AuditHandler) RespondFinding(c *gin.Context) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		Role     string `json:"role"`
+		Response string `json:"response"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.services.Workflow.RespondAuditFinding(c.Param("id"), c.Param("finding_id"), req.UserID, blockchain.AdminRole(req.Role), req.Response)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Respuesta registrada",
+		"case":    h.services.Blockchain.GetAuditCase(c.Param("id")),
+	})
+}
+
+// CloseCase closes an audit case with its outcome
+func (h *AuditHandler) CloseCase(c *gin.Context) {
+	var req struct {
+		AuditorID  string `json:"auditor_id"`
+		Role       string `json:"role"`
+		Outcome    string `json:"outcome"`
+		Conclusion string `json:"conclusion"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.services.Workflow.CloseAuditCase(c.Param("id"), req.AuditorID, blockchain.AdminRole(req.Role), req.Outcome, req.Conclusion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Caso de auditoría cerrado",
+		"case":    h.services.Blockchain.GetAuditCase(c.Param("id")),
+	})
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler handles budget ledger HTTP requests
+type BudgetHandler struct {
+	services *service.Services
+}
+
+// NewBudgetHandler creates a new budget handler
+func NewBudgetHandler(services *service.Services) *BudgetHandler {
+	return &BudgetHandler{
+		services: services,
+	}
+}
+
+// CreateAppropriation registers a budget appropriation
+func (h *BudgetHandler) CreateAppropriation(c *gin.Context) {
+	var appropriation blockchain.Appropriation
+	if err := c.ShouldBindJSON(&appropriation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if appropriation.EntityCode == "" {
+		appropriation.EntityCode = h.services.Config.Entity.Code
+	}
+
+	if err := h.services.Blockchain.RegisterAppropriation(&appropriation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":       true,
+		"appropriation": appropriation,
+	})
+}
+
+// GetAppropriations lists appropriations by entity and fiscal year
+func (h *BudgetHandler) GetAppropriations(c *gin.Context) {
+	fiscalYear := 0
+	if value := c.Query("fiscal_year"); value != "" {
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fiscal_year inválido: " + value})
+			return
+		}
+		fiscalYear = year
+	}
+
+	appropriations := h.services.Blockchain.GetAppropriations(c.Query("entity_code"), fiscalYear)
+	c.JSON(http.StatusOK, gin.H{
+		"count":          len(appropriations),
+		"appropriations": appropriations,
+	})
+}
+
+// GetAppropriation returns an appropriation with its CDPs
+func (h *BudgetHandler) GetAppropriation(c *gin.Context) {
+	appropriation := h.services.Blockchain.GetAppropriation(c.Param("id"))
+	if appropriation == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rubro presupuestal no encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"appropriation": appropriation,
+		"cdps":          h.services.Blockchain.GetCDPs(appropriation.ID, ""),
+	})
+}
+
+// IssueCDP issues a budget availability certificate
+func (h *BudgetHandler) IssueCDP(c *gin.Context) {
+	var cdp blockchain.CDP
+	if err := c.ShouldBindJSON(&cdp); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Blockchain.IssueCDP(&cdp); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"cdp":     cdp,
+	})
+}
+
+// GetCDPs lists CDPs by appropriation and contract
+func (h *BudgetHandler) GetCDPs(c *gin.Context) {
+	cdps := h.services.Blockchain.GetCDPs(c.Query("appropriation_id"), c.Query("contract_id"))
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(cdps),
+		"cdps":  cdps,
+	})
+}
+
+// GetCDP returns a CDP with its commitments
+func (h *BudgetHandler) GetCDP(c *gin.Context) {
+	cdp := h.services.Blockchain.GetCDP(c.Param("id"))
+	if cdp == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "CDP no encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cdp":         cdp,
+		"commitments": h.services.Blockchain.GetCommitments(cdp.ID, ""),
+	})
+}
+
+// ReleaseCDP releases the uncommitted balance of a CDP
+func (h *BudgetHandler) ReleaseCDP(c *gin.Context) {
+	var req struct {
+		ReleasedBy string `json:"released_by"`
+		Reason     string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Blockchain.ReleaseCDP(c.Param("id"), req.ReleasedBy, req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"cdp":     h.services.Blockchain.GetCDP(c.Param("id")),
+	})
+}
+
+// RegisterCommitment registers a budget commitment (RP) against a CDP
+func (h *BudgetHandler) RegisterCommitment(c *gin.Context) {
+	var commitment blockchain.Commitment
+	if err := c.ShouldBindJSON(&commitment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Blockchain.RegisterCommitment(&commitment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":    true,
+		"commitment": commitment,
+	})
+}
+
+// GetContractBudget returns the budget coverage of a contract
+func (h *BudgetHandler) GetContractBudget(c *gin.Context) {
+	budget, err := h.services.Blockchain.GetContractBudget(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, budget)
+}
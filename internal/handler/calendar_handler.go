@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler handles business-day calendar HTTP requests
+type CalendarHandler struct {
+	services *service.Services
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(services *service.Services) *CalendarHandler {
+	return &CalendarHandler{
+		services: services,
+	}
+}
+
+// GetHolidays returns the holidays of a year
+func (h *CalendarHandler) GetHolidays(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < 1900 || year > 2200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "año inválido: " + c.Param("year")})
+		return
+	}
+
+	holidays := h.services.Calendar.HolidaysInYear(year)
+	c.JSON(http.StatusOK, gin.H{
+		"year":     year,
+		"holidays": holidays,
+		"count":    len(holidays),
+	})
+}
+
+// GetBusinessDay reports whether a date is a business day
+func (h *CalendarHandler) GetBusinessDay(c *gin.Context) {
+	date, err := parseDateParam(c.DefaultQuery("date", config.GetColombianTime().Format("2006-01-02")), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date inválido: " + c.Query("date")})
+		return
+	}
+
+	response := gin.H{
+		"date":              date.Format("2006-01-02"),
+		"is_business_day":   h.services.Calendar.IsBusinessDay(date),
+		"next_business_day": h.services.Calendar.NextBusinessDay(date).Format("2006-01-02"),
+	}
+	if holiday, ok := h.services.Calendar.Holiday(date); ok {
+		response["holiday"] = holiday
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AddBusinessDays returns the date a number of business days after another
+func (h *CalendarHandler) AddBusinessDays(c *gin.Context) {
+	from, err := parseDateParam(c.DefaultQuery("from", config.GetColombianTime().Format("2006-01-02")), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from inválido: " + c.Query("from")})
+		return
+	}
+	days, err := strconv.Atoi(c.Query("days"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days inválido: " + c.Query("days")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":   from.Format("2006-01-02"),
+		"days":   days,
+		"result": h.services.Calendar.AddBusinessDays(from, days).Format("2006-01-02"),
+	})
+}
+
+// CountBusinessDays returns the number of business days between two dates
+func (h *CalendarHandler) CountBusinessDays(c *gin.Context) {
+	from, err := parseDateParam(c.Query("from"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from inválido: " + c.Query("from")})
+		return
+	}
+	to, err := parseDateParam(c.Query("to"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to inválido: " + c.Query("to")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":          from.Format("2006-01-02"),
+		"to":            to.Format("2006-01-02"),
+		"business_days": h.services.Calendar.BusinessDaysBetween(from, to),
+	})
+}
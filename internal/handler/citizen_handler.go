@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"secop-blockchain/internal/citizen"
+	"secop-blockchain/internal/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CitizenHandler handles observations submitted through the verified citizen channel
+type CitizenHandler struct {
+	services *service.Services
+}
+
+// NewCitizenHandler creates a new citizen handler
+func NewCitizenHandler(services *service.Services) *CitizenHandler {
+	return &CitizenHandler{
+		services: services,
+	}
+}
+
+// AddObservation records a citizen observation authenticated by an identity token
+func (h *CitizenHandler) AddObservation(c *gin.Context) {
+	if !h.services.Citizens.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": citizen.ErrDisabled.Error()})
+		return
+	}
+
+	var req struct {
+		Token       string `json:"token"`
+		Observation string `json:"observation"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Token == "" {
+		req.Token = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	}
+	if strings.TrimSpace(req.Observation) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "observación requerida"})
+		return
+	}
+	if _, err := h.services.Blockchain.GetContract(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	identity, err := h.services.Citizens.Admit(req.Token)
+	if err != nil {
+		var limited *citizen.RateLimitError
+		if errors.As(err, &limited) {
+			c.Header("Retry-After", strconv.Itoa(int(limited.RetryAfter.Seconds())))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.services.Workflow.AddCitizenObservation(c.Param("id"), identity.Pseudonym, req.Observation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Observación ciudadana registrada",
+		"pseudonym": identity.Pseudonym,
+	})
+}
+
+// GetChannel describes the citizen channel and its limits
+func (h *CitizenHandler) GetChannel(c *gin.Context) {
+	limit, window := h.services.Citizens.Limits()
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":     h.services.Citizens.Enabled(),
+		"rate_limit":  limit,
+		"rate_window": window.String(),
+		"test_issuer": h.services.Citizens.TestIssuerEnabled(),
+	})
+}
+
+// IssueToken issues an identity token when the development issuer is enabled
+func (h *CitizenHandler) IssueToken(c *gin.Context) {
+	if !h.services.Citizens.TestIssuerEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "emisor de tokens de prueba deshabilitado"})
+		return
+	}
+
+	var req struct {
+		Document string `json:"document"`
+		Name     string `json:"name"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, expiresAt, err := h.services.Citizens.Verifier.Issue(req.Document, req.Name, time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_at": expiresAt,
+	})
+}
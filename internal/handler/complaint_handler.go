@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/citizen"
+	"secop-blockchain/internal/evidence"
+	"secop-blockchain/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ComplaintHandler handles citizen and oversight committee complaints
+type ComplaintHandler struct {
+	services *service.Services
+}
+
+// NewComplaintHandler creates a new complaint handler
+func NewComplaintHandler(services *service.Services) *ComplaintHandler {
+	return &ComplaintHandler{
+		services: services,
+	}
+}
+
+// FileComplaint files a complaint on a contract, anonymously or with a verified identity
+func (h *ComplaintHandler) FileComplaint(c *gin.Context) {
+	var req struct {
+		Token       string `json:"token"`
+		Anonymous   bool   `json:"anonymous"`
+		Category    string `json:"category"`
+		Subject     string `json:"subject"`
+		Description string `json:"description"`
+		Committee   string `json:"oversight_committee"`
+		Evidence    []struct {
+			Name      string `json:"name"`
+			MediaType string `json:"media_type"`
+			Content   string `json:"content"` // Archivo en base64
+			URL       string `json:"url"`
+			SHA256    string `json:"sha256"`
+		} `json:"evidence"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.services.Blockchain.GetContract(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Decode evidence before consuming the sender's quota
+	store := h.services.Evidence
+	if len(req.Evidence) > store.MaxFiles() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("máximo %d archivos de evidencia por denuncia", store.MaxFiles())})
+		return
+	}
+	items := make([]blockchain.ComplaintEvidence, len(req.Evidence))
+	contents := make([][]byte, len(req.Evidence))
+	for i, file := range req.Evidence {
+		items[i] = blockchain.ComplaintEvidence{Name: file.Name, MediaType: file.MediaType, URL: file.URL}
+		switch {
+		case file.Content != "":
+			content, err := base64.StdEncoding.DecodeString(file.Content)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("evidencia %q: contenido base64 inválido", file.Name)})
+				return
+			}
+			if int64(len(content)) > store.MaxBytes() {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("evidencia %q supera el máximo de %d bytes", file.Name, store.MaxBytes())})
+				return
+			}
+			contents[i] = content
+			items[i].Size = int64(len(content))
+			items[i].SHA256 = evidence.Digest(content)
+		case file.URL != "" && file.SHA256 != "":
+			items[i].SHA256 = strings.ToLower(file.SHA256)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("evidencia %q: envíe el contenido o una URL con su huella sha256", file.Name)})
+			return
+		}
+	}
+
+	complaint := &blockchain.Complaint{
+		Category:    blockchain.ComplaintCategory(req.Category),
+		Subject:     req.Subject,
+		Description: req.Description,
+		Committee:   req.Committee,
+		Anonymous:   req.Anonymous,
+		Evidence:    items,
+	}
+
+	// Anonymous complaints are throttled by address; identified ones by citizen.
+	// RemoteIP rather than ClientIP: forwarded headers are chosen by the sender
+	if req.Anonymous {
+		if err := h.services.Citizens.Throttle(c.RemoteIP()); err != nil {
+			h.rateLimited(c, err)
+			return
+		}
+	} else {
+		if !h.services.Citizens.Enabled() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": citizen.ErrDisabled.Error() + "; solo se reciben denuncias anónimas"})
+			return
+		}
+		if req.Token == "" {
+			req.Token = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		if req.Token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "las denuncias no anónimas requieren token de identidad ciudadana"})
+			return
+		}
+		identity, err := h.services.Citizens.Admit(req.Token)
+		if err != nil {
+			h.rateLimited(c, err)
+			return
+		}
+		complaint.Complainant = identity.Pseudonym
+	}
+
+	for i, content := range contents {
+		if content == nil {
+			continue
+		}
+		if _, err := store.Save(content); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("evidencia %q: %v", items[i].Name, err)})
+			return
+		}
+	}
+
+	filed, receipt, err := h.services.Workflow.FileComplaint(c.Param("id"), complaint)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":   true,
+		"message":   "Denuncia radicada. Conserve el código de radicado para consultar su trámite.",
+		"receipt":   receipt,
+		"complaint": filed,
+	})
+}
+
+// GetContractComplaints returns the complaints filed on a contract
+func (h *ComplaintHandler) GetContractComplaints(c *gin.Context) {
+	contract, err := h.services.Blockchain.GetContract(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	complaints := h.services.Blockchain.GetComplaints(blockchain.ComplaintFilter{ContractID: contract.ID})
+	c.JSON(http.StatusOK, gin.H{
+		"contract_id": contract.ID,
+		"count":       len(complaints),
+		"complaints":  complaints,
+	})
+}
+
+// GetComplaints lists complaints by contract, entity, status or control entity
+func (h *ComplaintHandler) GetComplaints(c *gin.Context) {
+	filter := blockchain.ComplaintFilter{
+		ContractID: c.Query("contract_id"),
+		EntityCode: c.Query("entity_code"),
+		Status:     blockchain.ComplaintStatus(strings.ToUpper(c.Query("status"))),
+		Role:       blockchain.AdminRole(strings.ToUpper(c.Query("role"))),
+	}
+
+	complaints := h.services.Blockchain.GetComplaints(filter)
+	c.JSON(http.StatusOK, gin.H{
+		"count":      len(complaints),
+		"complaints": complaints,
+	})
+}
+
+// GetInbox lists the pending complaints this control node is competent to handle
+func (h *ComplaintHandler) GetInbox(c *gin.Context) {
+	role := blockchain.AdminRole(strings.ToUpper(c.Query("role")))
+	complaints, err := h.services.Workflow.ComplaintInbox(role)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"role":       role,
+		"count":      len(complaints),
+		"complaints": complaints,
+	})
+}
+
+// GetComplaint returns a complaint and its processing history
+func (h *ComplaintHandler) GetComplaint(c *gin.Context) {
+	complaint := h.services.Blockchain.GetComplaint(c.Param("id"))
+	if complaint == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "denuncia no encontrada"})
+		return
+	}
+	c.JSON(http.StatusOK, complaint)
+}
+
+// TrackComplaint returns the complaint matching a filing receipt
+func (h *ComplaintHandler) TrackComplaint(c *gin.Context) {
+	complaint := h.services.Blockchain.GetComplaintByReceipt(c.Param("receipt"))
+	if complaint == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "radicado no encontrado"})
+		return
+	}
+	c.JSON(http.StatusOK, complaint)
+}
+
+// GetEvidence serves an evidence file stored on this node
+func (h *ComplaintHandler) GetEvidence(c *gin.Context) {
+	complaint := h.services.Blockchain.GetComplaint(c.Param("id"))
+	if complaint == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "denuncia no encontrada"})
+		return
+	}
+	digest := strings.ToLower(c.Param("sha256"))
+	for _, item := range complaint.Evidence {
+		if item.SHA256 != digest {
+			continue
+		}
+		path, err := h.services.Evidence.Path(digest)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "url": item.URL})
+			return
+		}
+		if item.MediaType != "" {
+			c.Header("Content-Type", item.MediaType)
+		}
+		c.FileAttachment(path, item.Name)
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "la denuncia no tiene esa evidencia"})
+}
+
+// UpdateStatus records the processing of a complaint by the control entity it was routed to
+func (h *ComplaintHandler) UpdateStatus(c *gin.Context) {
+	var req struct {
+		AuditorID   string `json:"auditor_id"`
+		Role        string `json:"role"`
+		Status      string `json:"status"`
+		Note        string `json:"note"`
+		AuditCaseID string `json:"audit_case_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.services.Workflow.UpdateComplaintStatus(c.Param("id"), req.AuditorID, blockchain.AdminRole(req.Role),
+		blockchain.ComplaintStatus(req.Status), req.Note, req.AuditCaseID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Trámite de la denuncia actualizado",
+		"complaint": h.services.Blockchain.GetComplaint(c.Param("id")),
+	})
+}
+
+// rateLimited reports an identity or quota failure
+func (h *ComplaintHandler) rateLimited(c *gin.Context, err error) {
+	var limited *citizen.RateLimitError
+	if errors.As(err, &limited) {
+		c.Header("Retry-After", strconv.Itoa(int(limited.RetryAfter.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
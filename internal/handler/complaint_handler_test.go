@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/citizen"
+	"secop-blockchain/internal/evidence"
+	"secop-blockchain/internal/money"
+	"secop-blockchain/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestAnonymousComplaintThrottleIgnoresForwardedFor sends anonymous complaints
+// from one address, each claiming a different client in X-Forwarded-For
+func TestAnonymousComplaintThrottleIgnoresForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bc := blockchain.NewBlockchain()
+	err := bc.AddContract(&blockchain.Contract{
+		ID:           "C1",
+		EntityCode:   "E1",
+		EntityName:   "Alcaldía",
+		Description:  "Obra",
+		Amount:       money.New(1000000),
+		Currency:     money.COP,
+		CreatedBy:    "u1",
+		ContractType: "OBRA",
+	})
+	if err != nil {
+		t.Fatalf("AddContract: %v", err)
+	}
+	store, err := evidence.NewStore(t.TempDir(), 1<<20, 3)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	const limit = 2
+	h := NewComplaintHandler(&service.Services{
+		Blockchain: bc,
+		Workflow:   bc.WorkflowManager,
+		Citizens:   citizen.NewChannel("", limit, time.Hour, false),
+		Evidence:   store,
+	})
+	router := gin.New()
+	router.POST("/contracts/:id/complaints", h.FileComplaint)
+
+	body := `{"anonymous": true, "subject": "Sobreprecio", "description": "Precios por encima del mercado"}`
+	for i, forwarded := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+		req := httptest.NewRequest(http.MethodPost, "/contracts/C1/complaints", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwarded)
+		req.RemoteAddr = "203.0.113.7:40000"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		want := http.StatusCreated
+		if i >= limit {
+			want = http.StatusTooManyRequests
+		}
+		if rec.Code != want {
+			t.Fatalf("complaint %d with X-Forwarded-For %s: status %d, want %d: %s", i+1, forwarded, rec.Code, want, rec.Body.String())
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
 	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/report"
 	"secop-blockchain/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -20,13 +23,26 @@ func NewContractHandler(services *service.Services) *ContractHandler {
 	}
 }
 
-// GetAll returns all contracts
+// GetAll returns contracts filtered, sorted and paginated by query parameters
 func (h *ContractHandler) GetAll(c *gin.Context) {
-	contracts := h.services.Blockchain.GetAllContracts()
+	query, err := parseContractQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.services.Blockchain.QueryContracts(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"count":   len(contracts),
-		"data":    contracts,
+		"success":     true,
+		"count":       len(page.Contracts),
+		"total":       page.Total,
+		"next_cursor": page.NextCursor,
+		"data":        page.Contracts,
 	})
 }
 
@@ -37,6 +53,10 @@ func (h *ContractHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Region and level decide audit jurisdiction, so they always come from
+	// the node's entity and never from the client
+	contract.EntityRegion = h.services.Config.Entity.Region
+	contract.EntityLevel = h.services.Config.Entity.Level
 
 	err := h.services.Blockchain.AddContract(&contract)
 	if err != nil {
@@ -44,12 +64,6 @@ func (h *ContractHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// Broadcast new block to peers
-	if len(h.services.Blockchain.Chain) > 0 {
-		lastBlock := *h.services.Blockchain.Chain[len(h.services.Blockchain.Chain)-1]
-		go h.services.P2P.BroadcastBlock(lastBlock)
-	}
-
 	c.JSON(http.StatusCreated, gin.H{
 		"success":     true,
 		"message":     "Contrato creado exitosamente",
@@ -77,23 +91,32 @@ func (h *ContractHandler) Validate(c *gin.Context) {
 		return
 	}
 
-	// Broadcast validation block to peers
-	if len(h.services.Blockchain.Chain) > 0 {
-		lastBlock := *h.services.Blockchain.Chain[len(h.services.Blockchain.Chain)-1]
-		go h.services.P2P.BroadcastBlock(lastBlock)
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Validación registrada exitosamente",
 	})
 }
 
-// GetByStatus returns contracts by status
+// GetByStatus returns contracts by status, accepting the same query parameters as GetAll
 func (h *ContractHandler) GetByStatus(c *gin.Context) {
-	status := c.Param("status")
-	contracts := h.services.Blockchain.GetContractsByStatus(blockchain.ContractStatus(status))
-	c.JSON(http.StatusOK, gin.H{"contracts": contracts})
+	query, err := parseContractQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.Status = blockchain.ContractStatus(c.Param("status"))
+
+	page, err := h.services.Blockchain.QueryContracts(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contracts":   page.Contracts,
+		"total":       page.Total,
+		"next_cursor": page.NextCursor,
+	})
 }
 
 // GetByRole returns contracts by role
@@ -101,4 +124,77 @@ func (h *ContractHandler) GetByRole(c *gin.Context) {
 	role := c.Param("role")
 	contracts := h.services.Blockchain.GetContractsByRole(blockchain.AdminRole(role))
 	c.JSON(http.StatusOK, gin.H{"contracts": contracts})
-}
\ No newline at end of file
+}
+// GetByID returns a single contract
+func (h *ContractHandler) GetByID(c *gin.Context) {
+	contract, err := h.services.Blockchain.GetContract(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    contract,
+	})
+}
+
+// GetAuditTrail returns the audit trail of a contract with the block of each event
+func (h *ContractHandler) GetAuditTrail(c *gin.Context) {
+	contractID := c.Param("id")
+	events, err := h.services.Blockchain.GetContractHistory(contractID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contract_id": contractID,
+		"count":       len(events),
+		"audit_trail": events,
+	})
+}
+
+// Export returns the full history of a contract as CSV, signed JSON or signed PDF
+func (h *ContractHandler) Export(c *gin.Context) {
+	contractID := c.Param("id")
+	contractReport, err := report.NewContractReport(h.services.Blockchain, h.services.Config.P2P.NodeID, contractID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", report.FormatJSON)
+	filename := fmt.Sprintf("contrato-%s.%s", contractID, format)
+
+	switch format {
+	case report.FormatCSV:
+		var buf bytes.Buffer
+		if err := contractReport.WriteCSV(&buf); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+
+	case report.FormatJSON:
+		data, err := contractReport.SignedJSON(h.services.Identity)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+
+	case report.FormatPDF:
+		data, signature := contractReport.SignedPDF(h.services.Identity)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Header("X-Report-Digest", signature.Digest)
+		c.Header("X-Report-Signature", signature.Value)
+		c.Header("X-Report-Public-Key", signature.PublicKey)
+		c.Data(http.StatusOK, "application/pdf", data)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato no soportado, use csv, json o pdf"})
+	}
+}
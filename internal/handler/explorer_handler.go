@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExplorerHandler handles block explorer requests
+type ExplorerHandler struct {
+	services *service.Services
+}
+
+// NewExplorerHandler creates a new explorer handler
+func NewExplorerHandler(services *service.Services) *ExplorerHandler {
+	return &ExplorerHandler{
+		services: services,
+	}
+}
+
+// GetBlock returns a block by index or hash
+func (h *ExplorerHandler) GetBlock(c *gin.Context) {
+	ref := c.Param("ref")
+
+	if index, err := strconv.Atoi(ref); err == nil {
+		block, err := h.services.Blockchain.GetBlockByIndex(index)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"block": block})
+		return
+	}
+
+	block := h.services.Blockchain.GetBlockByHash(ref)
+	if block == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bloque no encontrado"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"block": block})
+}
+
+// GetLatestBlocks returns the latest N blocks, newest first
+func (h *ExplorerHandler) GetLatestBlocks(c *gin.Context) {
+	n, err := strconv.Atoi(c.DefaultQuery("n", "10"))
+	if err != nil || n <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "n inválido"})
+		return
+	}
+
+	blocks := h.services.Blockchain.GetLatestBlocks(n)
+	c.JSON(http.StatusOK, gin.H{
+		"blocks": blocks,
+		"count":  len(blocks),
+		"height": h.services.Blockchain.GetBlockchainHeight(),
+	})
+}
+
+// GetBlocksByType returns a page of blocks of the given type
+func (h *ExplorerHandler) GetBlocksByType(c *gin.Context) {
+	limit, descending, ok := h.pageParams(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Blockchain.GetBlocksByType(c.Param("type"), c.Query("cursor"), limit, descending)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetContractBlocks returns a page of blocks touching a contract
+func (h *ExplorerHandler) GetContractBlocks(c *gin.Context) {
+	limit, descending, ok := h.pageParams(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Blockchain.GetBlocksByContract(c.Param("id"), c.Query("cursor"), limit, descending)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetStats returns chain statistics per day, block type and entity
+func (h *ExplorerHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Blockchain.GetChainStats())
+}
+
+// pageParams reads limit and order, writing a 400 response on invalid input
+func (h *ExplorerHandler) pageParams(c *gin.Context) (int, bool, bool) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, false, false
+	}
+	descending, err := parseOrder(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, false, false
+	}
+	return limit, descending, true
+}
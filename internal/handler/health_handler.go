@@ -67,8 +67,18 @@ func (h *HealthHandler) GetReadiness(c *gin.Context) {
 		return
 	}
 	
+	// Do not take traffic before catching up with the network
+	if !h.services.P2P.IsReady() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "not ready",
+			"reason": "initial sync in progress",
+		})
+		return
+	}
+	
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ready",
+		"height": h.services.Blockchain.GetBlockchainHeight(),
 	})
 }
 
@@ -88,6 +98,7 @@ func (h *HealthHandler) Health(c *gin.Context) {
 		"p2p":             h.services.P2P.GetNetworkHealth(),
 		"peers":           len(h.services.P2P.GetPeers()),
 		"blockchain_height": h.services.Blockchain.GetBlockchainHeight(),
+		"scheduler":        h.services.Scheduler.Status(),
 	}
 	
 	c.JSON(http.StatusOK, health)
@@ -105,6 +116,7 @@ func (h *HealthHandler) Stats(c *gin.Context) {
 		"entity_contact":     h.services.Config.Entity.ContactEmail,
 		"budget_authority":   h.services.Config.Entity.BudgetAuthority,
 		"max_contract_value": h.services.Config.Entity.MaxContractValue,
+		"higher_authority_threshold": h.services.Config.Entity.HigherAuthorityThreshold,
 		"blockchain_height":  h.services.Blockchain.GetBlockchainHeight(),
 		"total_contracts":    len(h.services.Blockchain.GetAllContracts()),
 		"total_peers":        len(h.services.P2P.GetPeers()),
@@ -117,11 +129,28 @@ func (h *HealthHandler) Stats(c *gin.Context) {
 	})
 }
 
-// GetBlocks returns blockchain blocks
+// GetBlocks returns blockchain blocks paginated by block index
 func (h *HealthHandler) GetBlocks(c *gin.Context) {
-	chain := h.services.Blockchain.GetChain()
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	descending, err := parseOrder(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.services.Blockchain.GetBlocksPage(c.Query("cursor"), limit, descending)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"blocks": chain,
-		"height": len(chain),
+		"blocks":      page.Blocks,
+		"height":      page.Height,
+		"next_cursor": page.NextCursor,
 	})
 }
\ No newline at end of file
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/money"
+	"secop-blockchain/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ModalityHandler handles contracting modality HTTP requests
+type ModalityHandler struct {
+	services *service.Services
+}
+
+// NewModalityHandler creates a new modality handler
+func NewModalityHandler(services *service.Services) *ModalityHandler {
+	return &ModalityHandler{
+		services: services,
+	}
+}
+
+// GetRules returns the modality rules in effect and the entity's thresholds
+func (h *ModalityHandler) GetRules(c *gin.Context) {
+	rules := h.services.Blockchain.GetModalityRules()
+	limits := h.services.Blockchain.GetEntityLimits()
+
+	budget, err := h.services.Blockchain.ValueInSMMLV(limits.AnnualBudget, money.COP)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	minima, menor, err := rules.Thresholds(budget)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules":                rules.Config(),
+		"annual_budget":        limits.AnnualBudget,
+		"annual_budget_smmlv":  budget,
+		"minima_cuantia_smmlv": minima,
+		"menor_cuantia_smmlv":  menor,
+	})
+}
+
+// Evaluate returns the modality, documents and workflow required for a contract
+func (h *ModalityHandler) Evaluate(c *gin.Context) {
+	amount, err := money.Parse(c.Query("amount"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	currency, err := money.ParseCurrency(c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	decision, err := h.services.Blockchain.EvaluateModality(c.Query("contract_type"), amount, currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, decision)
+}
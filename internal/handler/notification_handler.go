@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/notification"
+	"secop-blockchain/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles notification recipients and logs
+type NotificationHandler struct {
+	services *service.Services
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(services *service.Services) *NotificationHandler {
+	return &NotificationHandler{
+		services: services,
+	}
+}
+
+// AddRecipient registers a user to be notified for a role
+func (h *NotificationHandler) AddRecipient(c *gin.Context) {
+	var req struct {
+		Name       string   `json:"name"`
+		Email      string   `json:"email"`
+		WebhookURL string   `json:"webhook_url"`
+		Role       string   `json:"role"`
+		EntityCode string   `json:"entity_code"`
+		Channels   []string `json:"channels"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.services.Notifications.AddRecipient(notification.Recipient{
+		Name:       req.Name,
+		Email:      req.Email,
+		WebhookURL: req.WebhookURL,
+		Role:       req.Role,
+		EntityCode: req.EntityCode,
+		Channels:   req.Channels,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":   true,
+		"recipient": recipient,
+	})
+}
+
+// GetRecipients returns all recipients
+func (h *NotificationHandler) GetRecipients(c *gin.Context) {
+	recipients := h.services.Notifications.Recipients()
+	c.JSON(http.StatusOK, gin.H{
+		"count":      len(recipients),
+		"recipients": recipients,
+	})
+}
+
+// RemoveRecipient deletes a recipient
+func (h *NotificationHandler) RemoveRecipient(c *gin.Context) {
+	if err := h.services.Notifications.RemoveRecipient(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Destinatario eliminado exitosamente"})
+}
+
+// GetLog returns sent and failed notifications
+func (h *NotificationHandler) GetLog(c *gin.Context) {
+	records := h.services.Notifications.Records()
+	c.JSON(http.StatusOK, gin.H{
+		"count":         len(records),
+		"notifications": records,
+	})
+}
+
+// SendDigest sends the pending-work digest immediately
+func (h *NotificationHandler) SendDigest(c *gin.Context) {
+	sent := h.services.Notifications.SendDigests()
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Resumen enviado",
+		"recipients": sent,
+	})
+}
+
+// GetTestOutbox returns the emails captured by the local test SMTP server
+func (h *NotificationHandler) GetTestOutbox(c *gin.Context) {
+	messages, err := h.services.Notifications.TestOutbox()
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(messages),
+		"messages": messages,
+	})
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"secop-blockchain/internal/blockchain"
 	"secop-blockchain/internal/service"
@@ -77,14 +79,7 @@ func (h *P2PHandler) BroadcastBlock(c *gin.Context) {
 	blockHash := c.Param("hash")
 	
 	// Find the block by hash in the blockchain
-	var targetBlock *blockchain.Block
-	for _, block := range h.services.Blockchain.Chain {
-		if block.Hash == blockHash {
-			targetBlock = block
-			break
-		}
-	}
-	
+	targetBlock := h.services.Blockchain.GetBlockByHash(blockHash)
 	if targetBlock == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Bloque no encontrado"})
 		return
@@ -102,6 +97,28 @@ func (h *P2PHandler) GetNetworkHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, health)
 }
 
+// ExchangePeers answers a peer's gossip message with the peers this node has verified
+func (h *P2PHandler) ExchangePeers(c *gin.Context) {
+	var msg blockchain.PeerExchange
+	if err := c.ShouldBindJSON(&msg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// RemoteIP rather than ClientIP: forwarded headers are chosen by the sender
+	response, err := h.services.P2P.PeerDiscovery.HandleExchange(msg, c.RemoteIP())
+	if errors.Is(err, blockchain.ErrPeerExchangeDisabled) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // GetChain returns the blockchain
 func (h *P2PHandler) GetChain(c *gin.Context) {
 	chain := h.services.Blockchain.GetChain()
@@ -119,33 +136,60 @@ func (h *P2PHandler) ReceiveBlock(c *gin.Context) {
 		return
 	}
 
-	// Validate and add the block
-	if !h.services.Blockchain.IsValidBlock(block) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bloque inválido"})
+	// Blocks from banned peers are refused until the ban expires. Without a
+	// verifiable node ID the sender is recognized by its address; RemoteIP
+	// rather than ClientIP, forwarded headers are chosen by the sender
+	sender := c.GetHeader(blockchain.NodeIDHeader)
+	if h.services.P2P.BannedSender(sender, c.RemoteIP()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Peer bloqueado temporalmente"})
 		return
 	}
 
-	// Check if we already have this block
-	if h.services.Blockchain.HasBlock(block.Hash) {
-		c.JSON(http.StatusOK, gin.H{"message": "Bloque ya existe"})
+	// A hash that does not match the contents is a protocol violation
+	if !block.IsValid() {
+		if h.services.P2P.KnownSender(sender, c.RemoteIP()) {
+			h.services.P2P.ReportViolation(sender, fmt.Sprintf("bloque %d con hash que no corresponde a su contenido", block.Index))
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bloque inválido"})
 		return
 	}
 
-	// Add block to blockchain
-	blockData := block.Data
-	if blockData == nil {
-		blockData = make(map[string]interface{})
+	// Add the block keeping its hash; blocks already in the chain are accepted
+	// again so the sender can safely retry
+	err := h.services.P2P.ReceiveBlock(block)
+	if errors.Is(err, blockchain.ErrBlockAhead) {
+		// The sender answers a conflict by sending the missing blocks first
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  err.Error(),
+			"height": h.services.Blockchain.GetBlockchainHeight(),
+		})
+		return
+	}
+	if errors.Is(err, blockchain.ErrInvalidBlockData) {
+		// A well-formed block that breaks the budget balances is a protocol violation
+		if h.services.P2P.KnownSender(sender, c.RemoteIP()) {
+			h.services.P2P.ReportViolation(sender, fmt.Sprintf("bloque %d: %v", block.Index, err))
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	
-	_, err := h.services.Blockchain.AddBlock(blockData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bloque inválido"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Bloque recibido y agregado"})
 }
 
+// Unban lifts a peer's temporary ban
+func (h *P2PHandler) Unban(c *gin.Context) {
+	if err := h.services.P2P.Unban(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bloqueo levantado"})
+}
+
 // Sync synchronizes the blockchain with peers
 func (h *P2PHandler) Sync(c *gin.Context) {
 	err := h.services.P2P.SyncBlockchain()
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/money"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseContractQuery builds a contract query from request query parameters
+func parseContractQuery(c *gin.Context) (blockchain.ContractQuery, error) {
+	query := blockchain.ContractQuery{
+		EntityCode:   c.Query("entity_code"),
+		ContractType: c.Query("contract_type"),
+		Status:       blockchain.ContractStatus(c.Query("status")),
+		CreatedBy:    c.Query("created_by"),
+		SortBy:       c.DefaultQuery("sort", blockchain.SortByCreatedAt),
+		Cursor:       c.Query("cursor"),
+	}
+
+	order, err := parseOrder(c)
+	if err != nil {
+		return query, err
+	}
+	query.Descending = order
+
+	if query.Limit, err = parseLimit(c); err != nil {
+		return query, err
+	}
+
+	// Amount filters are in pesos, regardless of the contract currency
+	if value := c.Query("min_amount"); value != "" {
+		amount, err := money.Parse(value)
+		if err != nil {
+			return query, fmt.Errorf("min_amount inválido: %s", value)
+		}
+		query.MinAmount = &amount
+	}
+	if value := c.Query("max_amount"); value != "" {
+		amount, err := money.Parse(value)
+		if err != nil {
+			return query, fmt.Errorf("max_amount inválido: %s", value)
+		}
+		query.MaxAmount = &amount
+	}
+
+	if value := c.Query("from"); value != "" {
+		if query.CreatedFrom, err = parseDateParam(value, false); err != nil {
+			return query, fmt.Errorf("from inválido: %s", value)
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if query.CreatedTo, err = parseDateParam(value, true); err != nil {
+			return query, fmt.Errorf("to inválido: %s", value)
+		}
+	}
+
+	return query, nil
+}
+
+// parseLimit reads the page size parameter
+func parseLimit(c *gin.Context) (int, error) {
+	value := c.Query("limit")
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("limit inválido: %s", value)
+	}
+	return limit, nil
+}
+
+// parseOrder reads the sort order parameter, returning true for descending
+func parseOrder(c *gin.Context) (bool, error) {
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+		return false, nil
+	case "desc":
+		return true, nil
+	default:
+		return false, fmt.Errorf("order inválido: %s", c.Query("order"))
+	}
+}
+
+// parseDateParam parses a date (YYYY-MM-DD) or RFC3339 timestamp in Colombian time.
+// Plain dates used as upper bounds include the whole day.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return config.ToColombianTime(t), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, config.ColombianTimezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"secop-blockchain/internal/registry"
+	"secop-blockchain/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegistryHandler serves the peer registry when the node runs in registry mode
+type RegistryHandler struct {
+	services *service.Services
+}
+
+// NewRegistryHandler creates a new registry handler
+func NewRegistryHandler(services *service.Services) *RegistryHandler {
+	return &RegistryHandler{
+		services: services,
+	}
+}
+
+// GetPeers returns the active registered nodes, optionally filtered by entity type.
+// The response is a plain array, as expected by the discovery client.
+func (h *RegistryHandler) GetPeers(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Registry.Peers(c.Query("entity_type")))
+}
+
+// GetStatus returns every registry entry, including expired ones
+func (h *RegistryHandler) GetStatus(c *gin.Context) {
+	entries := h.services.Registry.Entries()
+	active := 0
+	for _, entry := range entries {
+		if entry.IsActive {
+			active++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ttl":     h.services.Registry.TTL().String(),
+		"total":   len(entries),
+		"active":  active,
+		"entries": entries,
+	})
+}
+
+// Register adds or refreshes a node in the registry
+func (h *RegistryHandler) Register(c *gin.Context) {
+	var registration registry.Registration
+	if err := c.ShouldBindJSON(&registration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// RemoteIP rather than ClientIP: forwarded headers are chosen by the sender
+	entry, err := h.services.Registry.Register(registration, c.RemoteIP())
+	if errors.Is(err, registry.ErrNotOwner) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Nodo registrado",
+		"peer":    entry,
+		"ttl":     h.services.Registry.TTL().String(),
+	})
+}
+
+// Heartbeat keeps a node's registration alive
+func (h *RegistryHandler) Heartbeat(c *gin.Context) {
+	entry, err := h.services.Registry.Heartbeat(c.Param("id"))
+	if errors.Is(err, registry.ErrUnknownPeer) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"peer": entry,
+		"ttl":  h.services.Registry.TTL().String(),
+	})
+}
+
+// Unregister removes a node from the registry. Nodes with a key sign the
+// request; the others can only unregister from their registered address.
+func (h *RegistryHandler) Unregister(c *gin.Context) {
+	timestamp, _ := strconv.ParseInt(c.Query("timestamp"), 10, 64)
+	// RemoteIP rather than ClientIP: forwarded headers are chosen by the sender
+	err := h.services.Registry.Unregister(c.Param("id"), timestamp, c.Query("signature"), c.RemoteIP())
+	if errors.Is(err, registry.ErrUnknownPeer) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, registry.ErrNotOwner) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Nodo dado de baja"})
+}
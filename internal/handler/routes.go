@@ -29,6 +29,19 @@ func SetupRoutes(cfg *config.Config, services *service.Services) *gin.Engine {
 	workflowHandler := NewWorkflowHandler(services)
 	p2pHandler := NewP2PHandler(services)
 	healthHandler := NewHealthHandler(services)
+	searchHandler := NewSearchHandler(services)
+	explorerHandler := NewExplorerHandler(services)
+	streamHandler := NewStreamHandler(services)
+	webhookHandler := NewWebhookHandler(services)
+	notificationHandler := NewNotificationHandler(services)
+	calendarHandler := NewCalendarHandler(services)
+	budgetHandler := NewBudgetHandler(services)
+	modalityHandler := NewModalityHandler(services)
+	supplierHandler := NewSupplierHandler(services)
+	analyticsHandler := NewAnalyticsHandler(services)
+	auditHandler := NewAuditHandler(services)
+	citizenHandler := NewCitizenHandler(services)
+	complaintHandler := NewComplaintHandler(services)
 
 	// API Routes
 	api := r.Group("/api")
@@ -41,31 +54,175 @@ func SetupRoutes(cfg *config.Config, services *service.Services) *gin.Engine {
 			contracts.POST("/validate", contractHandler.Validate)
 			contracts.GET("/by-status/:status", contractHandler.GetByStatus)
 			contracts.GET("/by-role/:role", contractHandler.GetByRole)
+			contracts.GET("/:id", contractHandler.GetByID)
+			contracts.GET("/:id/audit-trail", contractHandler.GetAuditTrail)
+			contracts.GET("/:id/export", contractHandler.Export)
 		}
 
 		// Workflow routes
 		workflow := api.Group("/workflow")
 		{
 			workflow.GET("/steps", workflowHandler.GetSteps)
+			workflow.GET("/overdue", workflowHandler.GetOverdue)
+			workflow.POST("/check-deadlines", workflowHandler.CheckDeadlines)
 		}
 
 		// Contract workflow routes
 		api.GET("/contracts/:id/workflow", workflowHandler.GetContractStatus)
 		api.POST("/contracts/:id/validate-step", workflowHandler.ValidateStep)
 		api.POST("/contracts/:id/audit", workflowHandler.AddAudit)
+		api.POST("/contracts/:id/documents", workflowHandler.AttachDocuments)
+		api.POST("/contracts/:id/award", supplierHandler.AwardContract)
+		api.POST("/contracts/:id/audit-cases", auditHandler.OpenCase)
+		api.GET("/contracts/:id/audit-cases", auditHandler.GetContractCases)
+		api.POST("/contracts/:id/citizen-observations", citizenHandler.AddObservation)
+		api.POST("/contracts/:id/complaints", complaintHandler.FileComplaint)
+		api.GET("/contracts/:id/complaints", complaintHandler.GetContractComplaints)
+
+		// Citizen complaint routes
+		complaints := api.Group("/complaints")
+		{
+			complaints.GET("", complaintHandler.GetComplaints)
+			complaints.GET("/inbox", complaintHandler.GetInbox)
+			complaints.GET("/track/:receipt", complaintHandler.TrackComplaint)
+			complaints.GET("/:id", complaintHandler.GetComplaint)
+			complaints.GET("/:id/evidence/:sha256", complaintHandler.GetEvidence)
+			complaints.POST("/:id/status", complaintHandler.UpdateStatus)
+		}
+
+		// Citizen identity routes
+		citizens := api.Group("/citizen")
+		{
+			citizens.GET("/channel", citizenHandler.GetChannel)
+			citizens.POST("/token", citizenHandler.IssueToken)
+		}
+
+		// Audit case routes for control entities
+		audits := api.Group("/audits")
+		{
+			audits.GET("", auditHandler.GetCases)
+			audits.GET("/:id", auditHandler.GetCase)
+			audits.POST("/:id/findings", auditHandler.AddFinding)
+			audits.POST("/:id/findings/:finding_id/response", auditHandler.RespondFinding)
+			audits.POST("/:id/close", auditHandler.CloseCase)
+		}
+
+		// Peer registry routes, served only in registry mode
+		if services.Registry != nil {
+			registryHandler := NewRegistryHandler(services)
+			peers := api.Group("/peers")
+			{
+				peers.GET("", registryHandler.GetPeers)
+				peers.GET("/status", registryHandler.GetStatus)
+				peers.POST("/register", registryHandler.Register)
+				peers.POST("/heartbeat/:id", registryHandler.Heartbeat)
+				peers.DELETE("/unregister/:id", registryHandler.Unregister)
+			}
+		}
 
 		// P2P routes
 		p2p := api.Group("/p2p")
 		{
 			p2p.GET("/peers", p2pHandler.GetPeers)
+			p2p.POST("/peers/:id/unban", p2pHandler.Unban)
 			p2p.POST("/add-peer", p2pHandler.AddPeer)
 			p2p.GET("/get-chain", p2pHandler.GetChain)
 			p2p.POST("/receive-block", p2pHandler.ReceiveBlock)
 			p2p.POST("/sync", p2pHandler.Sync)
+			p2p.POST("/peer-exchange", p2pHandler.ExchangePeers)
 		}
 
+		// Block explorer routes
+		explorer := api.Group("/explorer")
+		{
+			explorer.GET("/blocks/latest", explorerHandler.GetLatestBlocks)
+			explorer.GET("/blocks/type/:type", explorerHandler.GetBlocksByType)
+			explorer.GET("/blocks/:ref", explorerHandler.GetBlock)
+			explorer.GET("/contracts/:id/blocks", explorerHandler.GetContractBlocks)
+			explorer.GET("/stats", explorerHandler.GetStats)
+		}
+
+		// Webhook routes
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.POST("", webhookHandler.Register)
+			webhooks.GET("", webhookHandler.List)
+			webhooks.GET("/dead-letters", webhookHandler.GetDeadLetters)
+			webhooks.POST("/deliveries/:id/retry", webhookHandler.RetryDelivery)
+			webhooks.GET("/:id", webhookHandler.Get)
+			webhooks.DELETE("/:id", webhookHandler.Delete)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+		}
+
+		// Notification routes
+		notifications := api.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.GetLog)
+			notifications.POST("/recipients", notificationHandler.AddRecipient)
+			notifications.GET("/recipients", notificationHandler.GetRecipients)
+			notifications.DELETE("/recipients/:id", notificationHandler.RemoveRecipient)
+			notifications.POST("/digest", notificationHandler.SendDigest)
+			notifications.GET("/test-outbox", notificationHandler.GetTestOutbox)
+		}
+
+		// Budget ledger routes
+		budget := api.Group("/budget")
+		{
+			budget.POST("/appropriations", budgetHandler.CreateAppropriation)
+			budget.GET("/appropriations", budgetHandler.GetAppropriations)
+			budget.GET("/appropriations/:id", budgetHandler.GetAppropriation)
+			budget.POST("/cdps", budgetHandler.IssueCDP)
+			budget.GET("/cdps", budgetHandler.GetCDPs)
+			budget.GET("/cdps/:id", budgetHandler.GetCDP)
+			budget.POST("/cdps/:id/release", budgetHandler.ReleaseCDP)
+			budget.POST("/commitments", budgetHandler.RegisterCommitment)
+			budget.GET("/contracts/:id", budgetHandler.GetContractBudget)
+		}
+
+		// Supplier registry routes
+		suppliers := api.Group("/suppliers")
+		{
+			suppliers.POST("", supplierHandler.RegisterSupplier)
+			suppliers.GET("", supplierHandler.GetSuppliers)
+			suppliers.GET("/:nit", supplierHandler.GetSupplier)
+			suppliers.PUT("/:nit", supplierHandler.UpdateSupplier)
+		}
+
+		// Red-flag analytics routes for control entities
+		analyticsRoutes := api.Group("/analytics")
+		{
+			analyticsRoutes.GET("/alerts", analyticsHandler.GetAlerts)
+			analyticsRoutes.GET("/risk", analyticsHandler.GetRanking)
+			analyticsRoutes.GET("/contracts/:id/risk", analyticsHandler.GetContractRisk)
+			analyticsRoutes.GET("/config", analyticsHandler.GetConfig)
+		}
+
+		// Contracting modality routes
+		modalityRoutes := api.Group("/modality")
+		{
+			modalityRoutes.GET("/rules", modalityHandler.GetRules)
+			modalityRoutes.GET("/evaluate", modalityHandler.Evaluate)
+		}
+
+		// Business-day calendar routes
+		calendarRoutes := api.Group("/calendar")
+		{
+			calendarRoutes.GET("/holidays/:year", calendarHandler.GetHolidays)
+			calendarRoutes.GET("/business-day", calendarHandler.GetBusinessDay)
+			calendarRoutes.GET("/add-business-days", calendarHandler.AddBusinessDays)
+			calendarRoutes.GET("/business-days", calendarHandler.CountBusinessDays)
+		}
+
+		// Event stream routes
+		api.GET("/stream", streamHandler.Stream)
+
+		// Search routes
+		api.GET("/search", searchHandler.Search)
+
 		// Health and stats routes
 		api.GET("/health", healthHandler.Health)
+		api.GET("/health/ready", healthHandler.GetReadiness)
+		api.GET("/health/live", healthHandler.GetLiveness)
 		api.GET("/stats", healthHandler.Stats)
 		api.GET("/blocks", healthHandler.GetBlocks)
 	}
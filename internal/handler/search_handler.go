@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler handles full-text search requests
+type SearchHandler struct {
+	services *service.Services
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(services *service.Services) *SearchHandler {
+	return &SearchHandler{
+		services: services,
+	}
+}
+
+// Search returns contracts ranked by relevance with highlighted matches
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parámetro q requerido"})
+		return
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if limit == 0 {
+		limit = 20
+	}
+
+	var fields []string
+	if value := c.Query("fields"); value != "" {
+		fields = strings.Split(value, ",")
+	}
+
+	results := h.services.Search.Search(query, fields, limit)
+	c.JSON(http.StatusOK, gin.H{
+		"query":   query,
+		"count":   len(results),
+		"results": results,
+	})
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"secop-blockchain/internal/service"
+	"secop-blockchain/internal/stream"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamKeepAlive is how often a comment is sent to keep idle connections open
+const streamKeepAlive = 15 * time.Second
+
+// StreamHandler handles Server-Sent Events subscriptions
+type StreamHandler struct {
+	services *service.Services
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(services *service.Services) *StreamHandler {
+	return &StreamHandler{
+		services: services,
+	}
+}
+
+// Stream sends chain events as Server-Sent Events. Clients may resume with
+// from_height or the Last-Event-ID header.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	filter := stream.Filter{
+		Role:       c.Query("role"),
+		ContractID: c.Query("contract_id"),
+		EntityCode: c.Query("entity_code"),
+	}
+	if types := c.Query("types"); types != "" {
+		filter.Types = make(map[string]bool)
+		for _, eventType := range strings.Split(types, ",") {
+			filter.Types[strings.TrimSpace(eventType)] = true
+		}
+	}
+
+	fromHeight := -1
+	if value := c.Query("from_height"); value != "" {
+		height, err := strconv.Atoi(value)
+		if err != nil || height < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from_height inválido"})
+			return
+		}
+		fromHeight = height
+	}
+	if value := c.GetHeader("Last-Event-ID"); value != "" {
+		if lastID, err := strconv.Atoi(value); err == nil {
+			fromHeight = lastID + 1
+		}
+	}
+
+	// Subscribe before replaying so no block is lost in between
+	sub := h.services.Stream.Subscribe(filter)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	lastSent := -1
+	if fromHeight >= 0 {
+		for _, event := range h.services.Stream.Replay(fromHeight, filter) {
+			writeEvent(c.Writer, event)
+			lastSent = event.ID
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			return true
+		case event, ok := <-sub.Events:
+			if !ok {
+				return false
+			}
+			// Skip events already delivered by the replay
+			if event.ID <= lastSent {
+				return true
+			}
+			writeEvent(w, event)
+			return true
+		}
+	})
+}
+
+// writeEvent writes an event in SSE wire format
+func writeEvent(w io.Writer, event stream.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierHandler handles supplier registry and award HTTP requests
+type SupplierHandler struct {
+	services *service.Services
+}
+
+// NewSupplierHandler creates a new supplier handler
+func NewSupplierHandler(services *service.Services) *SupplierHandler {
+	return &SupplierHandler{
+		services: services,
+	}
+}
+
+// supplierRequest is the body for registering or updating a supplier
+type supplierRequest struct {
+	NIT                 string                         `json:"nit"` // Con dígito de verificación, ej: 900123456-8
+	Name                string                         `json:"name"`
+	PersonType          string                         `json:"person_type"`
+	LegalRepresentative blockchain.LegalRepresentative `json:"legal_representative"`
+	RUP                 *blockchain.RUP                `json:"rup"`
+	Email               string                         `json:"email"`
+	City                string                         `json:"city"`
+	RegisteredBy        string                         `json:"registered_by"`
+}
+
+func (r supplierRequest) supplier() *blockchain.Supplier {
+	return &blockchain.Supplier{
+		Name:                r.Name,
+		PersonType:          r.PersonType,
+		LegalRepresentative: r.LegalRepresentative,
+		RUP:                 r.RUP,
+		Email:               r.Email,
+		City:                r.City,
+		RegisteredBy:        r.RegisteredBy,
+	}
+}
+
+// RegisterSupplier registers a supplier on the chain
+func (h *SupplierHandler) RegisterSupplier(c *gin.Context) {
+	var req supplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	supplier := req.supplier()
+	if err := h.services.Blockchain.RegisterSupplier(supplier, req.NIT); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":  true,
+		"supplier": supplier,
+	})
+}
+
+// UpdateSupplier records new representative, RUP or contact data for a supplier
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	var req supplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	supplier := req.supplier()
+	if err := h.services.Blockchain.UpdateSupplier(c.Param("nit"), supplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"supplier": supplier,
+	})
+}
+
+// GetSuppliers lists suppliers, optionally filtered by name or NIT prefix
+func (h *SupplierHandler) GetSuppliers(c *gin.Context) {
+	suppliers := h.services.Blockchain.GetSuppliers(c.Query("q"))
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(suppliers),
+		"suppliers": suppliers,
+	})
+}
+
+// GetSupplier returns a supplier with its RUP and sanctions status
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	if h.services.Blockchain.GetSupplier(c.Param("nit")) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "proveedor no encontrado"})
+		return
+	}
+	check, err := h.services.Blockchain.CheckSupplier(c.Param("nit"))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, check)
+}
+
+// AwardContract awards an authorized contract to a registered supplier
+func (h *SupplierHandler) AwardContract(c *gin.Context) {
+	contractID := c.Param("id")
+
+	var req struct {
+		SupplierNIT       string `json:"supplier_nit"`
+		UserID            string `json:"user_id"`
+		Role              string `json:"role"`
+		ProposalsReceived int    `json:"proposals_received"`
+		Comments          string `json:"comments"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role := blockchain.AdminRole(req.Role)
+	err := h.services.Workflow.AwardContract(contractID, req.SupplierNIT, req.UserID, role, req.ProposalsReceived, req.Comments)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contract, _ := h.services.Blockchain.GetContract(contractID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Contrato adjudicado",
+		"contract": contract,
+	})
+}
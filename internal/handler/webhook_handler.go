@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"secop-blockchain/internal/service"
+	"secop-blockchain/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles outbound webhook management requests
+type WebhookHandler struct {
+	services *service.Services
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(services *service.Services) *WebhookHandler {
+	return &WebhookHandler{
+		services: services,
+	}
+}
+
+// Register registers a new webhook and returns its signing secret
+func (h *WebhookHandler) Register(c *gin.Context) {
+	var req struct {
+		URL        string   `json:"url"`
+		EventTypes []string `json:"event_types"`
+		EntityCode string   `json:"entity_code"`
+		Role       string   `json:"role"`
+		Secret     string   `json:"secret"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hook, err := h.services.Webhooks.Register(webhook.Webhook{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		EntityCode: req.EntityCode,
+		Role:       req.Role,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The secret is only returned once, at registration time
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"webhook": hook,
+		"secret":  hook.Secret,
+	})
+}
+
+// List returns all registered webhooks
+func (h *WebhookHandler) List(c *gin.Context) {
+	hooks := h.services.Webhooks.List()
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(hooks),
+		"webhooks": hooks,
+	})
+}
+
+// Get returns a webhook
+func (h *WebhookHandler) Get(c *gin.Context) {
+	hook, err := h.services.Webhooks.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhook": hook})
+}
+
+// Delete removes a webhook
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.services.Webhooks.Unregister(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook eliminado exitosamente"})
+}
+
+// GetDeliveries returns the delivery log of a webhook
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	webhookID := c.Param("id")
+	if _, err := h.services.Webhooks.Get(webhookID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries := h.services.Webhooks.Deliveries(webhookID)
+	c.JSON(http.StatusOK, gin.H{
+		"count":      len(deliveries),
+		"deliveries": deliveries,
+	})
+}
+
+// GetDeadLetters returns deliveries that exhausted their retries
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	deliveries := h.services.Webhooks.DeadLetters()
+	c.JSON(http.StatusOK, gin.H{
+		"count":      len(deliveries),
+		"deliveries": deliveries,
+	})
+}
+
+// RetryDelivery requeues a dead-letter delivery
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	if err := h.services.Webhooks.Retry(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Entrega reprogramada"})
+}
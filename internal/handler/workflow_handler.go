@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/money"
 	"secop-blockchain/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -20,9 +21,25 @@ func NewWorkflowHandler(services *service.Services) *WorkflowHandler {
 	}
 }
 
-// GetSteps returns workflow steps
+// GetSteps returns workflow steps, optionally for a given contract type and amount
 func (h *WorkflowHandler) GetSteps(c *gin.Context) {
 	steps := h.services.Workflow.GetWorkflowSteps()
+	if value := c.Query("amount"); value != "" {
+		amount, err := money.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		currency, err := money.ParseCurrency(c.Query("currency"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if steps, err = h.services.Workflow.GetWorkflowStepsFor(c.Query("contract_type"), amount, currency); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"steps": steps})
 }
 
@@ -88,4 +105,53 @@ func (h *WorkflowHandler) AddAudit(c *gin.Context) {
 	}
 	
 	c.JSON(http.StatusOK, gin.H{"message": "Observación de auditoría agregada"})
-}
\ No newline at end of file
+}
+
+// AttachDocuments records documents provided for a contract
+func (h *WorkflowHandler) AttachDocuments(c *gin.Context) {
+	contractID := c.Param("id")
+	
+	var req struct {
+		UserID    string   `json:"user_id"`
+		Role      string   `json:"role"`
+		Documents []string `json:"documents"`
+	}
+	
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	
+	role := blockchain.AdminRole(req.Role)
+	err := h.services.Workflow.AttachDocuments(contractID, req.UserID, role, req.Documents)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	
+	contract, _ := h.services.Blockchain.GetContract(contractID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Documentos registrados",
+		"documents":          contract.Documents,
+		"required_documents": contract.RequiredDocuments,
+	})
+}
+
+// GetOverdue returns overdue workflow steps grouped by entity and role
+func (h *WorkflowHandler) GetOverdue(c *gin.Context) {
+	report := h.services.Workflow.GetOverdueReport(c.Query("entity_code"), blockchain.AdminRole(c.Query("role")))
+	c.JSON(http.StatusOK, report)
+}
+
+// CheckDeadlines escalates overdue steps immediately
+func (h *WorkflowHandler) CheckDeadlines(c *gin.Context) {
+	escalated, err := h.services.Workflow.CheckDeadlines()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Revisión de plazos completada",
+		"escalated": escalated,
+	})
+}
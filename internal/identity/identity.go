@@ -0,0 +1,68 @@
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+)
+
+// Identity is the node's signing key pair
+type Identity struct {
+	NodeID     string
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// Load builds the node identity from a hex-encoded ed25519 seed. When no seed
+// is configured an ephemeral key is generated, so signatures change on restart.
+func Load(nodeID, seedHex string) (*Identity, error) {
+	var seed []byte
+	if seedHex == "" {
+		seed = make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+		log.Printf("NODE_SIGNING_KEY not configured, using an ephemeral signing key for node %s", nodeID)
+	} else {
+		decoded, err := hex.DecodeString(seedHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signing key: %v", err)
+		}
+		if len(decoded) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid signing key: expected %d bytes, got %d", ed25519.SeedSize, len(decoded))
+		}
+		seed = decoded
+	}
+
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	return &Identity{
+		NodeID:     nodeID,
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+// PublicKey returns the hex-encoded public key
+func (id *Identity) PublicKey() string {
+	return hex.EncodeToString(id.publicKey)
+}
+
+// Sign returns the hex-encoded signature of data
+func (id *Identity) Sign(data []byte) string {
+	return hex.EncodeToString(ed25519.Sign(id.privateKey, data))
+}
+
+// Verify checks a hex-encoded signature against a hex-encoded public key
+func Verify(publicKeyHex string, data []byte, signatureHex string) bool {
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(publicKey, data, signature)
+}
@@ -0,0 +1,242 @@
+// Package modality decides the contracting modality (modalidad de selección)
+// that Colombian procurement law requires for a contract.
+//
+// Rules are evaluated in order and the first match wins. They are defined in
+// JSON; the embedded rules.json follows Ley 1150 de 2007 and Decreto 1082 de
+// 2015 and can be replaced with a file of the same shape.
+package modality
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"secop-blockchain/internal/money"
+	"sort"
+	"strings"
+)
+
+// Named thresholds usable in a rule's up_to field
+const (
+	ThresholdMinimaCuantia = "MINIMA_CUANTIA"
+	ThresholdMenorCuantia  = "MENOR_CUANTIA"
+)
+
+// DefaultWorkflow is the template used when a rule does not name one
+const DefaultWorkflow = "standard"
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Config is the JSON definition of the rules
+type Config struct {
+	MinimaCuantiaPercent money.Decimal     `json:"minima_cuantia_percent"`
+	MenorCuantia         []Bracket         `json:"menor_cuantia"`
+	Workflows            map[string][]Step `json:"workflows"`
+	Rules                []Rule            `json:"rules"`
+}
+
+// Bracket sets the menor cuantía ceiling for entities whose annual budget is
+// at least MinBudgetSMMLV
+type Bracket struct {
+	MinBudgetSMMLV money.Decimal `json:"min_budget_smmlv"`
+	MaxValueSMMLV  money.Decimal `json:"max_value_smmlv"`
+}
+
+// Step is a validation step of a workflow template
+type Step struct {
+	Role string `json:"role"`
+	Name string `json:"name"`
+}
+
+// Rule maps contract types and values to a modality
+type Rule struct {
+	Name              string   `json:"name"`
+	ContractTypes     []string `json:"contract_types,omitempty"` // Vacío aplica a cualquier tipo
+	UpTo              string   `json:"up_to,omitempty"`          // MINIMA_CUANTIA, MENOR_CUANTIA o un valor en SMMLV
+	Modality          string   `json:"modality"`
+	Workflow          string   `json:"workflow,omitempty"`
+	RequiredDocuments []string `json:"required_documents"`
+}
+
+// Input describes the contract being evaluated
+type Input struct {
+	ContractType      string
+	ValueSMMLV        money.Decimal // Valor del contrato en SMMLV
+	EntityBudgetSMMLV money.Decimal // Presupuesto anual de la entidad en SMMLV
+}
+
+// Decision is the result of evaluating the rules
+type Decision struct {
+	Rule               string        `json:"rule"`
+	Modality           string        `json:"modality"`
+	Workflow           string        `json:"workflow"`
+	Steps              []Step        `json:"steps"`
+	RequiredDocuments  []string      `json:"required_documents"`
+	ValueSMMLV         money.Decimal `json:"value_smmlv"`
+	MinimaCuantiaSMMLV money.Decimal `json:"minima_cuantia_smmlv"`
+	MenorCuantiaSMMLV  money.Decimal `json:"menor_cuantia_smmlv"`
+}
+
+// Engine evaluates modality rules
+type Engine struct {
+	config Config
+}
+
+// Default returns the engine with the embedded rules
+func Default() *Engine {
+	engine, err := Parse(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("reglas de modalidad incorporadas inválidas: %v", err))
+	}
+	return engine
+}
+
+// Load reads rules from a JSON file. An empty path returns the embedded rules.
+func Load(path string) (*Engine, error) {
+	if path == "" {
+		return Default(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo reglas de modalidad: %v", err)
+	}
+	return Parse(data)
+}
+
+// Parse builds an engine from JSON rules and validates them
+func Parse(data []byte) (*Engine, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("reglas de modalidad inválidas: %v", err)
+	}
+
+	if len(config.MenorCuantia) == 0 {
+		return nil, errors.New("reglas de modalidad sin rangos de menor cuantía")
+	}
+	if len(config.Rules) == 0 {
+		return nil, errors.New("reglas de modalidad vacías")
+	}
+	if _, ok := config.Workflows[DefaultWorkflow]; !ok {
+		return nil, fmt.Errorf("falta el flujo %q", DefaultWorkflow)
+	}
+
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.Modality == "" {
+			return nil, fmt.Errorf("regla %d sin modalidad", i+1)
+		}
+		if rule.Workflow == "" {
+			rule.Workflow = DefaultWorkflow
+		}
+		if _, ok := config.Workflows[rule.Workflow]; !ok {
+			return nil, fmt.Errorf("regla %q usa el flujo desconocido %q", rule.Name, rule.Workflow)
+		}
+		if rule.UpTo != "" && rule.UpTo != ThresholdMinimaCuantia && rule.UpTo != ThresholdMenorCuantia {
+			if _, err := money.Parse(rule.UpTo); err != nil {
+				return nil, fmt.Errorf("regla %q: up_to inválido: %s", rule.Name, rule.UpTo)
+			}
+		}
+		for j, contractType := range rule.ContractTypes {
+			rule.ContractTypes[j] = normalizeType(contractType)
+		}
+	}
+
+	// Los rangos se evalúan del presupuesto más alto al más bajo
+	sort.Slice(config.MenorCuantia, func(i, j int) bool {
+		return config.MenorCuantia[i].MinBudgetSMMLV.GreaterThan(config.MenorCuantia[j].MinBudgetSMMLV)
+	})
+
+	return &Engine{config: config}, nil
+}
+
+// Config returns the rules in effect
+func (e *Engine) Config() Config {
+	return e.config
+}
+
+// Workflow returns the steps of a workflow template
+func (e *Engine) Workflow(name string) ([]Step, bool) {
+	steps, ok := e.config.Workflows[name]
+	return steps, ok
+}
+
+// Thresholds returns the mínima and menor cuantía ceilings in SMMLV for an
+// entity budget
+func (e *Engine) Thresholds(entityBudgetSMMLV money.Decimal) (minima money.Decimal, menor money.Decimal, err error) {
+	for _, bracket := range e.config.MenorCuantia {
+		if !entityBudgetSMMLV.LessThan(bracket.MinBudgetSMMLV) {
+			menor = bracket.MaxValueSMMLV
+			break
+		}
+	}
+	if menor.IsZero() {
+		menor = e.config.MenorCuantia[len(e.config.MenorCuantia)-1].MaxValueSMMLV
+	}
+	percent, err := e.config.MinimaCuantiaPercent.Mul(money.MustParse("0.01"))
+	if err != nil {
+		return money.Zero, money.Zero, err
+	}
+	minima, err = menor.Mul(percent)
+	if err != nil {
+		return money.Zero, money.Zero, err
+	}
+	return minima, menor, nil
+}
+
+// Evaluate returns the modality, documents and workflow required for a contract
+func (e *Engine) Evaluate(input Input) (*Decision, error) {
+	contractType := normalizeType(input.ContractType)
+	minima, menor, err := e.Thresholds(input.EntityBudgetSMMLV)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range e.config.Rules {
+		if len(rule.ContractTypes) > 0 && !contains(rule.ContractTypes, contractType) {
+			continue
+		}
+		if rule.UpTo != "" {
+			ceiling := minima
+			switch rule.UpTo {
+			case ThresholdMinimaCuantia:
+			case ThresholdMenorCuantia:
+				ceiling = menor
+			default:
+				ceiling, _ = money.Parse(rule.UpTo)
+			}
+			if input.ValueSMMLV.GreaterThan(ceiling) {
+				continue
+			}
+		}
+
+		steps, _ := e.Workflow(rule.Workflow)
+		return &Decision{
+			Rule:               rule.Name,
+			Modality:           rule.Modality,
+			Workflow:           rule.Workflow,
+			Steps:              steps,
+			RequiredDocuments:  append([]string{}, rule.RequiredDocuments...),
+			ValueSMMLV:         input.ValueSMMLV,
+			MinimaCuantiaSMMLV: minima,
+			MenorCuantiaSMMLV:  menor,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("ninguna regla de modalidad aplica al tipo %q", input.ContractType)
+}
+
+// normalizeType compares contract types case-insensitively
+func normalizeType(contractType string) string {
+	return strings.ToUpper(strings.TrimSpace(contractType))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
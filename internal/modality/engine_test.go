@@ -0,0 +1,154 @@
+package modality
+
+import (
+	"reflect"
+	"secop-blockchain/internal/money"
+	"testing"
+)
+
+func TestThresholds(t *testing.T) {
+	engine := Default()
+
+	tests := []struct {
+		name       string
+		budget     string
+		wantMinima string
+		wantMenor  string
+	}{
+		{"sin presupuesto", "0", "28.00", "280.00"},
+		{"presupuesto pequeño", "119999.99", "28.00", "280.00"},
+		{"límite 120.000", "120000", "45.00", "450.00"},
+		{"límite 400.000", "400000", "65.00", "650.00"},
+		{"entre 400.000 y 850.000", "849999", "65.00", "650.00"},
+		{"límite 850.000", "850000", "85.00", "850.00"},
+		{"límite 1.200.000", "1200000", "100.00", "1000.00"},
+		{"presupuesto nacional", "50000000", "100.00", "1000.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minima, menor, err := engine.Thresholds(money.MustParse(tt.budget))
+			if err != nil {
+				t.Fatalf("Thresholds(%s) error: %v", tt.budget, err)
+			}
+			if minima.String() != tt.wantMinima || menor.String() != tt.wantMenor {
+				t.Errorf("Thresholds(%s) = (%s, %s), want (%s, %s)", tt.budget, minima, menor, tt.wantMinima, tt.wantMenor)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	engine := Default()
+
+	tests := []struct {
+		name         string
+		contractType string
+		value        string
+		budget       string
+		wantModality string
+		wantWorkflow string
+		wantSteps    int
+	}{
+		{"prestación de servicios siempre directa", "PRESTACION_SERVICIOS", "5000", "1200000", "CONTRATACION_DIRECTA", "directa", 5},
+		{"tipo en minúsculas", " convenio_interadministrativo ", "10", "0", "CONTRATACION_DIRECTA", "directa", 5},
+		{"obra de mínima cuantía", "OBRA", "28", "0", "MINIMA_CUANTIA", "minima_cuantia", 4},
+		{"obra justo sobre mínima cuantía", "OBRA", "28.01", "0", "SELECCION_ABREVIADA_MENOR_CUANTIA", "standard", 6},
+		{"obra de menor cuantía", "OBRA", "280", "0", "SELECCION_ABREVIADA_MENOR_CUANTIA", "standard", 6},
+		{"obra sobre menor cuantía", "OBRA", "280.01", "0", "LICITACION_PUBLICA", "standard", 6},
+		{"menor cuantía depende del presupuesto", "OBRA", "900", "1200000", "SELECCION_ABREVIADA_MENOR_CUANTIA", "standard", 6},
+		{"licitación en entidad grande", "OBRA", "1000.01", "1200000", "LICITACION_PUBLICA", "standard", 6},
+		{"consultoría pequeña es mínima cuantía", "CONSULTORIA", "10", "0", "MINIMA_CUANTIA", "minima_cuantia", 4},
+		{"consultoría por concurso de méritos", "CONSULTORIA", "5000", "0", "CONCURSO_MERITOS", "standard", 6},
+		{"interventoría por concurso de méritos", "INTERVENTORIA", "100", "0", "CONCURSO_MERITOS", "standard", 6},
+		{"bienes uniformes por subasta", "BIENES_UNIFORMES", "100000", "0", "SELECCION_ABREVIADA_SUBASTA", "standard", 6},
+		{"tipo vacío usa reglas por cuantía", "", "5", "0", "MINIMA_CUANTIA", "minima_cuantia", 4},
+		{"tipo desconocido usa reglas por cuantía", "SUMINISTRO", "1000000", "0", "LICITACION_PUBLICA", "standard", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := engine.Evaluate(Input{
+				ContractType:      tt.contractType,
+				ValueSMMLV:        money.MustParse(tt.value),
+				EntityBudgetSMMLV: money.MustParse(tt.budget),
+			})
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if decision.Modality != tt.wantModality {
+				t.Errorf("Modality = %s, want %s", decision.Modality, tt.wantModality)
+			}
+			if decision.Workflow != tt.wantWorkflow {
+				t.Errorf("Workflow = %s, want %s", decision.Workflow, tt.wantWorkflow)
+			}
+			if len(decision.Steps) != tt.wantSteps {
+				t.Errorf("len(Steps) = %d, want %d", len(decision.Steps), tt.wantSteps)
+			}
+			if len(decision.RequiredDocuments) == 0 {
+				t.Errorf("RequiredDocuments is empty")
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr bool
+	}{
+		{"json inválido", `{`, true},
+		{"sin rangos", `{"workflows":{"standard":[]},"rules":[{"modality":"X"}]}`, true},
+		{"sin reglas", `{"menor_cuantia":[{"min_budget_smmlv":"0","max_value_smmlv":"280"}],"workflows":{"standard":[]}}`, true},
+		{"sin flujo estándar", `{"menor_cuantia":[{"min_budget_smmlv":"0","max_value_smmlv":"280"}],"workflows":{},"rules":[{"modality":"X"}]}`, true},
+		{"flujo desconocido", `{"menor_cuantia":[{"min_budget_smmlv":"0","max_value_smmlv":"280"}],"workflows":{"standard":[]},"rules":[{"modality":"X","workflow":"otro"}]}`, true},
+		{"up_to inválido", `{"menor_cuantia":[{"min_budget_smmlv":"0","max_value_smmlv":"280"}],"workflows":{"standard":[]},"rules":[{"modality":"X","up_to":"MUCHO"}]}`, true},
+		{"reglas mínimas válidas", `{"menor_cuantia":[{"min_budget_smmlv":"0","max_value_smmlv":"280"}],"workflows":{"standard":[]},"rules":[{"modality":"X","up_to":"500"}]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.rules))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateCustomRules(t *testing.T) {
+	engine, err := Parse([]byte(`{
+		"minima_cuantia_percent": "10",
+		"menor_cuantia": [{"min_budget_smmlv": "0", "max_value_smmlv": "100"}],
+		"workflows": {"standard": [{"role": "PROJECT_DEVELOPER", "name": "Creación"}]},
+		"rules": [
+			{"name": "Tope fijo", "up_to": "50", "modality": "A", "required_documents": ["X"]},
+			{"name": "Resto", "modality": "B", "required_documents": ["Y", "Z"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		value    string
+		wantRule string
+		wantDocs []string
+	}{
+		{"50", "Tope fijo", []string{"X"}},
+		{"50.01", "Resto", []string{"Y", "Z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			decision, err := engine.Evaluate(Input{ValueSMMLV: money.MustParse(tt.value)})
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if decision.Rule != tt.wantRule || !reflect.DeepEqual(decision.RequiredDocuments, tt.wantDocs) {
+				t.Errorf("Evaluate(%s) = %s %v, want %s %v", tt.value, decision.Rule, decision.RequiredDocuments, tt.wantRule, tt.wantDocs)
+			}
+		})
+	}
+}
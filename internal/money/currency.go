@@ -0,0 +1,136 @@
+package money
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+// Supported currencies
+const (
+	COP Currency = "COP" // Peso colombiano, moneda por defecto
+	USD Currency = "USD" // Dólar, usado en créditos de banca multilateral
+)
+
+// DefaultCurrency is used when a contract does not declare its currency
+const DefaultCurrency = COP
+
+// ParseCurrency normalizes a currency code, defaulting to COP when empty
+func ParseCurrency(code string) (Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	switch Currency(code) {
+	case COP, USD:
+		return Currency(code), nil
+	}
+	return "", fmt.Errorf("moneda no soportada: %s", code)
+}
+
+// defaultSMMLV holds the monthly legal minimum wage (salario mínimo mensual
+// legal vigente) decreed for each year
+var defaultSMMLV = map[int]Decimal{
+	2020: New(877803),
+	2021: New(908526),
+	2022: New(1000000),
+	2023: New(1160000),
+	2024: New(1300000),
+	2025: New(1423500),
+	2026: New(1750905),
+}
+
+// defaultUSDRate is the peso value of one dollar used when no rate is configured
+var defaultUSDRate = New(4000)
+
+// Converter converts amounts to pesos and to SMMLV units
+type Converter struct {
+	rates map[Currency]Decimal // Pesos por unidad de cada moneda
+	smmlv map[int]Decimal
+	years []int
+}
+
+// NewConverter creates a converter. Rates and SMMLV values override or
+// extend the built-in defaults; nil maps keep the defaults.
+func NewConverter(rates map[Currency]Decimal, smmlv map[int]Decimal) *Converter {
+	c := &Converter{
+		rates: map[Currency]Decimal{COP: New(1), USD: defaultUSDRate},
+		smmlv: make(map[int]Decimal),
+	}
+	for currency, rate := range rates {
+		c.rates[currency] = rate
+	}
+	for year, value := range defaultSMMLV {
+		c.smmlv[year] = value
+	}
+	for year, value := range smmlv {
+		c.smmlv[year] = value
+	}
+	for year := range c.smmlv {
+		c.years = append(c.years, year)
+	}
+	sort.Ints(c.years)
+	return c
+}
+
+// Rate returns the peso value of one unit of a currency
+func (c *Converter) Rate(currency Currency) (Decimal, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	rate, ok := c.rates[currency]
+	if !ok || !rate.IsPositive() {
+		return Zero, fmt.Errorf("sin tasa de cambio para %s", currency)
+	}
+	return rate, nil
+}
+
+// ToCOP converts an amount in any supported currency to pesos
+func (c *Converter) ToCOP(amount Decimal, currency Currency) (Decimal, error) {
+	if currency == "" || currency == COP {
+		return amount, nil
+	}
+	rate, err := c.Rate(currency)
+	if err != nil {
+		return Zero, err
+	}
+	return amount.Mul(rate)
+}
+
+// SMMLV returns the minimum wage of a year. Years after the last known
+// value use the latest one; earlier years use the oldest.
+func (c *Converter) SMMLV(year int) Decimal {
+	if value, ok := c.smmlv[year]; ok {
+		return value
+	}
+	if len(c.years) == 0 {
+		return Zero
+	}
+	if year < c.years[0] {
+		return c.smmlv[c.years[0]]
+	}
+	latest := c.years[0]
+	for _, known := range c.years {
+		if known <= year {
+			latest = known
+		}
+	}
+	return c.smmlv[latest]
+}
+
+// ToSMMLV expresses an amount as minimum-wage units of a year, with two decimals
+func (c *Converter) ToSMMLV(amount Decimal, currency Currency, year int) (Decimal, error) {
+	pesos, err := c.ToCOP(amount, currency)
+	if err != nil {
+		return Zero, err
+	}
+	return pesos.Div(c.SMMLV(year))
+}
+
+// FromSMMLV converts minimum-wage units of a year to pesos
+func (c *Converter) FromSMMLV(units Decimal, year int) (Decimal, error) {
+	return units.Mul(c.SMMLV(year))
+}
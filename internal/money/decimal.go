@@ -0,0 +1,235 @@
+// Package money provides exact decimal amounts and currency conversion for
+// contract values.
+//
+// Amounts are stored as an integer number of hundredths (centavos or cents),
+// so they add, compare and hash exactly. They are encoded in JSON as strings
+// ("1500000.50") to avoid float rounding in clients and in block hashes.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// scale is the number of hundredths in one unit
+const scale = 100
+
+// Decimal is an exact amount with two decimal places
+type Decimal struct {
+	cents int64
+}
+
+// Zero is the zero amount
+var Zero = Decimal{}
+
+// New returns an amount of whole units
+func New(units int64) Decimal {
+	return Decimal{cents: units * scale}
+}
+
+// FromCents returns an amount from hundredths of a unit
+func FromCents(cents int64) Decimal {
+	return Decimal{cents: cents}
+}
+
+// Parse reads an amount such as "1500000", "1500000.5" or "-20.75".
+// More than two decimal places are rejected rather than rounded.
+func Parse(value string) (Decimal, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return Zero, errors.New("monto vacío")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return Zero, fmt.Errorf("monto inválido: %s", value)
+	}
+	if hasPoint && fraction == "" {
+		return Zero, fmt.Errorf("monto inválido: %s", value)
+	}
+	if len(fraction) > 2 {
+		if strings.Trim(fraction[2:], "0") != "" {
+			return Zero, fmt.Errorf("monto con más de dos decimales: %s", value)
+		}
+		fraction = fraction[:2]
+	}
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return Zero, fmt.Errorf("monto inválido: %s", value)
+		}
+	}
+
+	cents, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Zero, fmt.Errorf("monto fuera de rango: %s", value)
+	}
+	if negative {
+		cents = -cents
+	}
+	return Decimal{cents: cents}, nil
+}
+
+// MustParse is like Parse but panics on error. Intended for constants.
+func MustParse(value string) Decimal {
+	d, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Cents returns the amount in hundredths
+func (d Decimal) Cents() int64 {
+	return d.cents
+}
+
+// String formats the amount with exactly two decimals
+func (d Decimal) String() string {
+	cents := d.cents
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(cents)).String()
+	for len(abs) < 3 {
+		abs = "0" + abs
+	}
+	return sign + abs[:len(abs)-2] + "." + abs[len(abs)-2:]
+}
+
+// Float64 returns an approximate value for statistics. Never use it for money arithmetic.
+func (d Decimal) Float64() float64 {
+	return float64(d.cents) / scale
+}
+
+// Add returns d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{cents: d.cents + other.cents}
+}
+
+// Sub returns d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	return Decimal{cents: d.cents - other.cents}
+}
+
+// Cmp compares two amounts returning -1, 0 or 1
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.cents < other.cents:
+		return -1
+	case d.cents > other.cents:
+		return 1
+	}
+	return 0
+}
+
+// LessThan reports whether d < other
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.cents < other.cents
+}
+
+// GreaterThan reports whether d > other
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.cents > other.cents
+}
+
+// IsZero reports whether the amount is zero
+func (d Decimal) IsZero() bool {
+	return d.cents == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (d Decimal) IsPositive() bool {
+	return d.cents > 0
+}
+
+// Mul returns d * factor rounded half away from zero to two decimals
+func (d Decimal) Mul(factor Decimal) (Decimal, error) {
+	product := new(big.Int).Mul(big.NewInt(d.cents), big.NewInt(factor.cents))
+	cents, err := roundDiv(product, big.NewInt(scale))
+	if err != nil {
+		return Zero, err
+	}
+	return Decimal{cents: cents}, nil
+}
+
+// Div returns d / divisor rounded half away from zero to two decimals
+func (d Decimal) Div(divisor Decimal) (Decimal, error) {
+	if divisor.cents == 0 {
+		return Zero, errors.New("división por cero")
+	}
+	numerator := new(big.Int).Mul(big.NewInt(d.cents), big.NewInt(scale))
+	cents, err := roundDiv(numerator, big.NewInt(divisor.cents))
+	if err != nil {
+		return Zero, err
+	}
+	return Decimal{cents: cents}, nil
+}
+
+// ErrOverflow is returned when a result does not fit in the amount range
+var ErrOverflow = errors.New("monto fuera de rango")
+
+// roundDiv divides rounding half away from zero. Results outside the int64
+// range of cents return ErrOverflow instead of wrapping.
+func roundDiv(numerator, denominator *big.Int) (int64, error) {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	doubled := new(big.Int).Abs(remainder)
+	doubled.Mul(doubled, big.NewInt(2))
+	if doubled.Cmp(new(big.Int).Abs(denominator)) >= 0 {
+		if (numerator.Sign() < 0) != (denominator.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	if !quotient.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quotient.Int64(), nil
+}
+
+// MarshalJSON encodes the amount as a JSON string
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a JSON string or number. Numbers are parsed from
+// their literal text, never through float64.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "null" {
+		*d = Zero
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,31 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMul(t *testing.T) {
+	got, err := MustParse("1500.25").Mul(MustParse("4000.50"))
+	if err != nil {
+		t.Fatalf("Mul error: %v", err)
+	}
+	if want := "6001750.13"; got.String() != want {
+		t.Errorf("Mul = %s, want %s", got, want)
+	}
+}
+
+func TestMulOverflow(t *testing.T) {
+	// 50 billion dollars at 4,000 pesos exceed the int64 range of cents
+	amount := MustParse("50000000000000")
+	if _, err := amount.Mul(MustParse("4000")); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Mul error = %v, want ErrOverflow", err)
+	}
+}
+
+func TestDivOverflow(t *testing.T) {
+	amount := MustParse("90000000000000000")
+	if _, err := amount.Div(MustParse("0.01")); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Div error = %v, want ErrOverflow", err)
+	}
+}
@@ -0,0 +1,138 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Channel names
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// Message is a notification addressed to one recipient
+type Message struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Kind    string `json:"kind"`
+	// Data carries structured details for machine-readable channels
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Channel delivers messages through a transport
+type Channel interface {
+	Name() string
+	Send(recipient Recipient, msg Message) error
+}
+
+// SMTPChannel sends messages by email
+type SMTPChannel struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPChannel creates an email channel. Authentication is only used when a username is set.
+func NewSMTPChannel(host, port, username, password, from string) *SMTPChannel {
+	return &SMTPChannel{
+		addr:     host + ":" + port,
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Name returns the channel name
+func (ch *SMTPChannel) Name() string {
+	return ChannelEmail
+}
+
+// Send emails the message to the recipient
+func (ch *SMTPChannel) Send(recipient Recipient, msg Message) error {
+	if recipient.Email == "" {
+		return errors.New("destinatario sin email")
+	}
+	if ch.from == "" {
+		return errors.New("remitente no configurado (ENTITY_CONTACT_EMAIL)")
+	}
+
+	var auth smtp.Auth
+	if ch.username != "" {
+		auth = smtp.PlainAuth("", ch.username, ch.password, ch.host)
+	}
+
+	from, err := mail.ParseAddress(ch.from)
+	if err != nil {
+		return fmt.Errorf("remitente inválido: %v", err)
+	}
+	to, err := mail.ParseAddress(recipient.Email)
+	if err != nil {
+		return fmt.Errorf("email inválido: %v", err)
+	}
+	to.Name = recipient.Name
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", from.String())
+	fmt.Fprintf(&body, "To: %s\r\n", to.String())
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(ch.addr, auth, from.Address, []string{to.Address}, []byte(body.String()))
+}
+
+// WebhookChannel posts messages as JSON to the recipient's URL
+type WebhookChannel struct {
+	client *http.Client
+}
+
+// NewWebhookChannel creates a webhook channel
+func NewWebhookChannel(timeout time.Duration) *WebhookChannel {
+	return &WebhookChannel{client: &http.Client{Timeout: timeout}}
+}
+
+// Name returns the channel name
+func (ch *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Send posts the message to the recipient's webhook URL
+func (ch *WebhookChannel) Send(recipient Recipient, msg Message) error {
+	if recipient.WebhookURL == "" {
+		return errors.New("destinatario sin webhook_url")
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"recipient_id": recipient.ID,
+		"role":         recipient.Role,
+		"message":      msg,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := ch.client.Post(recipient.WebhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook respondió con status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/config"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxRecords bounds the notification log kept in memory
+const maxRecords = 500
+
+// Notification kinds
+const (
+	KindPendingValidation = "PENDING_VALIDATION"
+	KindDailyDigest       = "DAILY_DIGEST"
+)
+
+// Recipient is a user holding a workflow role who wants to be notified
+type Recipient struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email,omitempty"`
+	WebhookURL string    `json:"webhook_url,omitempty"`
+	Role       string    `json:"role"`
+	EntityCode string    `json:"entity_code,omitempty"`
+	Channels   []string  `json:"channels"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Record is an entry of the notification log
+type Record struct {
+	ID          string    `json:"id"`
+	RecipientID string    `json:"recipient_id"`
+	Channel     string    `json:"channel"`
+	Kind        string    `json:"kind"`
+	Subject     string    `json:"subject"`
+	ContractID  string    `json:"contract_id,omitempty"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	SentAt      time.Time `json:"sent_at"`
+}
+
+// Service notifies role holders about contracts waiting for them
+type Service struct {
+	cfg        config.NotificationConfig
+	blockchain *blockchain.Blockchain
+	channels   map[string]Channel
+	recipients map[string]*Recipient
+	records    []Record
+	testServer *TestSMTPServer
+	stop       chan struct{}
+	stopOnce   sync.Once
+	mutex      sync.RWMutex
+}
+
+// NewService creates the notification service with the email and webhook
+// channels and subscribes it to workflow events
+func NewService(cfg config.NotificationConfig, bc *blockchain.Blockchain, workflow *blockchain.WorkflowManager) (*Service, error) {
+	s := &Service{
+		cfg:        cfg,
+		blockchain: bc,
+		channels:   make(map[string]Channel),
+		recipients: make(map[string]*Recipient),
+		stop:       make(chan struct{}),
+	}
+
+	if cfg.TestSMTPServer {
+		server, err := StartTestSMTPServer(cfg.SMTPHost + ":" + cfg.SMTPPort)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo iniciar el servidor SMTP de prueba: %v", err)
+		}
+		s.testServer = server
+	}
+
+	s.RegisterChannel(NewSMTPChannel(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From))
+	s.RegisterChannel(NewWebhookChannel(10 * time.Second))
+
+	workflow.AddListener(s.onWorkflowEvent)
+	return s, nil
+}
+
+// RegisterChannel adds or replaces a delivery channel
+func (s *Service) RegisterChannel(channel Channel) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.channels[channel.Name()] = channel
+}
+
+// AddRecipient registers a role holder
+func (s *Service) AddRecipient(recipient Recipient) (*Recipient, error) {
+	if recipient.Role == "" {
+		return nil, errors.New("rol requerido")
+	}
+	if len(recipient.Channels) == 0 {
+		recipient.Channels = []string{ChannelEmail}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, name := range recipient.Channels {
+		if _, exists := s.channels[name]; !exists {
+			return nil, fmt.Errorf("canal desconocido: %s", name)
+		}
+		if name == ChannelEmail {
+			// Keep only the bare address; a display name goes to Name
+			addr, err := mail.ParseAddress(recipient.Email)
+			if err != nil {
+				return nil, errors.New("email inválido")
+			}
+			recipient.Email = addr.Address
+			if recipient.Name == "" {
+				recipient.Name = addr.Name
+			}
+		}
+		if name == ChannelWebhook && recipient.WebhookURL == "" {
+			return nil, errors.New("webhook_url requerido para el canal webhook")
+		}
+	}
+
+	recipient.ID = uuid.New().String()
+	recipient.CreatedAt = config.GetColombianTime()
+	s.recipients[recipient.ID] = &recipient
+	return &recipient, nil
+}
+
+// RemoveRecipient deletes a recipient
+func (s *Service) RemoveRecipient(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.recipients[id]; !exists {
+		return errors.New("destinatario no encontrado")
+	}
+	delete(s.recipients, id)
+	return nil
+}
+
+// Recipients returns all recipients ordered by creation
+func (s *Service) Recipients() []*Recipient {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	recipients := make([]*Recipient, 0, len(s.recipients))
+	for _, recipient := range s.recipients {
+		recipients = append(recipients, recipient)
+	}
+	sort.Slice(recipients, func(i, j int) bool { return recipients[i].CreatedAt.Before(recipients[j].CreatedAt) })
+	return recipients
+}
+
+// Records returns the notification log, newest first
+func (s *Service) Records() []Record {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]Record, len(s.records))
+	for i, record := range s.records {
+		records[len(s.records)-1-i] = record
+	}
+	return records
+}
+
+// TestOutbox returns the emails captured by the test SMTP server, if enabled
+func (s *Service) TestOutbox() ([]CapturedEmail, error) {
+	if s.testServer == nil {
+		return nil, errors.New("servidor SMTP de prueba no habilitado")
+	}
+	return s.testServer.Messages(), nil
+}
+
+// Start runs the daily digest in the background
+func (s *Service) Start() {
+	go s.digestLoop()
+}
+
+// Stop stops the digest loop and the test SMTP server
+func (s *Service) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		if s.testServer != nil {
+			s.testServer.Close()
+		}
+	})
+}
+
+// digestLoop sends the digest every day at the configured hour
+func (s *Service) digestLoop() {
+	for {
+		timer := time.NewTimer(time.Until(s.nextDigestTime(config.GetColombianTime())))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			sent := s.SendDigests()
+			log.Printf("Resumen diario de pendientes enviado a %d destinatarios", sent)
+		}
+	}
+}
+
+// nextDigestTime returns the next occurrence of the digest hour after now
+func (s *Service) nextDigestTime(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), s.cfg.DigestHour, 0, 0, 0, config.ColombianTimezone)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// SendDigests sends each recipient the contracts pending for their role.
+// Returns the number of recipients with pending work.
+func (s *Service) SendDigests() int {
+	sent := 0
+	for _, recipient := range s.Recipients() {
+		pending := s.pendingFor(recipient)
+		if len(pending) == 0 {
+			continue
+		}
+
+		var body strings.Builder
+		fmt.Fprintf(&body, "Hola %s,\n\nTiene %d contrato(s) pendientes de validación como %s:\n\n", recipient.Name, len(pending), recipient.Role)
+		ids := make([]string, 0, len(pending))
+		for _, contract := range pending {
+			fmt.Fprintf(&body, "- %s | %s | %s | paso %d | desde %s\n",
+				contract.ID, contract.EntityName, contract.Description, contract.CurrentStep,
+				contract.UpdatedAt.Format("2006-01-02"))
+			ids = append(ids, contract.ID)
+		}
+		body.WriteString("\nSECOP Blockchain")
+
+		s.deliver(*recipient, Message{
+			Subject: fmt.Sprintf("[SECOP] Resumen diario: %d contrato(s) pendientes", len(pending)),
+			Body:    body.String(),
+			Kind:    KindDailyDigest,
+			Data:    map[string]interface{}{"contract_ids": ids, "role": recipient.Role},
+		}, "")
+		sent++
+	}
+	return sent
+}
+
+// pendingFor returns the contracts waiting for the recipient's role
+func (s *Service) pendingFor(recipient *Recipient) []*blockchain.Contract {
+	var pending []*blockchain.Contract
+	for _, contract := range s.blockchain.GetContractsByRole(blockchain.AdminRole(recipient.Role)) {
+		if recipient.EntityCode == "" || recipient.EntityCode == contract.EntityCode {
+			pending = append(pending, contract)
+		}
+	}
+	return pending
+}
+
+// onWorkflowEvent notifies the holders of the role a contract just advanced to
+func (s *Service) onWorkflowEvent(event blockchain.WorkflowEvent) {
+	if event.NextRole == "" || event.Action == "STEP_REJECTED" {
+		return
+	}
+	if event.Action != "WORKFLOW_INITIALIZED" && event.Action != "STEP_APPROVED" {
+		return
+	}
+
+	contract := event.Contract
+	msg := Message{
+		Subject: fmt.Sprintf("[SECOP] Contrato %s pendiente de su validación", contract.ID),
+		Body: fmt.Sprintf("El contrato %s de %s llegó al paso %d y requiere validación del rol %s.\n\nDescripción: %s\nEstado: %s\n\nSECOP Blockchain",
+			contract.ID, contract.EntityName, contract.CurrentStep, event.NextRole, contract.Description, contract.Status),
+		Kind: KindPendingValidation,
+		Data: map[string]interface{}{
+			"contract_id": contract.ID,
+			"entity_code": contract.EntityCode,
+			"step":        contract.CurrentStep,
+			"role":        string(event.NextRole),
+			"status":      string(contract.Status),
+		},
+	}
+
+	for _, recipient := range s.Recipients() {
+		if recipient.Role != string(event.NextRole) {
+			continue
+		}
+		if recipient.EntityCode != "" && recipient.EntityCode != contract.EntityCode {
+			continue
+		}
+		// Deliver asynchronously so slow channels never block the workflow
+		go s.deliver(*recipient, msg, contract.ID)
+	}
+}
+
+// deliver sends a message through every channel of the recipient and logs the result
+func (s *Service) deliver(recipient Recipient, msg Message, contractID string) {
+	for _, name := range recipient.Channels {
+		s.mutex.RLock()
+		channel, exists := s.channels[name]
+		s.mutex.RUnlock()
+
+		record := Record{
+			ID:          uuid.New().String(),
+			RecipientID: recipient.ID,
+			Channel:     name,
+			Kind:        msg.Kind,
+			Subject:     msg.Subject,
+			ContractID:  contractID,
+			Status:      "SENT",
+			SentAt:      config.GetColombianTime(),
+		}
+
+		var err error
+		if !exists {
+			err = fmt.Errorf("canal desconocido: %s", name)
+		} else {
+			err = channel.Send(recipient, msg)
+		}
+		if err != nil {
+			record.Status = "FAILED"
+			record.Error = err.Error()
+			log.Printf("Error enviando notificación a %s por %s: %v", recipient.ID, name, err)
+		}
+
+		s.mutex.Lock()
+		s.records = append(s.records, record)
+		if len(s.records) > maxRecords {
+			s.records = s.records[len(s.records)-maxRecords:]
+		}
+		s.mutex.Unlock()
+	}
+}
@@ -0,0 +1,154 @@
+package notification
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTestMessages bounds the messages kept by the test SMTP server
+const maxTestMessages = 200
+
+// CapturedEmail is a message received by the test SMTP server
+type CapturedEmail struct {
+	From       string    `json:"from"`
+	To         []string  `json:"to"`
+	Data       string    `json:"data"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// TestSMTPServer is a minimal local SMTP server that stores messages in memory.
+// It lets development and test environments exercise the email channel
+// without a real mail server.
+type TestSMTPServer struct {
+	listener net.Listener
+	messages []CapturedEmail
+	mutex    sync.RWMutex
+}
+
+// StartTestSMTPServer listens on addr and accepts messages in the background
+func StartTestSMTPServer(addr string) (*TestSMTPServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &TestSMTPServer{listener: listener}
+	go server.acceptLoop()
+
+	log.Printf("Servidor SMTP de prueba escuchando en %s", listener.Addr())
+	return server, nil
+}
+
+// Addr returns the address the server listens on
+func (s *TestSMTPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Messages returns the captured messages, newest last
+func (s *TestSMTPServer) Messages() []CapturedEmail {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	messages := make([]CapturedEmail, len(s.messages))
+	copy(messages, s.messages)
+	return messages
+}
+
+// Close stops the server
+func (s *TestSMTPServer) Close() error {
+	return s.listener.Close()
+}
+
+func (s *TestSMTPServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle speaks just enough SMTP for net/smtp clients
+func (s *TestSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Minute))
+
+	reader := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	var current CapturedEmail
+	reply("220 secop-test-smtp ESMTP")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 secop-test-smtp")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current = CapturedEmail{From: extractAddress(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			current.To = append(current.To, extractAddress(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if strings.TrimRight(dataLine, "\r\n") == "." {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			current.Data = data.String()
+			current.ReceivedAt = time.Now()
+			s.store(current)
+			reply("250 OK: queued")
+		case command == "RSET":
+			current = CapturedEmail{}
+			reply("250 OK")
+		case command == "NOOP":
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *TestSMTPServer) store(msg CapturedEmail) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = append(s.messages, msg)
+	if len(s.messages) > maxTestMessages {
+		s.messages = s.messages[len(s.messages)-maxTestMessages:]
+	}
+}
+
+func extractAddress(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, " "); i >= 0 {
+		value = value[:i]
+	}
+	return strings.Trim(value, "<>")
+}
@@ -0,0 +1,324 @@
+// Package registry implements the peer registry that nodes use for discovery
+// when the server runs in registry mode.
+//
+// Nodes register with their address and entity type and keep their entry
+// alive with heartbeats. A node that registers with a public key signs the
+// registration, and from then on only signatures with that key can change or
+// remove its entry. Entries that miss heartbeats for longer than the TTL
+// are no longer listed and are eventually pruned. The registry is persisted to
+// a JSON file so a restart does not lose the network's membership.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/identity"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownPeer is returned for heartbeats of nodes that are not registered,
+// so they know to register again
+var ErrUnknownPeer = errors.New("nodo no registrado")
+
+// ErrNotOwner is returned when a request to change or remove an entry does not
+// come from the node that registered it
+var ErrNotOwner = errors.New("la solicitud no proviene del nodo registrado")
+
+// signatureMaxAge bounds how old a signed unregister request may be
+const signatureMaxAge = 5 * time.Minute
+
+// Registration is a node's request to register. Nodes with an identity key
+// sign it the same way as their peer exchange record.
+type Registration struct {
+	blockchain.PeerInfo
+	Timestamp int64  `json:"timestamp,omitempty"` // Unix seconds when the node signed it
+	Signature string `json:"signature,omitempty"`
+}
+
+// Entry is a registered node
+type Entry struct {
+	blockchain.PeerInfo
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// Registry keeps the registered nodes of the network
+type Registry struct {
+	path    string
+	ttl     time.Duration
+	entries map[string]*Entry
+	mutex   sync.RWMutex
+	saving  sync.Mutex // Serializa escrituras del archivo
+}
+
+// New creates a registry persisted at path, loading any previous state
+func New(path string, ttl time.Duration) (*Registry, error) {
+	if ttl <= 0 {
+		ttl = 90 * time.Second
+	}
+	r := &Registry{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]*Entry),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TTL returns how long an entry stays active without a heartbeat
+func (r *Registry) TTL() time.Duration {
+	return r.ttl
+}
+
+// Register adds or refreshes a node. The address defaults to the caller's.
+func (r *Registry) Register(registration Registration, remoteAddress string) (*Entry, error) {
+	info := registration.PeerInfo
+	info.ID = strings.TrimSpace(info.ID)
+	if info.ID == "" {
+		return nil, errors.New("id del nodo requerido")
+	}
+	if info.PublicKey != "" {
+		// The signature covers the fields as the node sent them
+		record := blockchain.PeerRecord{
+			ID:         info.ID,
+			Address:    info.Address,
+			Port:       info.Port,
+			EntityType: info.EntityType,
+			PublicKey:  info.PublicKey,
+			Timestamp:  registration.Timestamp,
+			Signature:  registration.Signature,
+		}
+		if err := record.Verify(time.Now()); err != nil {
+			return nil, fmt.Errorf("registro con clave pública sin firma válida: %v", err)
+		}
+	}
+	if info.Address == "" || info.Address == "localhost" {
+		if remoteAddress != "" {
+			info.Address = remoteAddress
+		}
+	}
+	if info.Address == "" {
+		return nil, errors.New("dirección del nodo requerida")
+	}
+	if info.Port == "" {
+		return nil, errors.New("puerto del nodo requerido")
+	}
+	info.EntityType = strings.ToUpper(strings.TrimSpace(info.EntityType))
+	if info.EntityType == "" {
+		return nil, errors.New("tipo de entidad requerido")
+	}
+
+	now := config.GetColombianTime()
+	info.LastSeen = now
+	info.IsActive = true
+	info.Verified = false // Verification is each node's own judgment
+	info.Source = ""
+
+	r.mutex.Lock()
+	entry, exists := r.entries[info.ID]
+	if exists {
+		if err := r.checkUpdate(entry, info, now); err != nil {
+			r.mutex.Unlock()
+			return nil, err
+		}
+		entry.PeerInfo = info
+	} else {
+		entry = &Entry{PeerInfo: info, RegisteredAt: now}
+		r.entries[info.ID] = entry
+	}
+	view := *entry
+	r.mutex.Unlock()
+
+	if !exists {
+		log.Printf("Registro: nodo %s (%s) registrado en %s:%s", info.ID, info.EntityType, info.Address, info.Port)
+	}
+	return &view, r.save()
+}
+
+// checkUpdate verifies that a registration may replace an existing entry. An
+// entry with a key only accepts registrations signed with that key; one
+// without a key cannot be moved to another address while it is alive.
+func (r *Registry) checkUpdate(entry *Entry, info blockchain.PeerInfo, now time.Time) error {
+	if entry.PublicKey != "" {
+		if info.PublicKey != entry.PublicKey {
+			return fmt.Errorf("%w: %s está registrado con otra clave", ErrNotOwner, entry.ID)
+		}
+		return nil
+	}
+	if r.alive(entry, now) && (info.Address != entry.Address || info.Port != entry.Port) {
+		return fmt.Errorf("%w: %s está activo en %s:%s", ErrNotOwner, entry.ID, entry.Address, entry.Port)
+	}
+	return nil
+}
+
+// Heartbeat refreshes a node's entry. Heartbeats are not persisted; after a
+// restart entries stay inactive until their node's next heartbeat.
+func (r *Registry) Heartbeat(id string) (*Entry, error) {
+	r.mutex.Lock()
+	entry, exists := r.entries[id]
+	if !exists {
+		r.mutex.Unlock()
+		return nil, ErrUnknownPeer
+	}
+	entry.LastSeen = config.GetColombianTime()
+	entry.IsActive = true
+	view := *entry
+	r.mutex.Unlock()
+
+	return &view, nil
+}
+
+// Unregister removes a node. An entry with a key requires the node's signature
+// of blockchain.UnregisterPayload; one without a key, a request from its address.
+func (r *Registry) Unregister(id string, timestamp int64, signature string, remoteAddress string) error {
+	r.mutex.Lock()
+	entry, exists := r.entries[id]
+	if !exists {
+		r.mutex.Unlock()
+		return ErrUnknownPeer
+	}
+	if entry.PublicKey != "" {
+		signedAt := time.Unix(timestamp, 0)
+		if time.Since(signedAt).Abs() > signatureMaxAge || !identity.Verify(entry.PublicKey, blockchain.UnregisterPayload(id, timestamp), signature) {
+			r.mutex.Unlock()
+			return fmt.Errorf("%w: firma inválida o vencida", ErrNotOwner)
+		}
+	} else if remoteAddress != entry.Address {
+		r.mutex.Unlock()
+		return fmt.Errorf("%w: %s solo puede darse de baja desde %s", ErrNotOwner, id, entry.Address)
+	}
+	delete(r.entries, id)
+	r.mutex.Unlock()
+
+	log.Printf("Registro: nodo %s dado de baja", id)
+	return r.save()
+}
+
+// Peers lists the active nodes, optionally of one entity type
+func (r *Registry) Peers(entityType string) []blockchain.PeerInfo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entityType = strings.ToUpper(entityType)
+	now := config.GetColombianTime()
+	peers := make([]blockchain.PeerInfo, 0, len(r.entries))
+	for _, entry := range r.entries {
+		if !r.alive(entry, now) {
+			continue
+		}
+		if entityType != "" && entry.EntityType != entityType {
+			continue
+		}
+		peers = append(peers, entry.PeerInfo)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	return peers
+}
+
+// Entries lists every entry, including expired ones, with its current state
+func (r *Registry) Entries() []Entry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	now := config.GetColombianTime()
+	entries := make([]Entry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		view := *entry
+		view.IsActive = r.alive(entry, now)
+		entries = append(entries, view)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// Prune removes entries that have missed heartbeats for several TTLs. The
+// scheduler runs it every TTL.
+func (r *Registry) Prune() int {
+	r.mutex.Lock()
+	now := config.GetColombianTime()
+	removed := 0
+	for id, entry := range r.entries {
+		if now.Sub(entry.LastSeen) > 10*r.ttl {
+			delete(r.entries, id)
+			removed++
+		}
+	}
+	r.mutex.Unlock()
+
+	if removed > 0 {
+		log.Printf("Registro: %d nodos expirados eliminados", removed)
+		if err := r.save(); err != nil {
+			log.Printf("Registro: error guardando %s: %v", r.path, err)
+		}
+	}
+	return removed
+}
+
+func (r *Registry) alive(entry *Entry, now time.Time) bool {
+	return entry.IsActive && now.Sub(entry.LastSeen) <= r.ttl
+}
+
+// load reads the persisted entries, if any
+func (r *Registry) load() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("registro de peers inválido en %s: %v", r.path, err)
+	}
+	for _, entry := range entries {
+		if entry.ID != "" {
+			r.entries[entry.ID] = entry
+		}
+	}
+	log.Printf("Registro: %d nodos cargados desde %s", len(r.entries), r.path)
+	return nil
+}
+
+// save writes the entries atomically
+func (r *Registry) save() error {
+	if r.path == "" {
+		return nil
+	}
+	r.saving.Lock()
+	defer r.saving.Unlock()
+
+	r.mutex.RLock()
+	entries := make([]*Entry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	data, err := json.MarshalIndent(entries, "", "  ")
+	r.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o750); err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
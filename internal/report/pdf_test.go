@@ -0,0 +1,47 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+	}{
+		{"short", "hola mundo", 20},
+		{"words", strings.Repeat("palabra ", 30), 20},
+		{"no spaces", strings.Repeat("x", 95), 20},
+		{"indented", "    " + strings.Repeat("abc ", 40), 20},
+		{"indent past width", strings.Repeat(" ", 30) + strings.Repeat("y", 60), 20},
+		{"indent equal to width", strings.Repeat(" ", 18) + strings.Repeat("z ", 40), 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan []string, 1)
+			go func() { done <- wrapLine(tt.line, tt.width) }()
+
+			var lines []string
+			select {
+			case lines = <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("wrapLine(%q, %d) did not return", tt.line, tt.width)
+			}
+
+			content := 0
+			for _, line := range lines {
+				if n := len([]rune(line)); n > tt.width {
+					t.Errorf("line %q has %d runes, want at most %d", line, n, tt.width)
+				}
+				content += len(strings.ReplaceAll(line, " ", ""))
+			}
+			if want := len(strings.ReplaceAll(tt.line, " ", "")); content != want {
+				t.Errorf("wrapped lines keep %d non-space runes, want %d", content, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/identity"
+	"secop-blockchain/internal/money"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatPDF  = "pdf"
+)
+
+// ContractReport is the full history of a contract as seen by this node
+type ContractReport struct {
+	NodeID        string                    `json:"node_id"`
+	GeneratedAt   time.Time                 `json:"generated_at"`
+	ChainHeight   int                       `json:"chain_height"`
+	LastBlockHash string                    `json:"last_block_hash"`
+	Contract      *blockchain.Contract      `json:"contract"`
+	ValueCOP      money.Decimal             `json:"value_cop"`
+	ValueSMMLV    money.Decimal             `json:"value_smmlv"`
+	Events        []blockchain.HistoryEvent `json:"events"`
+}
+
+// Signature proves which node generated a report and that it was not altered
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	NodeID    string `json:"node_id"`
+	PublicKey string `json:"public_key"`
+	Digest    string `json:"digest"`
+	Value     string `json:"signature"`
+}
+
+// SignedReport wraps a report with its signature
+type SignedReport struct {
+	Report    *ContractReport `json:"report"`
+	Signature Signature       `json:"signature"`
+}
+
+// NewContractReport builds the report of a contract from the blockchain
+func NewContractReport(bc *blockchain.Blockchain, nodeID, contractID string) (*ContractReport, error) {
+	contract, err := bc.GetContract(contractID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := bc.GetContractHistory(contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	valueCOP, err := bc.ValueInCOP(contract.Amount, contract.Currency)
+	if err != nil {
+		return nil, err
+	}
+	valueSMMLV, err := bc.GetConverter().ToSMMLV(contract.Amount, contract.Currency, contract.CreatedAt.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContractReport{
+		NodeID:        nodeID,
+		GeneratedAt:   config.GetColombianTime(),
+		ChainHeight:   bc.GetBlockchainHeight(),
+		LastBlockHash: bc.GetLastBlockHash(),
+		Contract:      contract,
+		ValueCOP:      valueCOP,
+		ValueSMMLV:    valueSMMLV,
+		Events:        events,
+	}, nil
+}
+
+// WriteCSV writes one row per history event
+func (r *ContractReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"contract_id", "event_id", "timestamp", "action", "user_id", "user_role", "description", "block_index", "block_hash"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, event := range r.Events {
+		row := []string{
+			r.Contract.ID,
+			event.ID,
+			event.Timestamp.Format(time.RFC3339),
+			event.Action,
+			event.UserID,
+			string(event.UserRole),
+			event.Description,
+			strconv.Itoa(event.BlockIndex),
+			event.BlockHash,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// SignedJSON returns the report and its signature as JSON
+func (r *ContractReport) SignedJSON(signer *identity.Identity) ([]byte, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(SignedReport{Report: r, Signature: sign(signer, body)}, "", "  ")
+}
+
+// SignedPDF renders the report as a PDF document. The signature covers the
+// report text and is printed at the end of the document.
+func (r *ContractReport) SignedPDF(signer *identity.Identity) ([]byte, Signature) {
+	lines := r.textLines()
+	signature := sign(signer, []byte(strings.Join(lines, "\n")))
+
+	lines = append(lines,
+		"",
+		strings.Repeat("=", maxLineWidth),
+		"FIRMA DIGITAL DEL NODO",
+		fmt.Sprintf("Algoritmo:    %s", signature.Algorithm),
+		fmt.Sprintf("Nodo:         %s", signature.NodeID),
+		fmt.Sprintf("Llave pública: %s", signature.PublicKey),
+		fmt.Sprintf("Digest SHA-256 del contenido: %s", signature.Digest),
+		fmt.Sprintf("Firma: %s", signature.Value),
+	)
+
+	return renderPDF("Historial de contrato "+r.Contract.ID, lines), signature
+}
+
+// textLines builds the human-readable body of the report
+func (r *ContractReport) textLines() []string {
+	c := r.Contract
+	lines := []string{
+		"SECOP BLOCKCHAIN - REPORTE DE HISTORIAL DE CONTRATO",
+		strings.Repeat("=", maxLineWidth),
+		fmt.Sprintf("Generado:           %s", r.GeneratedAt.Format("2006-01-02 15:04:05 MST")),
+		fmt.Sprintf("Nodo:               %s", r.NodeID),
+		fmt.Sprintf("Altura de cadena:   %d", r.ChainHeight),
+		fmt.Sprintf("Último bloque:      %s", r.LastBlockHash),
+		"",
+		"CONTRATO",
+		strings.Repeat("-", maxLineWidth),
+		fmt.Sprintf("ID:                 %s", c.ID),
+		fmt.Sprintf("Entidad:            %s (%s)", c.EntityName, c.EntityCode),
+		fmt.Sprintf("Tipo:               %s", c.ContractType),
+		fmt.Sprintf("Descripción:        %s", c.Description),
+		fmt.Sprintf("Monto:              %s %s", c.Amount, c.Currency),
+		fmt.Sprintf("Valor en pesos:     %s COP (%s SMMLV %d)", r.ValueCOP, r.ValueSMMLV, c.CreatedAt.Year()),
+		fmt.Sprintf("Estado:             %s", c.Status),
+		fmt.Sprintf("Creado por:         %s", c.CreatedBy),
+		fmt.Sprintf("Creado:             %s", c.CreatedAt.Format("2006-01-02 15:04:05 MST")),
+		fmt.Sprintf("Actualizado:        %s", c.UpdatedAt.Format("2006-01-02 15:04:05 MST")),
+		"",
+		fmt.Sprintf("HISTORIAL (%d eventos)", len(r.Events)),
+		strings.Repeat("-", maxLineWidth),
+	}
+
+	for i, event := range r.Events {
+		lines = append(lines,
+			fmt.Sprintf("%3d. %s  %s  %s (%s)", i+1, event.Timestamp.Format("2006-01-02 15:04:05"), event.Action, event.UserID, event.UserRole),
+			fmt.Sprintf("     %s", event.Description),
+			fmt.Sprintf("     Bloque #%d  %s", event.BlockIndex, event.BlockHash),
+		)
+	}
+
+	return lines
+}
+
+// sign computes the digest of data and signs it with the node identity
+func sign(signer *identity.Identity, data []byte) Signature {
+	digest := sha256.Sum256(data)
+	return Signature{
+		Algorithm: "ed25519",
+		NodeID:    signer.NodeID,
+		PublicKey: signer.PublicKey(),
+		Digest:    hex.EncodeToString(digest[:]),
+		Value:     signer.Sign(digest[:]),
+	}
+}
@@ -0,0 +1,193 @@
+// Package sanctions checks suppliers against sanction and disqualification
+// registries (inhabilidades).
+//
+// The official sources are the Procuraduría's SIRI, the Contraloría's
+// boletín de responsables fiscales and the sanctions recorded in the RUP.
+// Until those integrations exist, FileList reads a local JSON file with the
+// same information so awards can be blocked consistently.
+package sanctions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"secop-blockchain/internal/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a sanction or disqualification registered against a person or company
+type Record struct {
+	Document  string    `json:"document"` // NIT sin dígito de verificación o número de cédula
+	Name      string    `json:"name"`
+	Source    string    `json:"source"` // PROCURADURIA_SIRI, CONTRALORIA_BOLETIN, RUP, ...
+	Type      string    `json:"type"`   // INHABILIDAD, INCOMPATIBILIDAD, MULTA, CADUCIDAD, RESPONSABILIDAD_FISCAL
+	Reason    string    `json:"reason"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date,omitempty"` // Vacío para sanciones sin término
+}
+
+// Active reports whether the sanction is in force at a given time
+func (r Record) Active(at time.Time) bool {
+	if !r.StartDate.IsZero() && at.Before(r.StartDate) {
+		return false
+	}
+	return r.EndDate.IsZero() || !at.After(r.EndDate)
+}
+
+// Checker returns the sanctions in force for a document number
+type Checker interface {
+	Check(document string) ([]Record, error)
+}
+
+// None is a checker that never finds sanctions
+type None struct{}
+
+// Check implements Checker
+func (None) Check(document string) ([]Record, error) {
+	return nil, nil
+}
+
+// FileList reads sanctions from a local JSON file. The file is reloaded
+// whenever its modification time changes.
+type FileList struct {
+	path    string
+	records map[string][]Record
+	modTime time.Time
+	mutex   sync.Mutex
+}
+
+// NewFileList loads a sanctions file
+func NewFileList(path string) (*FileList, error) {
+	list := &FileList{path: path}
+	if err := list.reload(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Check implements Checker
+func (l *FileList) Check(document string) ([]Record, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	now := config.GetColombianTime()
+	var active []Record
+	for _, record := range l.records[NormalizeDocument(document)] {
+		if record.Active(now) {
+			active = append(active, record)
+		}
+	}
+	return active, nil
+}
+
+// Count returns the number of records loaded
+func (l *FileList) Count() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	count := 0
+	for _, records := range l.records {
+		count += len(records)
+	}
+	return count
+}
+
+func (l *FileList) reloadIfChanged() error {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return fmt.Errorf("error leyendo lista de sanciones: %v", err)
+	}
+	if info.ModTime().Equal(l.modTime) {
+		return nil
+	}
+	return l.reload()
+}
+
+func (l *FileList) reload() error {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return fmt.Errorf("error leyendo lista de sanciones: %v", err)
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return fmt.Errorf("error leyendo lista de sanciones: %v", err)
+	}
+
+	var entries []fileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("lista de sanciones inválida: %v", err)
+	}
+
+	byDocument := make(map[string][]Record)
+	for _, entry := range entries {
+		document := NormalizeDocument(entry.Document)
+		if document == "" {
+			return fmt.Errorf("lista de sanciones inválida: registro %q sin documento", entry.Name)
+		}
+		record := Record{
+			Document: document,
+			Name:     entry.Name,
+			Source:   entry.Source,
+			Type:     entry.Type,
+			Reason:   entry.Reason,
+		}
+		if record.StartDate, err = parseDate(entry.StartDate); err != nil {
+			return fmt.Errorf("lista de sanciones inválida: %v", err)
+		}
+		if record.EndDate, err = parseDate(entry.EndDate); err != nil {
+			return fmt.Errorf("lista de sanciones inválida: %v", err)
+		}
+		if !record.EndDate.IsZero() {
+			record.EndDate = record.EndDate.Add(24*time.Hour - time.Second)
+		}
+		byDocument[document] = append(byDocument[document], record)
+	}
+
+	l.records = byDocument
+	l.modTime = info.ModTime()
+	return nil
+}
+
+// fileEntry is a record as written in the sanctions file, with YYYY-MM-DD dates
+type fileEntry struct {
+	Document  string `json:"document"`
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, config.ColombianTimezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fecha inválida %q, use YYYY-MM-DD", value)
+	}
+	return date, nil
+}
+
+// NormalizeDocument removes separators and a trailing NIT check digit so
+// "900.123.456-8" and "900123456" match
+func NormalizeDocument(document string) string {
+	document = strings.TrimSpace(document)
+	if i := strings.LastIndex(document, "-"); i >= 0 {
+		document = document[:i]
+	}
+	var b strings.Builder
+	for _, r := range document {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,218 @@
+// Package scheduler runs the node's background jobs: peer health checks,
+// chain sync, registry heartbeats and maintenance.
+//
+// Each job runs in its own goroutine, so a slow job never delays the others,
+// and a job never overlaps with itself. Intervals are jittered so nodes
+// started together do not hit their peers at the same moment, and failing
+// jobs back off exponentially when they configure a retry delay.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"secop-blockchain/internal/config"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is a task run periodically
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Timeout  time.Duration // Maximum duration of a run; defaults to the interval
+	RetryMin time.Duration // Delay after a first failure, doubled on each one; zero keeps the interval
+	RetryMax time.Duration // Upper bound of the retry delay
+	Run      func(ctx context.Context) error
+}
+
+// JobStatus describes the last runs of a job
+type JobStatus struct {
+	Name                string    `json:"name"`
+	Interval            string    `json:"interval"`
+	Running             bool      `json:"running"`
+	Runs                int       `json:"runs"`
+	Failures            int       `json:"failures"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastRun             time.Time `json:"last_run,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+	LastDuration        string    `json:"last_duration,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	NextRun             time.Time `json:"next_run,omitempty"`
+}
+
+// Scheduler runs jobs until stopped
+type Scheduler struct {
+	jitter  float64
+	jobs    []*entry
+	mutex   sync.RWMutex
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// entry is a job and its status
+type entry struct {
+	job    Job
+	status JobStatus
+}
+
+// New creates a scheduler that varies each interval by up to jitterPercent
+func New(jitterPercent int) *Scheduler {
+	if jitterPercent < 0 {
+		jitterPercent = 0
+	}
+	if jitterPercent > 50 {
+		jitterPercent = 50
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		jitter: float64(jitterPercent) / 100,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers a job. Jobs added after Start begin right away.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job requires a name and a function")
+	}
+	if job.Interval <= 0 {
+		return errors.New("job " + job.Name + " requires a positive interval")
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = job.Interval
+	}
+	if job.RetryMax < job.RetryMin {
+		job.RetryMax = job.RetryMin
+	}
+
+	e := &entry{job: job, status: JobStatus{Name: job.Name, Interval: job.Interval.String()}}
+	s.mutex.Lock()
+	s.jobs = append(s.jobs, e)
+	started := s.started
+	s.mutex.Unlock()
+
+	if started {
+		s.launch(e)
+	}
+	return nil
+}
+
+// Start runs every registered job. The first run of each job happens after a
+// random part of the jitter window, spreading startup load.
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	if s.started {
+		s.mutex.Unlock()
+		return
+	}
+	s.started = true
+	jobs := append([]*entry(nil), s.jobs...)
+	s.mutex.Unlock()
+
+	for _, e := range jobs {
+		s.launch(e)
+	}
+	log.Printf("Scheduler: %d tareas en segundo plano iniciadas", len(jobs))
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Status returns the state of every job, sorted by name
+func (s *Scheduler) Status() []JobStatus {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	statuses := make([]JobStatus, len(s.jobs))
+	for i, e := range s.jobs {
+		statuses[i] = e.status
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (s *Scheduler) launch(e *entry) {
+	s.wg.Add(1)
+	go s.loop(e)
+}
+
+// loop runs a job until the scheduler stops
+func (s *Scheduler) loop(e *entry) {
+	defer s.wg.Done()
+
+	delay := time.Duration(rand.Float64() * s.jitter * float64(e.job.Interval))
+	for {
+		s.mutex.Lock()
+		e.status.NextRun = config.GetColombianTime().Add(delay)
+		s.mutex.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		delay = s.run(e)
+	}
+}
+
+// run executes the job once and returns the delay until the next run
+func (s *Scheduler) run(e *entry) time.Duration {
+	s.mutex.Lock()
+	e.status.Running = true
+	s.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(s.ctx, e.job.Timeout)
+	started := time.Now()
+	err := e.job.Run(ctx)
+	cancel()
+	elapsed := time.Since(started)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e.status.Running = false
+	e.status.Runs++
+	e.status.LastRun = config.GetColombianTime()
+	e.status.LastDuration = elapsed.Round(time.Millisecond).String()
+	if err == nil {
+		e.status.ConsecutiveFailures = 0
+		e.status.LastError = ""
+		e.status.LastSuccess = e.status.LastRun
+		return s.jittered(e.job.Interval)
+	}
+
+	e.status.Failures++
+	e.status.ConsecutiveFailures++
+	e.status.LastError = err.Error()
+	if s.ctx.Err() == nil {
+		log.Printf("Scheduler: tarea %s falló (%d seguidas): %v", e.job.Name, e.status.ConsecutiveFailures, err)
+	}
+	if e.job.RetryMin <= 0 {
+		return s.jittered(e.job.Interval)
+	}
+	backoff := e.job.RetryMin << uint(min(e.status.ConsecutiveFailures-1, 16))
+	if backoff > e.job.RetryMax {
+		backoff = e.job.RetryMax
+	}
+	return s.jittered(backoff)
+}
+
+// jittered varies d randomly by up to the jitter fraction in either direction
+func (s *Scheduler) jittered(d time.Duration) time.Duration {
+	if s.jitter == 0 {
+		return d
+	}
+	return d + time.Duration((rand.Float64()*2-1)*s.jitter*float64(d))
+}
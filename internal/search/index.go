@@ -0,0 +1,269 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// snippetRadius is the number of bytes of context shown around a highlight
+const snippetRadius = 60
+
+// Hit is a ranked search result
+type Hit struct {
+	DocID      string              `json:"doc_id"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights"`
+}
+
+// document holds the original field texts of an indexed document
+type document struct {
+	fields  map[string]string
+	lengths map[string]int
+}
+
+// Index is an in-memory inverted index with per-field BM25 ranking
+type Index struct {
+	boosts      map[string]float64
+	docs        map[string]*document
+	postings    map[string]map[string]map[string]int // term -> doc -> field -> frequency
+	fieldTotals map[string]int
+	mutex       sync.RWMutex
+}
+
+// NewIndex creates an index. Boosts weight matches per field; unknown fields weigh 1.
+func NewIndex(boosts map[string]float64) *Index {
+	return &Index{
+		boosts:      boosts,
+		docs:        make(map[string]*document),
+		postings:    make(map[string]map[string]map[string]int),
+		fieldTotals: make(map[string]int),
+	}
+}
+
+// Put indexes a document, replacing any previous version
+func (idx *Index) Put(docID string, fields map[string]string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.remove(docID)
+
+	doc := &document{fields: fields, lengths: make(map[string]int)}
+	for field, text := range fields {
+		terms := Terms(text)
+		doc.lengths[field] = len(terms)
+		idx.fieldTotals[field] += len(terms)
+		for _, term := range terms {
+			if idx.postings[term] == nil {
+				idx.postings[term] = make(map[string]map[string]int)
+			}
+			if idx.postings[term][docID] == nil {
+				idx.postings[term][docID] = make(map[string]int)
+			}
+			idx.postings[term][docID][field]++
+		}
+	}
+	idx.docs[docID] = doc
+}
+
+// Remove deletes a document from the index
+func (idx *Index) Remove(docID string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.remove(docID)
+}
+
+// remove deletes a document (requires the write lock)
+func (idx *Index) remove(docID string) {
+	doc, exists := idx.docs[docID]
+	if !exists {
+		return
+	}
+	for field, text := range doc.fields {
+		idx.fieldTotals[field] -= doc.lengths[field]
+		for _, term := range Terms(text) {
+			if postings, ok := idx.postings[term]; ok {
+				delete(postings, docID)
+				if len(postings) == 0 {
+					delete(idx.postings, term)
+				}
+			}
+		}
+	}
+	delete(idx.docs, docID)
+}
+
+// Size returns the number of indexed documents
+func (idx *Index) Size() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	return len(idx.docs)
+}
+
+// Search ranks documents matching any query term. If fields is non-empty only
+// those fields are searched.
+func (idx *Index) Search(query string, fields []string, limit int) []Hit {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	terms := uniqueTerms(Terms(query))
+	if len(terms) == 0 || len(idx.docs) == 0 {
+		return []Hit{}
+	}
+
+	allowed := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		allowed[field] = true
+	}
+
+	scores := make(map[string]float64)
+	matched := make(map[string]map[string]bool) // doc -> matched terms
+	totalDocs := float64(len(idx.docs))
+
+	for _, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := math.Log(1 + (totalDocs-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+
+		for docID, freqs := range postings {
+			doc := idx.docs[docID]
+			for field, tf := range freqs {
+				if len(allowed) > 0 && !allowed[field] {
+					continue
+				}
+				avgLength := float64(idx.fieldTotals[field]) / totalDocs
+				if avgLength == 0 {
+					avgLength = 1
+				}
+				norm := 1 - bm25B + bm25B*float64(doc.lengths[field])/avgLength
+				score := idf * (float64(tf) * (bm25K1 + 1)) / (float64(tf) + bm25K1*norm)
+				scores[docID] += score * idx.boost(field)
+
+				if matched[docID] == nil {
+					matched[docID] = make(map[string]bool)
+				}
+				matched[docID][term] = true
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for docID, score := range scores {
+		// Documents matching more query terms rank higher
+		coverage := float64(len(matched[docID])) / float64(len(terms))
+		hits = append(hits, Hit{DocID: docID, Score: score * coverage})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].DocID < hits[j].DocID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	for i := range hits {
+		hits[i].Highlights = idx.highlights(hits[i].DocID, matched[hits[i].DocID], allowed)
+	}
+
+	return hits
+}
+
+// boost returns the weight of a field
+func (idx *Index) boost(field string) float64 {
+	if boost, ok := idx.boosts[field]; ok {
+		return boost
+	}
+	return 1
+}
+
+// highlights builds snippets around matched terms, wrapping them in <em> tags
+func (idx *Index) highlights(docID string, terms map[string]bool, allowed map[string]bool) map[string][]string {
+	result := make(map[string][]string)
+	doc := idx.docs[docID]
+
+	for field, text := range doc.fields {
+		if len(allowed) > 0 && !allowed[field] {
+			continue
+		}
+
+		var spans []Token
+		for _, token := range Tokenize(text) {
+			if terms[token.Term] {
+				spans = append(spans, token)
+			}
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		start := clampToRune(text, spans[0].Start-snippetRadius)
+		end := spans[len(spans)-1].End + snippetRadius
+		if maxEnd := spans[0].End + 3*snippetRadius; end > maxEnd {
+			end = maxEnd
+		}
+		end = clampToRune(text, end)
+
+		var b strings.Builder
+		if start > 0 {
+			b.WriteString("…")
+		}
+		pos := start
+		for _, span := range spans {
+			if span.End > end {
+				break
+			}
+			b.WriteString(text[pos:span.Start])
+			b.WriteString("<em>")
+			b.WriteString(text[span.Start:span.End])
+			b.WriteString("</em>")
+			pos = span.End
+		}
+		b.WriteString(text[pos:end])
+		if end < len(text) {
+			b.WriteString("…")
+		}
+
+		result[field] = append(result[field], b.String())
+	}
+
+	return result
+}
+
+// clampToRune bounds an offset to the text and moves it to a rune boundary
+func clampToRune(text string, offset int) int {
+	if offset <= 0 {
+		return 0
+	}
+	if offset >= len(text) {
+		return len(text)
+	}
+	for offset > 0 && offset < len(text) && (text[offset]&0xC0) == 0x80 {
+		offset--
+	}
+	return offset
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	result := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
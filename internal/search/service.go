@@ -0,0 +1,103 @@
+package search
+
+import (
+	"secop-blockchain/internal/blockchain"
+	"strings"
+)
+
+// Searchable contract fields
+const (
+	FieldDescription = "description"
+	FieldEntityName  = "entity_name"
+	FieldAudit       = "audit"
+)
+
+// Result is a search hit resolved to its contract
+type Result struct {
+	ContractID string               `json:"contract_id"`
+	Score      float64              `json:"score"`
+	Highlights map[string][]string  `json:"highlights"`
+	Contract   *blockchain.Contract `json:"contract"`
+}
+
+// Service keeps a full-text index of contracts in sync with the blockchain
+type Service struct {
+	blockchain *blockchain.Blockchain
+	index      *Index
+}
+
+// NewService creates the search service and subscribes it to new blocks
+func NewService(bc *blockchain.Blockchain) *Service {
+	s := &Service{
+		blockchain: bc,
+		index: NewIndex(map[string]float64{
+			FieldEntityName:  1.5,
+			FieldDescription: 1.0,
+			FieldAudit:       0.8,
+		}),
+	}
+
+	s.Rebuild()
+	bc.AddBlockListener(s.onBlock)
+
+	return s
+}
+
+// onBlock reindexes the contract touched by a block
+func (s *Service) onBlock(block *blockchain.Block) {
+	contractID, ok := block.Data["contract_id"].(string)
+	if !ok || contractID == "" {
+		return
+	}
+	if contract, err := s.blockchain.GetContract(contractID); err == nil {
+		s.indexContract(contract)
+	}
+}
+
+// Rebuild reindexes every contract currently in the blockchain
+func (s *Service) Rebuild() {
+	for _, contract := range s.blockchain.GetAllContracts() {
+		s.indexContract(contract)
+	}
+}
+
+// indexContract stores the searchable fields of a contract
+func (s *Service) indexContract(contract *blockchain.Contract) {
+	var observations []string
+	for _, entry := range contract.AuditTrail {
+		if entry.Action == "AUDIT_OBSERVATION" {
+			observations = append(observations, entry.Description)
+		}
+	}
+
+	s.index.Put(contract.ID, map[string]string{
+		FieldDescription: contract.Description,
+		FieldEntityName:  contract.EntityName,
+		FieldAudit:       strings.Join(observations, "\n"),
+	})
+}
+
+// Search returns contracts ranked by relevance to the query
+func (s *Service) Search(query string, fields []string, limit int) []Result {
+	hits := s.index.Search(query, fields, limit)
+
+	results := make([]Result, 0, len(hits))
+	for _, hit := range hits {
+		contract, err := s.blockchain.GetContract(hit.DocID)
+		if err != nil {
+			continue
+		}
+		results = append(results, Result{
+			ContractID: hit.DocID,
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+			Contract:   contract,
+		})
+	}
+	return results
+}
+
+// IndexedCount returns the number of indexed contracts
+func (s *Service) IndexedCount() int {
+	return s.index.Size()
+}
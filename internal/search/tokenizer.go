@@ -0,0 +1,128 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a normalized term with its byte offsets in the original text
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// accentFolding maps accented Spanish characters to their base letter
+var accentFolding = map[rune]rune{
+	'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a',
+	'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+	'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+	'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o',
+	'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// stopwords are common Spanish words that carry no search value
+var stopwords = map[string]bool{
+	"a": true, "al": true, "ante": true, "bajo": true, "con": true, "contra": true,
+	"de": true, "del": true, "desde": true, "durante": true, "e": true, "el": true,
+	"en": true, "entre": true, "es": true, "esta": true, "este": true, "esto": true,
+	"ha": true, "hacia": true, "hasta": true, "la": true, "las": true, "le": true,
+	"les": true, "lo": true, "los": true, "mas": true, "mediante": true, "ni": true,
+	"o": true, "para": true, "pero": true, "por": true, "que": true, "se": true,
+	"segun": true, "sin": true, "sobre": true, "su": true, "sus": true, "tras": true,
+	"u": true, "un": true, "una": true, "unas": true, "uno": true, "unos": true,
+	"y": true, "ya": true,
+}
+
+// Fold lowercases text and removes Spanish accents
+func Fold(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		if folded, ok := accentFolding[r]; ok {
+			r = folded
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Tokenize splits text into normalized terms, dropping stopwords
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if term := normalize(text[start:end]); term != "" {
+			tokens = append(tokens, Token{Term: term, Start: start, End: end})
+		}
+		start = -1
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+		} else {
+			flush(i)
+		}
+		i += size
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// Terms returns only the normalized terms of a text
+func Terms(text string) []string {
+	tokens := Tokenize(text)
+	terms := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		terms = append(terms, token.Term)
+	}
+	return terms
+}
+
+// normalize folds a word and applies light Spanish stemming
+func normalize(word string) string {
+	term := Fold(word)
+	if stopwords[term] {
+		return ""
+	}
+	return stem(term)
+}
+
+// stem removes Spanish plural endings and a trailing "e" so that
+// "licitaciones" matches "licitación" and "puentes" matches "puente"
+func stem(term string) string {
+	n := len(term)
+	switch {
+	case n > 4 && strings.HasSuffix(term, "ces"):
+		// luces -> luz, veces -> vez
+		return term[:n-3] + "z"
+	case n > 4 && strings.HasSuffix(term, "es"):
+		term = term[:n-2]
+	case n > 3 && strings.HasSuffix(term, "s") && isVowel(term[n-2]):
+		term = term[:n-1]
+	}
+	if len(term) > 4 && strings.HasSuffix(term, "e") {
+		term = term[:len(term)-1]
+	}
+	return term
+}
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	}
+	return false
+}
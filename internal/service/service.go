@@ -1,16 +1,43 @@
 package service
 
 import (
+	"log"
+	"secop-blockchain/internal/analytics"
 	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/calendar"
+	"secop-blockchain/internal/citizen"
 	"secop-blockchain/internal/config"
+	"secop-blockchain/internal/evidence"
+	"secop-blockchain/internal/identity"
+	"secop-blockchain/internal/modality"
+	"secop-blockchain/internal/money"
+	"secop-blockchain/internal/notification"
+	"secop-blockchain/internal/registry"
+	"secop-blockchain/internal/sanctions"
+	"secop-blockchain/internal/scheduler"
+	"secop-blockchain/internal/search"
+	"secop-blockchain/internal/stream"
+	"secop-blockchain/internal/webhook"
+	"time"
 )
 
 // Services holds all business logic services
 type Services struct {
-	Blockchain *blockchain.Blockchain
-	P2P        *blockchain.P2PNetwork
-	Workflow   *blockchain.WorkflowManager
-	Config     *config.Config
+	Blockchain    *blockchain.Blockchain
+	P2P           *blockchain.P2PNetwork
+	Workflow      *blockchain.WorkflowManager
+	Calendar      *calendar.Calendar
+	Search        *search.Service
+	Analytics     *analytics.Service
+	Citizens      *citizen.Channel
+	Evidence      *evidence.Store
+	Stream        *stream.Hub
+	Webhooks      *webhook.Dispatcher
+	Notifications *notification.Service
+	Identity      *identity.Identity
+	Registry      *registry.Registry // nil unless running in registry mode
+	Scheduler     *scheduler.Scheduler
+	Config        *config.Config
 }
 
 // NewServices creates and initializes all services
@@ -24,17 +51,162 @@ func NewServices(cfg *config.Config) *Services {
 		cfg.Server.Address,
 		cfg.Server.Port,
 		bc,
-		cfg.P2P.DiscoveryRegistryURL,
+		cfg.P2P.DiscoveryRegistryURLs,
 		cfg.Entity.Type,
 	)
+	p2pNetwork.PeerDiscovery.ConfigureHeartbeat(cfg.P2P.HeartbeatInterval)
+	p2pNetwork.ConfigureReputation(cfg.P2P.BanDuration)
+	p2pNetwork.ConfigureBroadcast(cfg.P2P.BroadcastTimeout, cfg.P2P.BroadcastMaxRetries, cfg.P2P.BroadcastQueueSize)
 	
-	// Initialize workflow manager
-	workflowManager := blockchain.NewWorkflowManager(bc)
+	// Currency conversion and minimum wage table
+	bc.ConfigureConverter(newConverter(cfg.Money))
+	
+	// Enforce the entity's contract limits
+	bc.ConfigureEntityLimits(blockchain.EntityLimits{
+		MaxContractValue:         money.New(cfg.Entity.MaxContractValue),
+		HigherAuthorityThreshold: money.New(cfg.Entity.HigherAuthorityThreshold),
+		BudgetAuthority:          cfg.Entity.BudgetAuthority,
+		AnnualBudget:             money.New(cfg.Entity.AnnualBudget),
+	})
+	
+	// Contracting modality rules (embedded defaults or a JSON file)
+	modalityRules, err := modality.Load(cfg.Workflow.ModalityRulesFile)
+	if err != nil {
+		log.Fatalf("Error cargando reglas de modalidad: %v", err)
+	}
+	bc.ConfigureModalityRules(modalityRules)
+	
+	// Sanctions and disqualification checks for awards
+	if cfg.Suppliers.SanctionsListFile != "" {
+		sanctionsList, err := sanctions.NewFileList(cfg.Suppliers.SanctionsListFile)
+		if err != nil {
+			log.Fatalf("Error cargando lista de sanciones: %v", err)
+		}
+		bc.ConfigureSanctions(sanctionsList)
+		log.Printf("Lista de sanciones cargada: %d registros", sanctionsList.Count())
+	}
+	
+	// Colombian business-day calendar plus configured non-working days
+	businessCalendar := calendar.New(cfg.Workflow.Holidays)
+	
+	// Use the blockchain's workflow manager so workflow listeners see every event
+	workflowManager := bc.WorkflowManager
+	stepDays := make(map[blockchain.AdminRole]int)
+	for role, days := range cfg.Workflow.StepDeadlines {
+		stepDays[blockchain.AdminRole(role)] = days
+	}
+	workflowManager.ConfigureDeadlines(
+		businessCalendar,
+		stepDays,
+		cfg.Workflow.DefaultStepDays,
+	)
+	workflowManager.ConfigureAuditDeadlines(cfg.Workflow.AuditResponseDays)
+	
+	// Audit roles are bound to control entities and their jurisdiction
+	workflowManager.ConfigureAuditAuthority(blockchain.AuditAuthority{
+		EntityType: cfg.Entity.Type,
+		EntityCode: cfg.Entity.Code,
+		Region:     cfg.Entity.Region,
+		Level:      cfg.Entity.Level,
+	})
+	
+	// Citizens observe through a verified-identity, rate-limited channel
+	citizenChannel := citizen.NewChannel(
+		cfg.Citizens.IdentitySecret,
+		cfg.Citizens.RateLimit,
+		cfg.Citizens.RateWindow,
+		cfg.Citizens.TestIssuer,
+	)
+	
+	// Local storage for complaint evidence; only digests go on-chain
+	evidenceStore, err := evidence.NewStore(
+		cfg.Complaints.EvidenceDir,
+		cfg.Complaints.MaxEvidenceBytes,
+		cfg.Complaints.MaxEvidenceFiles,
+	)
+	if err != nil {
+		log.Fatalf("Error iniciando almacén de evidencias: %v", err)
+	}
+	
+	// Initialize full-text search index
+	searchService := search.NewService(bc)
+	
+	// Red-flag analytics for control entities
+	analyticsService := analytics.NewService(bc, analytics.Config{
+		SplitWindow:           time.Duration(cfg.Analytics.SplitWindowDays) * 24 * time.Hour,
+		SplitMarginPercent:    cfg.Analytics.SplitMarginPercent,
+		FastStep:              cfg.Analytics.FastStep,
+		FastWorkflow:          cfg.Analytics.FastWorkflow,
+		ValidatorSharePercent: cfg.Analytics.ValidatorSharePercent,
+		ValidatorMinContracts: cfg.Analytics.ValidatorMinContracts,
+	})
+	
+	// Initialize chain event stream
+	streamHub := stream.NewHub(bc)
+	
+	// Initialize outbound webhooks for workflow events
+	webhookDispatcher := webhook.NewDispatcher(cfg.P2P.NodeID, cfg.Webhooks, workflowManager)
+	
+	// Initialize notifications for pending validations
+	notificationService, err := notification.NewService(cfg.Notify, bc, workflowManager)
+	if err != nil {
+		log.Fatalf("Error iniciando notificaciones: %v", err)
+	}
+	
+	// Load node signing identity
+	nodeIdentity, err := identity.Load(cfg.P2P.NodeID, cfg.P2P.SigningKey)
+	if err != nil {
+		log.Fatalf("Error cargando identidad del nodo: %v", err)
+	}
+	
+	// Peer exchange records are signed with the node identity
+	if cfg.P2P.GossipEnabled {
+		p2pNetwork.PeerDiscovery.ConfigureGossip(nodeIdentity, cfg.P2P.GossipInterval, cfg.P2P.GossipFanout, cfg.P2P.GossipMaxPeers)
+	}
+	
+	// Built-in peer registry so a network can run without external services
+	var peerRegistry *registry.Registry
+	if cfg.P2P.RegistryMode {
+		peerRegistry, err = registry.New(cfg.P2P.RegistryFile, cfg.P2P.RegistryTTL)
+		if err != nil {
+			log.Fatalf("Error iniciando registro de peers: %v", err)
+		}
+	}
 	
 	return &Services{
-		Blockchain: bc,
-		P2P:        p2pNetwork,
-		Workflow:   workflowManager,
-		Config:     cfg,
+		Blockchain:    bc,
+		P2P:           p2pNetwork,
+		Workflow:      workflowManager,
+		Calendar:      businessCalendar,
+		Search:        searchService,
+		Analytics:     analyticsService,
+		Citizens:      citizenChannel,
+		Evidence:      evidenceStore,
+		Stream:        streamHub,
+		Webhooks:      webhookDispatcher,
+		Notifications: notificationService,
+		Identity:      nodeIdentity,
+		Registry:      peerRegistry,
+		Scheduler:     scheduler.New(cfg.Scheduler.JitterPercent),
+		Config:        cfg,
+	}
+}
+
+// newConverter builds the currency converter from configuration
+func newConverter(cfg config.MoneyConfig) *money.Converter {
+	rates := make(map[money.Currency]money.Decimal)
+	if cfg.USDRate != "" {
+		rate, err := money.Parse(cfg.USDRate)
+		if err != nil || !rate.IsPositive() {
+			log.Fatalf("MONEY_USD_COP_RATE inválido: %s", cfg.USDRate)
+		}
+		rates[money.USD] = rate
 	}
-}
\ No newline at end of file
+	
+	smmlv := make(map[int]money.Decimal)
+	for year, value := range cfg.SMMLV {
+		smmlv[year] = money.New(value)
+	}
+	
+	return money.NewConverter(rates, smmlv)
+}
@@ -0,0 +1,212 @@
+package stream
+
+import (
+	"secop-blockchain/internal/blockchain"
+	"sync"
+	"time"
+)
+
+// Event types published by the hub
+const (
+	EventBlock             = "block"
+	EventContractStatus    = "contract_status"
+	EventPendingValidation = "pending_validation"
+)
+
+// subscriberBuffer is the number of events queued per subscriber before it is dropped
+const subscriberBuffer = 256
+
+// Event is a chain event delivered to subscribers. ID is the height of the
+// block that produced it, so clients can resume with from_height = ID + 1.
+type Event struct {
+	ID         int               `json:"id"`
+	Type       string            `json:"type"`
+	ContractID string            `json:"contract_id,omitempty"`
+	EntityCode string            `json:"entity_code,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	Role       string            `json:"role,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Block      *blockchain.Block `json:"block,omitempty"`
+}
+
+// Filter selects the events a subscriber receives. Empty fields match everything.
+type Filter struct {
+	Types      map[string]bool
+	Role       string
+	ContractID string
+	EntityCode string
+}
+
+// Matches reports whether an event passes the filter
+func (f Filter) Matches(event Event) bool {
+	if len(f.Types) > 0 && !f.Types[event.Type] {
+		return false
+	}
+	if f.ContractID != "" && event.ContractID != f.ContractID {
+		return false
+	}
+	if f.EntityCode != "" && event.EntityCode != f.EntityCode {
+		return false
+	}
+	if f.Role != "" && event.Type == EventPendingValidation && event.Role != f.Role {
+		return false
+	}
+	return true
+}
+
+// Subscription receives filtered events until closed. Events is closed when
+// the subscriber falls too far behind; the client should resume from its last ID.
+type Subscription struct {
+	Events chan Event
+	filter Filter
+	hub    *Hub
+	closed bool
+}
+
+// Close unsubscribes from the hub
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// Hub fans out chain events to subscribers
+type Hub struct {
+	blockchain  *blockchain.Blockchain
+	subscribers map[*Subscription]bool
+	mutex       sync.Mutex
+}
+
+// NewHub creates a hub and subscribes it to new blocks
+func NewHub(bc *blockchain.Blockchain) *Hub {
+	hub := &Hub{
+		blockchain:  bc,
+		subscribers: make(map[*Subscription]bool),
+	}
+	bc.AddBlockListener(hub.publishBlock)
+	return hub
+}
+
+// Subscribe registers a subscriber for live events
+func (h *Hub) Subscribe(filter Filter) *Subscription {
+	sub := &Subscription{
+		Events: make(chan Event, subscriberBuffer),
+		filter: filter,
+		hub:    h,
+	}
+
+	h.mutex.Lock()
+	h.subscribers[sub] = true
+	h.mutex.Unlock()
+
+	return sub
+}
+
+// Replay returns the filtered events of blocks from fromHeight up to the current height
+func (h *Hub) Replay(fromHeight int, filter Filter) []Event {
+	chain := h.blockchain.GetChain()
+	if fromHeight < 0 {
+		fromHeight = 0
+	}
+
+	var events []Event
+	for i := fromHeight; i < len(chain); i++ {
+		for _, event := range h.eventsFromBlock(chain[i]) {
+			if filter.Matches(event) {
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
+
+// SubscriberCount returns the number of connected subscribers
+func (h *Hub) SubscriberCount() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.subscribers)
+}
+
+// publishBlock delivers the events of a new block without blocking the chain
+func (h *Hub) publishBlock(block *blockchain.Block) {
+	events := h.eventsFromBlock(block)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for sub := range h.subscribers {
+		for _, event := range events {
+			if !sub.filter.Matches(event) {
+				continue
+			}
+			select {
+			case sub.Events <- event:
+			default:
+				// Subscriber is too slow: drop it so it reconnects and resumes
+				h.closeLocked(sub)
+			}
+			if sub.closed {
+				break
+			}
+		}
+	}
+}
+
+// unsubscribe removes a subscriber
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.closeLocked(sub)
+}
+
+// closeLocked closes a subscription (requires the hub lock)
+func (h *Hub) closeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(h.subscribers, sub)
+	close(sub.Events)
+}
+
+// eventsFromBlock derives the stream events produced by a block
+func (h *Hub) eventsFromBlock(block *blockchain.Block) []Event {
+	contractID, _ := block.Data["contract_id"].(string)
+	entityCode, _ := block.Data["entity_code"].(string)
+	if entityCode == "" && contractID != "" {
+		if contract, err := h.blockchain.GetContract(contractID); err == nil {
+			entityCode = contract.EntityCode
+		}
+	}
+
+	base := Event{
+		ID:         block.Index,
+		ContractID: contractID,
+		EntityCode: entityCode,
+		Timestamp:  block.Timestamp,
+	}
+
+	blockEvent := base
+	blockEvent.Type = EventBlock
+	blockEvent.Block = block
+	events := []Event{blockEvent}
+
+	if contractID == "" {
+		return events
+	}
+
+	if status, _ := block.Data["status"].(string); status != "" {
+		statusEvent := base
+		statusEvent.Type = EventContractStatus
+		statusEvent.Status = status
+		events = append(events, statusEvent)
+	}
+
+	if role, _ := block.Data["next_role"].(string); role != "" {
+		pendingEvent := base
+		pendingEvent.Type = EventPendingValidation
+		pendingEvent.Role = role
+		pendingEvent.Status, _ = block.Data["status"].(string)
+		events = append(events, pendingEvent)
+	}
+
+	return events
+}
@@ -0,0 +1,449 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"secop-blockchain/internal/blockchain"
+	"secop-blockchain/internal/config"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventStepPending is emitted when a contract reaches a step; the other event
+// types are the audit actions written by the workflow (STEP_APPROVED, ...).
+const (
+	EventStepPending = "STEP_PENDING"
+	EventAll         = "*"
+)
+
+// maxDeliveryLog bounds the number of deliveries kept in memory
+const maxDeliveryLog = 1000
+
+// Delivery states
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryRetrying  = "RETRYING"
+	DeliveryDead      = "DEAD"
+)
+
+// Webhook is an endpoint registered to receive workflow events
+type Webhook struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	EntityCode string    `json:"entity_code,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	Secret     string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Delivery is one attempt sequence to deliver an event to a webhook
+type Delivery struct {
+	ID            string          `json:"id"`
+	WebhookID     string          `json:"webhook_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	LastAttemptAt time.Time       `json:"last_attempt_at,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at,omitempty"`
+	DeliveredAt   time.Time       `json:"delivered_at,omitempty"`
+}
+
+// Payload is the JSON body posted to webhooks
+type Payload struct {
+	DeliveryID string                `json:"delivery_id"`
+	Event      string                `json:"event"`
+	NodeID     string                `json:"node_id"`
+	Timestamp  time.Time             `json:"timestamp"`
+	Contract   ContractSummary       `json:"contract"`
+	Entry      blockchain.AuditEntry `json:"audit_entry"`
+	NextRole   blockchain.AdminRole  `json:"next_role,omitempty"`
+}
+
+// ContractSummary is the contract data included in payloads
+type ContractSummary struct {
+	ID          string                    `json:"id"`
+	EntityCode  string                    `json:"entity_code"`
+	EntityName  string                    `json:"entity_name"`
+	Status      blockchain.ContractStatus `json:"status"`
+	CurrentStep int                       `json:"current_step"`
+}
+
+// Dispatcher stores webhooks and delivers signed events with retries
+type Dispatcher struct {
+	nodeID     string
+	cfg        config.WebhookConfig
+	client     *http.Client
+	webhooks   map[string]*Webhook
+	deliveries map[string]*Delivery
+	log        []string // IDs de entregas en orden de creación
+	queue      chan *Delivery
+	stop       chan struct{}
+	mutex      sync.RWMutex
+	stopOnce   sync.Once
+}
+
+// NewDispatcher creates a dispatcher, starts its workers and subscribes it to workflow events
+func NewDispatcher(nodeID string, cfg config.WebhookConfig, workflow *blockchain.WorkflowManager) *Dispatcher {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 5 * time.Second
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+
+	d := &Dispatcher{
+		nodeID:     nodeID,
+		cfg:        cfg,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		webhooks:   make(map[string]*Webhook),
+		deliveries: make(map[string]*Delivery),
+		queue:      make(chan *Delivery, 1024),
+		stop:       make(chan struct{}),
+	}
+
+	for i := 0; i < cfg.Workers; i++ {
+		go d.worker()
+	}
+	workflow.AddListener(d.onWorkflowEvent)
+
+	return d
+}
+
+// Stop terminates the delivery workers
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+}
+
+// Register adds a webhook. If no secret is given one is generated and returned.
+func (d *Dispatcher) Register(hook Webhook) (*Webhook, error) {
+	parsed, err := url.Parse(hook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("url inválida, debe ser http(s)")
+	}
+	if len(hook.EventTypes) == 0 {
+		return nil, errors.New("se requiere al menos un tipo de evento")
+	}
+	if hook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		hook.Secret = hex.EncodeToString(secret)
+	}
+
+	hook.ID = uuid.New().String()
+	hook.CreatedAt = config.GetColombianTime()
+
+	d.mutex.Lock()
+	d.webhooks[hook.ID] = &hook
+	d.mutex.Unlock()
+
+	log.Printf("Webhook %s registrado para %v en %s", hook.ID, hook.EventTypes, hook.URL)
+	return &hook, nil
+}
+
+// Unregister removes a webhook
+func (d *Dispatcher) Unregister(id string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, exists := d.webhooks[id]; !exists {
+		return errors.New("webhook no encontrado")
+	}
+	delete(d.webhooks, id)
+	return nil
+}
+
+// List returns all registered webhooks ordered by creation
+func (d *Dispatcher) List() []*Webhook {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	hooks := make([]*Webhook, 0, len(d.webhooks))
+	for _, hook := range d.webhooks {
+		hooks = append(hooks, hook)
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].CreatedAt.Before(hooks[j].CreatedAt) })
+	return hooks
+}
+
+// Get returns a webhook by ID
+func (d *Dispatcher) Get(id string) (*Webhook, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	hook, exists := d.webhooks[id]
+	if !exists {
+		return nil, errors.New("webhook no encontrado")
+	}
+	return hook, nil
+}
+
+// Deliveries returns the delivery log of a webhook, newest first
+func (d *Dispatcher) Deliveries(webhookID string) []Delivery {
+	return d.collect(func(delivery *Delivery) bool { return delivery.WebhookID == webhookID })
+}
+
+// DeadLetters returns deliveries that exhausted their retries, newest first
+func (d *Dispatcher) DeadLetters() []Delivery {
+	return d.collect(func(delivery *Delivery) bool { return delivery.Status == DeliveryDead })
+}
+
+// Retry requeues a dead delivery
+func (d *Dispatcher) Retry(deliveryID string) error {
+	d.mutex.Lock()
+	delivery, exists := d.deliveries[deliveryID]
+	if !exists {
+		d.mutex.Unlock()
+		return errors.New("entrega no encontrada")
+	}
+	if delivery.Status != DeliveryDead {
+		d.mutex.Unlock()
+		return errors.New("solo se pueden reintentar entregas en dead-letter")
+	}
+	delivery.Status = DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Time{}
+	d.mutex.Unlock()
+
+	d.enqueue(delivery)
+	return nil
+}
+
+// collect returns copies of the logged deliveries that match, newest first
+func (d *Dispatcher) collect(match func(*Delivery) bool) []Delivery {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	result := make([]Delivery, 0)
+	for i := len(d.log) - 1; i >= 0; i-- {
+		if delivery, exists := d.deliveries[d.log[i]]; exists && match(delivery) {
+			result = append(result, *delivery)
+		}
+	}
+	return result
+}
+
+// onWorkflowEvent creates deliveries for every webhook subscribed to the event
+func (d *Dispatcher) onWorkflowEvent(event blockchain.WorkflowEvent) {
+	eventTypes := []string{event.Action}
+	if event.NextRole != "" && event.Action != "STEP_REJECTED" {
+		eventTypes = append(eventTypes, EventStepPending)
+	}
+
+	for _, eventType := range eventTypes {
+		for _, hook := range d.subscribers(eventType, event) {
+			d.dispatch(hook, eventType, event)
+		}
+	}
+}
+
+// subscribers returns the webhooks interested in an event
+func (d *Dispatcher) subscribers(eventType string, event blockchain.WorkflowEvent) []*Webhook {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	var hooks []*Webhook
+	for _, hook := range d.webhooks {
+		if hook.EntityCode != "" && hook.EntityCode != event.Contract.EntityCode {
+			continue
+		}
+		if hook.Role != "" && eventType == EventStepPending && hook.Role != string(event.NextRole) {
+			continue
+		}
+		for _, subscribed := range hook.EventTypes {
+			if subscribed == eventType || subscribed == EventAll {
+				hooks = append(hooks, hook)
+				break
+			}
+		}
+	}
+	return hooks
+}
+
+// dispatch builds the payload and queues a delivery
+func (d *Dispatcher) dispatch(hook *Webhook, eventType string, event blockchain.WorkflowEvent) {
+	delivery := &Delivery{
+		ID:        uuid.New().String(),
+		WebhookID: hook.ID,
+		EventType: eventType,
+		Status:    DeliveryPending,
+		CreatedAt: config.GetColombianTime(),
+	}
+
+	payload, err := json.Marshal(Payload{
+		DeliveryID: delivery.ID,
+		Event:      eventType,
+		NodeID:     d.nodeID,
+		Timestamp:  delivery.CreatedAt,
+		Contract: ContractSummary{
+			ID:          event.Contract.ID,
+			EntityCode:  event.Contract.EntityCode,
+			EntityName:  event.Contract.EntityName,
+			Status:      event.Contract.Status,
+			CurrentStep: event.Contract.CurrentStep,
+		},
+		Entry:    event.Entry,
+		NextRole: event.NextRole,
+	})
+	if err != nil {
+		log.Printf("Error serializando webhook %s: %v", hook.ID, err)
+		return
+	}
+	delivery.Payload = payload
+
+	d.mutex.Lock()
+	d.deliveries[delivery.ID] = delivery
+	d.log = append(d.log, delivery.ID)
+	for len(d.log) > maxDeliveryLog {
+		delete(d.deliveries, d.log[0])
+		d.log = d.log[1:]
+	}
+	d.mutex.Unlock()
+
+	d.enqueue(delivery)
+}
+
+// enqueue hands a delivery to the workers without blocking the workflow
+func (d *Dispatcher) enqueue(delivery *Delivery) {
+	select {
+	case d.queue <- delivery:
+	default:
+		// Cola llena: reintentar más tarde en lugar de bloquear
+		d.scheduleRetry(delivery, "cola de entregas llena")
+	}
+}
+
+// worker sends queued deliveries
+func (d *Dispatcher) worker() {
+	for {
+		select {
+		case <-d.stop:
+			return
+		case delivery := <-d.queue:
+			d.attempt(delivery)
+		}
+	}
+}
+
+// attempt performs one delivery attempt and schedules a retry on failure
+func (d *Dispatcher) attempt(delivery *Delivery) {
+	d.mutex.RLock()
+	hook, exists := d.webhooks[delivery.WebhookID]
+	d.mutex.RUnlock()
+	if !exists {
+		d.markDead(delivery, "webhook eliminado")
+		return
+	}
+
+	statusCode, err := d.send(hook, delivery)
+
+	d.mutex.Lock()
+	delivery.Attempts++
+	delivery.LastAttemptAt = config.GetColombianTime()
+	delivery.ResponseCode = statusCode
+	if err == nil {
+		delivery.Status = DeliveryDelivered
+		delivery.DeliveredAt = delivery.LastAttemptAt
+		delivery.LastError = ""
+		d.mutex.Unlock()
+		return
+	}
+	attempts := delivery.Attempts
+	d.mutex.Unlock()
+
+	if attempts >= d.cfg.MaxAttempts {
+		d.markDead(delivery, err.Error())
+		log.Printf("Webhook %s: entrega %s enviada a dead-letter tras %d intentos: %v", hook.ID, delivery.ID, attempts, err)
+		return
+	}
+	d.scheduleRetry(delivery, err.Error())
+}
+
+// send posts the signed payload to the webhook URL
+func (d *Dispatcher) send(hook *Webhook, delivery *Delivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SECOP-Blockchain-Webhooks/1.0")
+	req.Header.Set("X-SECOP-Event", delivery.EventType)
+	req.Header.Set("X-SECOP-Delivery", delivery.ID)
+	req.Header.Set("X-SECOP-Timestamp", timestamp)
+	req.Header.Set("X-SECOP-Signature", "sha256="+Sign(hook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook respondió con status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// scheduleRetry requeues a delivery after an exponential backoff
+func (d *Dispatcher) scheduleRetry(delivery *Delivery, reason string) {
+	d.mutex.Lock()
+	backoff := d.cfg.InitialBackoff << uint(delivery.Attempts)
+	delivery.Status = DeliveryRetrying
+	delivery.LastError = reason
+	delivery.NextAttemptAt = config.GetColombianTime().Add(backoff)
+	d.mutex.Unlock()
+
+	time.AfterFunc(backoff, func() {
+		select {
+		case <-d.stop:
+		case d.queue <- delivery:
+		}
+	})
+}
+
+// markDead moves a delivery to the dead-letter list
+func (d *Dispatcher) markDead(delivery *Delivery, reason string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delivery.Status = DeliveryDead
+	delivery.LastError = reason
+	delivery.NextAttemptAt = time.Time{}
+}
+
+// Sign computes the HMAC-SHA256 of "timestamp.payload" with the webhook secret.
+// Receivers should recompute it and compare with the X-SECOP-Signature header.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}